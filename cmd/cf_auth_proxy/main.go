@@ -16,6 +16,7 @@ import (
 	uaa "code.cloudfoundry.org/uaa-go-client"
 	uaaConfig "code.cloudfoundry.org/uaa-go-client/config"
 
+	"github.com/pivotal/monitoring-indicator-protocol/pkg/registry"
 	"github.com/pivotal/monitoring-indicator-protocol/pkg/tls_config"
 )
 
@@ -60,10 +61,9 @@ func main() {
 	}
 
 	var server = &http.Server{
-		Addr:         address,
-		Handler:      newUaaHandler(*uaaAddress, registryProxyHandler),
-		ReadTimeout:  5 * time.Second,
-		WriteTimeout: 10 * time.Second,
+		Addr:        address,
+		Handler:     registry.WriteTimeoutHandler(newUaaHandler(*uaaAddress, registryProxyHandler), 10*time.Second),
+		ReadTimeout: 5 * time.Second,
 	}
 	log.Printf("CF Auth Proxy listening for request on: https://%s\n", address)
 	log.Fatalf("CF Auth proxy listen unblocked: %s", server.ListenAndServeTLS(*serverPEM, *serverKey))
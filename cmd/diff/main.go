@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/pivotal/monitoring-indicator-protocol/pkg/document_diff"
+	"github.com/pivotal/monitoring-indicator-protocol/pkg/indicator"
+)
+
+var Version = "undefined"
+var OS = "undefined"
+
+func main() {
+	l := log.New(os.Stderr, "", 0)
+	outputFormat := flag.String("format", "text", "output format [text,json]")
+	fromFilePath := flag.String("from", "", "indicators YAML file path of the previous version")
+	toFilePath := flag.String("to", "", "indicators YAML file path of the new version")
+	metadata := flag.String("metadata", "", "metadata to override in both documents (e.g. --metadata deployment=my-test-deployment)")
+	showVersion := flag.Bool("version", false, "show CLI version")
+
+	flag.Parse()
+
+	if *showVersion {
+		fmt.Printf("cli version %s %s", Version, OS)
+		return
+	}
+
+	if len(*fromFilePath) == 0 || len(*toFilePath) == 0 {
+		l.Fatalf("-from and -to flags are required")
+	}
+
+	output, err := diffDocuments(*outputFormat, *fromFilePath, *toFilePath, indicator.OverrideMetadata(indicator.ParseMetadata(*metadata)))
+	if err != nil {
+		l.Fatal(err)
+	}
+
+	fmt.Print(output)
+}
+
+func diffDocuments(format string, fromPath string, toPath string, opts ...indicator.ReadOpt) (string, error) {
+	from, err := indicator.ReadFile(fromPath, opts...)
+	if err != nil {
+		return "", fmt.Errorf("%s: %s", fromPath, err)
+	}
+	to, err := indicator.ReadFile(toPath, opts...)
+	if err != nil {
+		return "", fmt.Errorf("%s: %s", toPath, err)
+	}
+
+	changes := document_diff.Diff(from, to)
+	switch format {
+	case "text":
+		return changes.String(), nil
+	case "json":
+		jsonOutput, err := json.MarshalIndent(changes, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		return string(jsonOutput) + "\n", nil
+	default:
+		return "", errors.New("could not diff the documents; specified format not supported")
+	}
+}
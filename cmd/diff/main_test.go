@@ -0,0 +1,70 @@
+package main_test
+
+import (
+	"bytes"
+	"os/exec"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gexec"
+
+	"github.com/pivotal/monitoring-indicator-protocol/pkg/go_test"
+)
+
+func TestDiffBinary(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	binPath, err := go_test.Build("./", "-race")
+	g.Expect(err).ToNot(HaveOccurred())
+
+	t.Run("complains if the documents are not specified", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		cmd := exec.Command(binPath, "-from", "test_fixtures/from.yml")
+
+		session, _ := gexec.Start(cmd, nil, nil)
+		g.Eventually(session, 5).Should(gexec.Exit(1))
+	})
+
+	t.Run("prints the changes as text", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		cmd := exec.Command(binPath,
+			"-from", "test_fixtures/from.yml",
+			"-to", "test_fixtures/to.yml")
+
+		buffer := bytes.NewBuffer(nil)
+		session, err := gexec.Start(cmd, buffer, nil)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Eventually(session, 5).Should(gexec.Exit(0))
+
+		g.Expect(buffer.String()).To(Equal(`my-product 1.0.0 -> my-product 1.1.0
+changed product.version: "1.0.0" -> "1.1.0"
+added indicators[throughput]
+tightened indicators[latency].thresholds[critical].value: 500 -> 250
+removed indicators[errors]
+changed layout.sections[Main].indicators: ["latency","errors"] -> ["latency","throughput"]
+`))
+	})
+
+	t.Run("prints the changes as JSON", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		cmd := exec.Command(binPath,
+			"-format", "json",
+			"-from", "test_fixtures/from.yml",
+			"-to", "test_fixtures/from.yml",
+			"-metadata", "deployment=other-deployment")
+
+		buffer := bytes.NewBuffer(nil)
+		session, err := gexec.Start(cmd, buffer, nil)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Eventually(session, 5).Should(gexec.Exit(0))
+
+		g.Expect(buffer.String()).To(MatchJSON(`{
+			"from": {"name": "my-product", "version": "1.0.0"},
+			"to": {"name": "my-product", "version": "1.0.0"},
+			"changes": []
+		}`))
+	})
+}
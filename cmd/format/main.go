@@ -1,14 +1,18 @@
 package main
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
+	"io/ioutil"
 	"log"
 	"os"
 	"regexp"
+	"strings"
 
+	"github.com/sergi/go-diff/diffmatchpatch"
 	"gopkg.in/yaml.v2"
 
 	"github.com/pivotal/monitoring-indicator-protocol/pkg/docs"
@@ -23,9 +27,13 @@ var OS = "undefined"
 
 func main() {
 	l := log.New(os.Stderr, "", 0)
-	outputFormat := flag.String("format", "bookbinder", "output format [html,bookbinder,prometheus-alerts,grafana]")
+	outputFormat := flag.String("format", "bookbinder", "output format [html,bookbinder,prometheus-alerts,grafana,lint,patch-dry-run,canonical]")
 	metadata := flag.String("metadata", "", "metadata to override (e.g. --metadata deployment=my-test-deployment,source_id=metric-forwarder)")
 	indicatorsFilePath := flag.String("indicators", "", "indicators YAML file path")
+	patchFilePaths := flag.String("patches", "", "comma separated patch YAML file paths to apply with -format patch-dry-run")
+	defaults := flag.String("defaults", string(indicator.KeepDefaults), "what -format canonical does with defaulted fields [keep,show,hide]")
+	check := flag.Bool("check", false, "with -format canonical, only show how the file differs from its canonical form, and exit 1 if it does")
+	propertiesFilePath := flag.String("properties", "", "BOSH job properties YAML file path used to render the ERB in the indicators file")
 	showVersion := flag.Bool("version", false, "show CLI version")
 
 	flag.Parse()
@@ -39,7 +47,49 @@ func main() {
 		l.Fatalf("-indicators flag is required")
 	}
 
-	output, err := parseDocument(*outputFormat, *metadata, *indicatorsFilePath)
+	if *outputFormat == "canonical" {
+		output, canonical, err := formatCanonical(*indicatorsFilePath, *defaults, *check)
+		if err != nil {
+			l.Fatal(err)
+		}
+		fmt.Print(output)
+		if !canonical {
+			os.Exit(1)
+		}
+		return
+	}
+
+	readOpts := []indicator.ReadOpt{indicator.OverrideMetadata(indicator.ParseMetadata(*metadata))}
+	if len(*propertiesFilePath) > 0 {
+		properties, err := indicator.ReadPropertiesFile(*propertiesFilePath)
+		if err != nil {
+			l.Fatal(err)
+		}
+		readOpts = append(readOpts, indicator.RenderERB(properties))
+	}
+
+	if *outputFormat == "lint" {
+		output, valid := lintDocument(*indicatorsFilePath, readOpts...)
+		fmt.Print(output)
+		if !valid {
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *outputFormat == "patch-dry-run" {
+		output, applied, err := dryRunPatches(*indicatorsFilePath, *patchFilePaths)
+		if err != nil {
+			l.Fatal(err)
+		}
+		fmt.Print(output)
+		if !applied {
+			os.Exit(1)
+		}
+		return
+	}
+
+	output, err := parseDocument(*outputFormat, *indicatorsFilePath, readOpts...)
 	if err != nil {
 		l.Fatal(err)
 	}
@@ -48,8 +98,8 @@ func main() {
 	fmt.Print(output)
 }
 
-func parseDocument(format string, metadata string, filePath string) (string, error) {
-	document := getDocument(filePath, indicator.OverrideMetadata(indicator.ParseMetadata(metadata)))
+func parseDocument(format string, filePath string, opts ...indicator.ReadOpt) (string, error) {
+	document := getDocument(filePath, opts...)
 	switch format {
 	case "bookbinder":
 		bookbinder, err := docs.DocumentToBookbinder(document)
@@ -83,8 +133,232 @@ func parseDocument(format string, metadata string, filePath string) (string, err
 	}
 }
 
+// Reports every lint warning and validation error of the document, rather than only the first problem that
+// prevents formatting it.
+func lintDocument(filePath string, opts ...indicator.ReadOpt) (string, bool) {
+	var output strings.Builder
+	_, err := indicator.ReadFile(filePath, append(opts,
+		indicator.ReportWarnings(func(warning error) {
+			fmt.Fprintf(&output, "warning: %s\n", indicator.DescribeError(warning))
+		}),
+	)...)
+	if err != nil {
+		output.WriteString(err.Error())
+		return output.String(), false
+	}
+
+	if output.Len() == 0 {
+		output.WriteString("no problems found\n")
+	}
+	return output.String(), true
+}
+
+// Formats the document without reading it, so that its comments and ERB are kept. With check, the
+// output is the diff to the canonical form instead, and it is reported whether there is none.
+func formatCanonical(docPath string, defaultsMode string, check bool) (string, bool, error) {
+	defaults, err := indicator.ParseDefaultsMode(defaultsMode)
+	if err != nil {
+		return "", false, err
+	}
+	documentBytes, err := ioutil.ReadFile(docPath)
+	if err != nil {
+		return "", false, errors.New("could not read indicators file")
+	}
+
+	formatted, err := indicator.FormatCanonical(documentBytes, defaults)
+	if err != nil {
+		return "", false, fmt.Errorf("%s: %s", docPath, err)
+	}
+	if !check {
+		return string(formatted), true, nil
+	}
+	if bytes.Equal(documentBytes, formatted) {
+		return "", true, nil
+	}
+	return lineDiff(docPath, "canonical", string(documentBytes), string(formatted)), false, nil
+}
+
+// Applies the patches to the document without registering it, and describes what every patch did and how
+// the document changed. Fails if any operation of a matching patch could not be applied.
+func dryRunPatches(docPath string, patchPaths string) (string, bool, error) {
+	if patchPaths == "" {
+		return "", false, errors.New("-patches flag is required")
+	}
+
+	var patches []indicator.Patch
+	for _, path := range strings.Split(patchPaths, ",") {
+		p, err := indicator.ReadPatchFile(path)
+		if err != nil {
+			return "", false, fmt.Errorf("%s: %s", path, err)
+		}
+		// Every file is its own source, so that files writing the same path are reported as conflicts.
+		p.Source = path
+		patches = append(patches, p)
+	}
+	indicator.SortPatches(patches)
+
+	documentBytes, err := ioutil.ReadFile(docPath)
+	if err != nil {
+		return "", false, errors.New("could not read indicators file")
+	}
+
+	// Applying no patches formats the document the same way as the patched one, so that only the
+	// changes made by the patches show up in the diff.
+	original, err := indicator.ApplyPatches(nil, documentBytes)
+	if err != nil {
+		return "", false, err
+	}
+	patched, report, err := indicator.ApplyPatchesWithReport(patches, documentBytes)
+	if err != nil {
+		return "", false, err
+	}
+
+	var output strings.Builder
+	for _, p := range report.Patches {
+		switch {
+		case !p.Matched:
+			fmt.Fprintf(&output, "%s: not matched: %s\n", p.Origin, p.Reason)
+		case p.Error != "":
+			fmt.Fprintf(&output, "%s: matched, not applied: %s\n", p.Origin, p.Error)
+		default:
+			fmt.Fprintf(&output, "%s: matched\n", p.Origin)
+		}
+		for _, o := range p.Operations {
+			if o.Applied {
+				fmt.Fprintf(&output, "  %s %s: applied\n", o.Type, o.Path)
+			} else if o.Error != "" {
+				fmt.Fprintf(&output, "  %s %s: failed: %s\n", o.Type, o.Path, o.Error)
+			}
+		}
+	}
+	for _, c := range report.Conflicts {
+		fmt.Fprintf(&output, "conflict: %s is written by", c.Path)
+		for _, p := range c.Patches {
+			fmt.Fprintf(&output, " %s (priority %d)", p.Origin, p.Priority)
+		}
+		output.WriteString("\n")
+	}
+	output.WriteString("\n")
+	output.WriteString(lineDiff(docPath, "patched", string(original), string(patched)))
+
+	return output.String(), !report.Failed(), nil
+}
+
+// The number of unchanged lines shown around each change in a diff.
+const diffContext = 3
+
+type diffLine struct {
+	prefix string
+	text   string
+}
+
+// Returns a unified diff of the two documents, in which only the changed lines and their context
+// are shown.
+func lineDiff(name string, afterLabel string, before string, after string) string {
+	if before == after {
+		return "no changes\n"
+	}
+
+	dmp := diffmatchpatch.New()
+	beforeChars, afterChars, lines := dmp.DiffLinesToChars(before, after)
+	diffs := dmp.DiffCharsToLines(dmp.DiffMain(beforeChars, afterChars, false), lines)
+
+	var diffLines []diffLine
+	for _, d := range diffs {
+		prefix := " "
+		switch d.Type {
+		case diffmatchpatch.DiffDelete:
+			prefix = "-"
+		case diffmatchpatch.DiffInsert:
+			prefix = "+"
+		}
+		for _, line := range strings.SplitAfter(d.Text, "\n") {
+			if line != "" {
+				diffLines = append(diffLines, diffLine{prefix: prefix, text: line})
+			}
+		}
+	}
+
+	var output strings.Builder
+	fmt.Fprintf(&output, "--- %s\n+++ %s (%s)\n", name, name, afterLabel)
+	for start := 0; start < len(diffLines); {
+		first := nextChange(diffLines, start)
+		if first == len(diffLines) {
+			break
+		}
+		// Changes separated by no more than twice the context share a hunk.
+		last := first
+		for {
+			next := nextChange(diffLines, last+1)
+			if next == len(diffLines) || next-last > 2*diffContext+1 {
+				break
+			}
+			last = next
+		}
+		from := first - diffContext
+		if from < start {
+			from = start
+		}
+		to := last + diffContext + 1
+		if to > len(diffLines) {
+			to = len(diffLines)
+		}
+		writeHunk(&output, diffLines, from, to)
+		start = to
+	}
+	return output.String()
+}
+
+func nextChange(lines []diffLine, from int) int {
+	for i := from; i < len(lines); i++ {
+		if lines[i].prefix != " " {
+			return i
+		}
+	}
+	return len(lines)
+}
+
+func writeHunk(output *strings.Builder, lines []diffLine, from int, to int) {
+	beforeStart, afterStart := 1, 1
+	for _, l := range lines[:from] {
+		if l.prefix != "+" {
+			beforeStart++
+		}
+		if l.prefix != "-" {
+			afterStart++
+		}
+	}
+	beforeCount, afterCount := 0, 0
+	for _, l := range lines[from:to] {
+		if l.prefix != "+" {
+			beforeCount++
+		}
+		if l.prefix != "-" {
+			afterCount++
+		}
+	}
+	// An empty range is given as the line before it.
+	if beforeCount == 0 {
+		beforeStart--
+	}
+	if afterCount == 0 {
+		afterStart--
+	}
+
+	fmt.Fprintf(output, "@@ -%d,%d +%d,%d @@\n", beforeStart, beforeCount, afterStart, afterCount)
+	for _, l := range lines[from:to] {
+		output.WriteString(l.prefix + l.text)
+		if !strings.HasSuffix(l.text, "\n") {
+			output.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
 func getDocument(docPath string, opts ...indicator.ReadOpt) v1.IndicatorDocument {
 	l := log.New(os.Stderr, "", 0)
+	opts = append(opts, indicator.ReportWarnings(func(warning error) {
+		l.Printf("warning: %s", indicator.DescribeError(warning))
+	}))
 	document, err := indicator.ReadFile(docPath, opts...)
 	if err != nil {
 		l.Fatal(err)
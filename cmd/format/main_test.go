@@ -58,6 +58,120 @@ func TestFormatBinary(t *testing.T) {
 		g.Expect(session.Err).To(gbytes.Say(regexp.QuoteMeta("found raw un-interpolated ERB, please check your input for ERB")))
 	})
 
+	t.Run("renders erb with the given properties", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		cmd := exec.Command(binPath,
+			"-format", "prometheus-alerts",
+			"-indicators", "test_fixtures/erb-doc.yml",
+			"-properties", "test_fixtures/properties.yml")
+
+		buffer := bytes.NewBuffer(nil)
+
+		session, err := gexec.Start(cmd, buffer, nil)
+		g.Expect(err).ToNot(HaveOccurred())
+
+		g.Eventually(session, 5).Should(gexec.Exit(0))
+
+		output := buffer.String()
+		g.Expect(output).To(ContainSubstring(`expr: latency{source_id="api"} > 500`))
+		g.Expect(output).To(ContainSubstring("deployment: my-deployment"))
+	})
+
+	t.Run("lints the document", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		cmd := exec.Command(binPath,
+			"-format", "lint",
+			"-indicators", "test_fixtures/lint-doc.yml")
+
+		buffer := bytes.NewBuffer(nil)
+
+		session, err := gexec.Start(cmd, buffer, nil)
+		g.Expect(err).ToNot(HaveOccurred())
+
+		g.Eventually(session, 5).Should(gexec.Exit(1))
+
+		output := buffer.String()
+		g.Expect(output).To(ContainSubstring("warning: test_fixtures/lint-doc.yml:12:5 spec.indicators[0].promql: indicators[0].promql [rate-over-gauge]"))
+		g.Expect(output).To(ContainSubstring("- test_fixtures/lint-doc.yml:14:5 spec.indicators[1].promql: indicators[1].promql [histogram-quantile-le]"))
+	})
+
+	t.Run("dry-runs patches against the document", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		cmd := exec.Command(binPath,
+			"-format", "patch-dry-run",
+			"-indicators", "test_fixtures/patch-doc.yml",
+			"-patches", "test_fixtures/patch.yml,test_fixtures/other-patch.yml")
+
+		buffer := bytes.NewBuffer(nil)
+
+		session, err := gexec.Start(cmd, buffer, nil)
+		g.Expect(err).ToNot(HaveOccurred())
+
+		g.Eventually(session, 5).Should(gexec.Exit(1))
+
+		output := buffer.String()
+		g.Expect(output).To(ContainSubstring("test_fixtures/patch.yml: matched\n"))
+		g.Expect(output).To(ContainSubstring("  replace /spec/indicators/0/promql: applied\n"))
+		g.Expect(output).To(ContainSubstring("  remove /spec/indicators/1: failed: "))
+		g.Expect(output).To(ContainSubstring(`test_fixtures/other-patch.yml: not matched: product name "my-product" does not equal "other-product"`))
+		g.Expect(output).To(ContainSubstring("-    promql: latency_ms\n+    promql: max(latency_ms)\n"))
+		g.Expect(output).To(ContainSubstring("@@ -6,7 +6,7 @@\n"))
+		g.Expect(output).ToNot(ContainSubstring("apiVersion"), "the unchanged lines away from the change should be omitted")
+	})
+
+	t.Run("formats the document canonically", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		cmd := exec.Command(binPath,
+			"-format", "canonical",
+			"-defaults", "hide",
+			"-indicators", "test_fixtures/uncanonical-doc.yml")
+
+		buffer := bytes.NewBuffer(nil)
+
+		session, err := gexec.Start(cmd, buffer, nil)
+		g.Expect(err).ToNot(HaveOccurred())
+
+		g.Eventually(session, 5).Should(gexec.Exit(0))
+
+		expected, err := ioutil.ReadFile("test_fixtures/canonical-doc.yml")
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(buffer.String()).To(Equal(string(expected)))
+	})
+
+	t.Run("checks whether the document is formatted canonically", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		cmd := exec.Command(binPath,
+			"-format", "canonical",
+			"-check",
+			"-indicators", "test_fixtures/canonical-doc.yml")
+
+		session, err := gexec.Start(cmd, nil, nil)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Eventually(session, 5).Should(gexec.Exit(0))
+
+		cmd = exec.Command(binPath,
+			"-format", "canonical",
+			"-check",
+			"-indicators", "test_fixtures/uncanonical-doc.yml")
+
+		buffer := bytes.NewBuffer(nil)
+
+		session, err = gexec.Start(cmd, buffer, nil)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Eventually(session, 5).Should(gexec.Exit(1))
+
+		output := buffer.String()
+		g.Expect(output).To(ContainSubstring("+++ test_fixtures/uncanonical-doc.yml (canonical)\n"))
+		g.Expect(output).To(ContainSubstring("@@ -2,22 +2,22 @@\n"))
+		g.Expect(output).To(ContainSubstring(`-      operator: ">"`))
+		g.Expect(output).To(ContainSubstring("+          operator: gt\n"))
+	})
+
 	t.Run("outputs formatted HTML", func(t *testing.T) {
 		g := NewGomegaWithT(t)
 
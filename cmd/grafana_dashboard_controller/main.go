@@ -22,6 +22,7 @@ func main() {
 	clientKey := flag.String("tls-key-path", "", "Client TLS private key path which can connect to the server (indicator-registry)")
 	rootCACert := flag.String("tls-root-ca-pem", "", "Root CA Pem for self-signed certs")
 	serverCommonName := flag.String("tls-server-cn", "indicator-registry", "server (indicator-registry) common name")
+	watch := flag.Bool("watch", false, "Update when the registry's documents change instead of every minute")
 
 	flag.Parse()
 
@@ -50,6 +51,7 @@ func main() {
 		RegistryAPIClient: apiClient,
 		OutputDirectory:   *outputDirectory,
 		UpdateFrequency:   time.Minute,
+		Watch:             *watch,
 		DocType:           "grafana dashboard",
 		Converter: func(document v1.IndicatorDocument) (file *exporter.File, e error) {
 			grafanaDashboard, err := grafana_dashboard.ToGrafanaDashboard(document, indicatorType)
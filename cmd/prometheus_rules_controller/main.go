@@ -24,6 +24,7 @@ func main() {
 	clientKey := flag.String("tls-key-path", "", "Client TLS private key path which can connect to the server (indicator-registry)")
 	rootCACert := flag.String("tls-root-ca-pem", "", "Root CA Pem for self-signed certs")
 	serverCommonName := flag.String("tls-server-cn", "indicator-registry", "server (indicator-registry) common name")
+	watch := flag.Bool("watch", false, "Update when the registry's documents change instead of every minute")
 	prometheusURI := flag.String("prometheus", "", "URI of a Prometheus server instance")
 
 	flag.Parse()
@@ -51,6 +52,7 @@ func main() {
 		RegistryAPIClient: apiClient,
 		OutputDirectory:   *outputDirectory,
 		UpdateFrequency:   time.Minute,
+		Watch:             *watch,
 		DocType:           "prometheus alert",
 		Converter:         Convert,
 		Reloader:          prometheusClient.Reload,
@@ -18,7 +18,11 @@ import (
 	"gopkg.in/src-d/go-git.v4/storage/memory"
 
 	"github.com/pivotal/monitoring-indicator-protocol/pkg/configuration"
+	"github.com/pivotal/monitoring-indicator-protocol/pkg/indicator"
+	v1 "github.com/pivotal/monitoring-indicator-protocol/pkg/k8s/apis/indicatordocument/v1"
 	"github.com/pivotal/monitoring-indicator-protocol/pkg/registry"
+	"github.com/pivotal/monitoring-indicator-protocol/pkg/registry/storage"
+	"github.com/pivotal/monitoring-indicator-protocol/pkg/registry/webhook"
 )
 
 func main() {
@@ -26,12 +30,53 @@ func main() {
 	host := flag.String("host", "localhost", "Host to bind to for registration endpoints")
 	expiration := flag.Duration("indicator-expiration", 120*time.Minute, "Document expiration duration")
 	configFile := flag.String("config", "", "Configuration yaml for patch and document sources")
+	importsDir := flag.String("imports-dir", "", "Directory containing the documents that registered documents can import")
+	webhooksFile := flag.String("webhooks-config", "", "Configuration yaml for the webhooks notified of indicator status transitions")
+	storageDir := flag.String("storage-dir", "", "Directory to persist registered documents and statuses in, so that they survive restarts. They are only kept in memory if empty")
 
 	flag.Parse()
 
 	address := fmt.Sprintf("%s:%d", *host, *port)
 
-	store := registry.NewDocumentStore(*expiration, time.Now)
+	var documentStoreOpts []registry.DocumentStoreOpt
+	var statusStoreOpts []status_store.Opt
+	if *storageDir != "" {
+		s, err := storage.NewDirectory(*storageDir)
+		if err != nil {
+			log.Fatalf("failed to open storage: %s", err)
+		}
+		documentStoreOpts = append(documentStoreOpts, registry.WithStorage(s))
+		statusStoreOpts = append(statusStoreOpts, status_store.WithStorage(s))
+	}
+
+	// Resource versions start from the time of startup, so that watches cannot resume after the
+	// versions of an earlier registry.
+	watchHub := registry.NewWatchHub(uint64(time.Now().UnixNano() / int64(time.Microsecond)))
+	documentStoreOpts = append(documentStoreOpts, registry.WithChangeHandler(watchHub.DocumentChanged))
+	statusStoreOpts = append(statusStoreOpts, status_store.WithTransitionHandler(watchHub.StatusChanged))
+
+	// The status store is created first, so that the statuses of documents that expired while the
+	// registry was stopped are deleted as the documents are restored.
+	var store *registry.DocumentStore
+	lookupDocument := func(uid string) (v1.IndicatorDocument, bool) {
+		return store.Document(uid)
+	}
+	statusStoreOpts = append(statusStoreOpts, status_store.WithDocumentLookup(lookupDocument))
+	if *webhooksFile != "" {
+		webhooks, err := webhook.ReadConfigFile(*webhooksFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		dispatcher := webhook.NewDispatcher(webhooks, lookupDocument)
+		dispatcher.Start()
+		defer dispatcher.Stop()
+		statusStoreOpts = append(statusStoreOpts, status_store.WithTransitionHandler(dispatcher.Notify))
+	}
+	statusStore := status_store.New(time.Now, statusStoreOpts...)
+	documentStoreOpts = append(documentStoreOpts, registry.WithChangeHandler(registry.StatusCleanup(statusStore)))
+
+	store = registry.NewDocumentStore(*expiration, time.Now, documentStoreOpts...)
+	go expireEachMinute(store)
 
 	if *configFile != "" {
 		upsertFromConfig(*configFile, store)
@@ -41,7 +86,11 @@ func main() {
 	config := registry.WebServerConfig{
 		Address:       address,
 		DocumentStore: store,
-		StatusStore:   status_store.New(time.Now),
+		StatusStore:   statusStore,
+		WatchHub:      watchHub,
+	}
+	if *importsDir != "" {
+		config.ImportResolver = indicator.DirectoryImportResolver(*importsDir)
 	}
 
 	start, stop := registry.NewWebServer(config)
@@ -65,6 +114,18 @@ func readConfigEachMinute(configFile string, store *registry.DocumentStore) {
 	}
 }
 
+// expireEachMinute expires documents while nothing reads them, so that watchers learn of it.
+func expireEachMinute(store *registry.DocumentStore) {
+	timer := time.NewTicker(time.Minute)
+
+	for {
+		select {
+		case <-timer.C:
+			store.ExpireDocuments()
+		}
+	}
+}
+
 func upsertFromConfig(configFile string, store *registry.DocumentStore) {
 	sources, err := configuration.ParseSourcesFile(configFile)
 	if err != nil {
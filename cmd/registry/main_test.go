@@ -96,6 +96,44 @@ func TestIndicatorRegistry(t *testing.T) {
 		})
 	})
 
+	t.Run("it restores documents and statuses from its storage directory", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		storageDir, err := ioutil.TempDir("", "registry-storage")
+		g.Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(storageDir)
+
+		withServer("10567", g, func(serverUrl string) {
+			file, err := os.Open("test_fixtures/indicators.yml")
+			g.Expect(err).ToNot(HaveOccurred())
+
+			resp, err := http.Post(serverUrl+"/v1/register", "text/plain", file)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+			file, err = os.Open("test_fixtures/bulk_status_request.json")
+			g.Expect(err).ToNot(HaveOccurred())
+
+			resp, err = http.Post(serverUrl+"/v1/indicator-documents/my-other-component-62a5511746dfd09059ced03b2ed73ff0ae942421/bulk_status", "text/plain", file)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		}, "--storage-dir", storageDir)
+
+		withServer("10567", g, func(serverUrl string) {
+			resp, err := http.Get(serverUrl + "/v1/indicator-documents")
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+			responseBytes, err := ioutil.ReadAll(resp.Body)
+			g.Expect(err).ToNot(HaveOccurred())
+
+			expectedJSON, err := ioutil.ReadFile("test_fixtures/status_response.json")
+			g.Expect(err).ToNot(HaveOccurred())
+
+			g.Expect(responseBytes).Should(ContainOrderedJSON(expectedJSON))
+		}, "--storage-dir", storageDir)
+	})
+
 	t.Run("it retrieves documents by product name", func(t *testing.T) {
 		g := NewGomegaWithT(t)
 
@@ -164,14 +202,14 @@ func TestIndicatorRegistry(t *testing.T) {
 }
 
 func withConfigServer(port, configPath string, g *GomegaWithT, testFun func(string)) {
+	withServer(port, g, testFun, "--config", configPath)
+}
+
+func withServer(port string, g *GomegaWithT, testFun func(string), args ...string) {
 	binPath, err := go_test.Build("./", "-race")
 	g.Expect(err).ToNot(HaveOccurred())
 
-	cmd := exec.Command(
-		binPath,
-		"--port", port,
-		"--config", configPath,
-	)
+	cmd := exec.Command(binPath, append([]string{"--port", port}, args...)...)
 
 	var outW, errW io.Writer
 	if testing.Verbose() {
@@ -23,6 +23,7 @@ func main() {
 	clientKey := flag.String("tls-key-path", "", "Client TLS private key path which can connect to the server (indicator-registry)")
 	rootCACert := flag.String("tls-root-ca-pem", "", "Root CA Pem for self-signed certs")
 	serverCommonName := flag.String("tls-server-cn", "indicator-registry", "server (indicator-registry) common name")
+	deregister := flag.Bool("deregister", false, "Deregister the documents once instead of registering them on an interval, e.g. when they are no longer deployed")
 	flag.Parse()
 
 	startMetricsEndpoint()
@@ -46,6 +47,13 @@ func main() {
 		IntervalTime:   *intervalTime,
 		Client:         client,
 	}
+	if *deregister {
+		err := agent.Deregister()
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
 	agent.Start()
 }
 
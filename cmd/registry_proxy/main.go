@@ -14,6 +14,7 @@ import (
 	"net/http"
 
 	"github.com/pivotal/monitoring-indicator-protocol/pkg/tls_config"
+	"github.com/pivotal/monitoring-indicator-protocol/pkg/registry"
 	"github.com/pivotal/monitoring-indicator-protocol/pkg/registry_proxy"
 )
 
@@ -60,10 +61,9 @@ func main() {
 
 	server := &http.Server{
 		Addr:         address,
-		Handler:      registry_proxy.NewHandler(localRegistryHandler, registryHandlers),
-		TLSConfig:    tlsConfig,
-		ReadTimeout:  5 * time.Second,
-		WriteTimeout: 10 * time.Second,
+		Handler:     registry.WriteTimeoutHandler(registry_proxy.NewHandler(localRegistryHandler, registryHandlers), 10*time.Second),
+		TLSConfig:   tlsConfig,
+		ReadTimeout: 5 * time.Second,
 	}
 	log.Printf("Listening for request on port %d", *port)
 	_ = server.ListenAndServeTLS(*serverPEM, *serverKey)
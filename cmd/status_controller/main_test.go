@@ -130,9 +130,11 @@ func setupFakeRegistry(g *GomegaWithT) *ghttp.Server {
 			g.Expect(err).NotTo(HaveOccurred())
 
 			status := "warning"
+			value := 10.0
 			g.Expect(indicatorStatuses).To(BeEquivalentTo([]registry.ApiV1UpdateIndicatorStatus{{
 				Name:   "very_good_indicator",
 				Status: &status,
+				Value:  &value,
 			}}))
 
 			w.WriteHeader(http.StatusOK)
@@ -25,6 +25,8 @@ func main() {
 	prometheusURI := flag.String("query-endpoint", "", "the query url of a Prometheus compliant store (e.g. https://metric-store.system.cfapp.com")
 	authorization := flag.String("authorization", "", "the authorization header sent to prometheus (e.g. 'bearer abc-123')")
 	insecure := flag.Bool("k", false, "skips ssl verification (insecure)")
+	propertiesFilePath := flag.String("properties", "", "BOSH job properties YAML file path used to render the ERB in the indicators file")
+	strictInterpolation := flag.Bool("strict-interpolation", false, "interpolate v1 documents like v2 documents, failing on undefined variables and supporting $$ escapes")
 	showVersion := flag.Bool("version", false, "show CLI version")
 
 	flag.Parse()
@@ -36,8 +38,19 @@ func main() {
 
 	checkRequiredFlagsArePresent(*indicatorsFilePath, *prometheusURI, *authorization)
 
-	document, err := indicator.ReadFile(*indicatorsFilePath,
-		indicator.OverrideMetadata(indicator.ParseMetadata(*metadata)))
+	readOpts := []indicator.ReadOpt{indicator.OverrideMetadata(indicator.ParseMetadata(*metadata))}
+	if len(*propertiesFilePath) > 0 {
+		properties, err := indicator.ReadPropertiesFile(*propertiesFilePath)
+		if err != nil {
+			l.Fatal(err)
+		}
+		readOpts = append(readOpts, indicator.RenderERB(properties))
+	}
+	if *strictInterpolation {
+		readOpts = append(readOpts, indicator.StrictInterpolation)
+	}
+
+	document, err := indicator.ReadFile(*indicatorsFilePath, readOpts...)
 
 	if err != nil {
 		l.Fatal(err)
@@ -59,6 +72,11 @@ func main() {
 	for _, ind := range document.Spec.Indicators {
 		stdOut.Println()
 
+		if ind.IsComposite() {
+			stdOut.Printf("Skipping composite indicator with name \"%s\", it has no query of its own", ind.Name)
+			continue
+		}
+
 		stdOut.Printf("Querying for indicator with name \"%s\"", ind.Name)
 		stdOut.Printf("  query: %s", ind.PromQL)
 
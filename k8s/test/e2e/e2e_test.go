@@ -255,7 +255,7 @@ func TestAdmission(t *testing.T) {
 		_, err := clients.idClient.IndicatorDocuments(ns).Create(id)
 
 		g.Expect(err).To(HaveOccurred())
-		g.Expect(err.Error()).To(ContainSubstring("spec.indicators.thresholds.operator in body should be one of [lt lte gt gte eq neq]"))
+		g.Expect(err.Error()).To(ContainSubstring("spec.indicators.thresholds.operator in body should be one of [lt lte gt gte eq neq between outside]"))
 	})
 }
 
@@ -1,3 +1,4 @@
 package api_versions
 
 const V1 = "indicatorprotocol.io/v1"
+const V2 = "indicatorprotocol.io/v2"
@@ -77,7 +77,7 @@ func (fi bindataFileInfo) Sys() interface{} {
 	return nil
 }
 
-var _schemasYml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xac\x56\x51\xaf\xab\x36\x0c\x7e\xe7\x57\x58\xea\xa4\x4a\x13\xb4\xbb\x8f\xe3\x6d\xd2\x5e\x26\xed\xed\x6e\xf7\x61\x47\xd5\x69\x08\x2e\x64\x0d\x71\x9a\x98\x9e\xb1\x5f\x3f\x05\x28\xa5\x85\x96\x4e\xe7\x46\xaa\x44\x8c\xe3\xf8\xfb\xfc\xd9\x65\x05\x7f\x7a\x84\x7d\x41\x49\xa6\x4c\x2e\x58\x40\x42\x60\x8f\xc5\x56\x78\x8f\xbc\xf5\xb2\xc4\x4a\x6c\x0a\x82\xc4\x1e\x0b\x68\x8d\xd0\x19\xfd\xa6\xa9\xf4\x1e\x0e\x8e\x2a\xe0\x12\xc1\xa1\x25\x70\x44\x0c\x4c\x90\xd5\x26\xd7\x18\xad\x80\x4b\xe5\xa1\x8d\xab\x0c\x13\x08\x28\x08\x0e\x4a\x23\x78\x02\x2e\x05\x83\x62\x90\xc2\x40\x86\xa0\x8c\xd4\x75\x8e\x39\x28\x03\xf8\x0f\xca\x9a\x45\xa6\xd1\x6f\xa2\x24\x49\xa2\xdf\x4c\xae\xa4\x60\x72\xbf\x92\xac\x2b\x34\x9c\x46\x00\xdc\x58\x4c\x81\xb2\xbf\x51\x72\x04\xe0\xf0\x54\x2b\x87\x79\x78\x95\x80\xb0\xea\x1b\x3a\xaf\xc8\xb4\xdb\xa3\x32\x79\xfb\xe0\x2d\xca\x08\xc0\x3a\xb2\xe8\x58\xa1\x0f\xee\x30\x72\xef\xf6\x97\xe8\x9e\x9d\x32\x45\x6f\x42\x53\x57\x29\xbc\xa9\x4b\x32\xd6\x11\x93\x24\xbd\x51\xb4\x3d\x7f\xd9\xb5\x5e\xe1\xa2\xe5\x10\x13\x3c\xdd\xe1\x0a\x59\x04\xb6\x6e\x03\x0c\x08\xc3\xba\x4f\x3c\x2c\x23\x2a\xbc\xee\x66\xef\x05\xd0\x22\x43\xed\xa7\x6e\x5d\x74\x58\x85\x3a\x18\x62\x90\x64\x58\x28\x03\x7b\xcf\x68\xf7\x70\xc4\xa6\x3d\x12\x78\xbb\x1c\xfe\xc1\xe1\x21\x85\xf5\x6a\x3b\x81\xf1\xd5\xa2\x5c\x4f\xab\xf5\xb5\x3f\xfc\xb4\x62\xd6\x51\x5e\xb7\xf6\x7b\x8c\xfd\x9b\x27\xac\x8c\x23\x85\x95\xb4\x9c\x0c\x9b\xf3\xa0\x84\x4f\x51\x08\x50\x29\xf3\x3b\x9a\x82\xcb\x14\xbe\x0c\xe6\xf3\xad\x70\xfe\xcf\xf1\x41\x4a\xfe\x16\x9c\x70\x4e\x34\xbd\x45\x31\x56\xa3\x4c\xa7\xe4\x77\xa4\x77\x35\x6e\xa8\x7e\xc6\xd3\x1c\x74\xfa\x30\xe8\x16\x93\x67\xc5\x7a\x99\xa1\x1c\xbd\x74\xca\xf2\x2b\x74\x78\x94\xc1\x6f\x46\x92\x63\xf4\x33\x0c\x3c\xc0\xf6\x18\xe1\x03\x04\x4f\x4b\xf5\x04\xcd\xe2\xb9\x69\x55\x97\x10\x3e\xc4\x39\xbd\x0b\x56\x60\x08\x3e\x44\x13\x06\xed\x59\x68\x95\x0b\xc6\x30\x82\x3d\x46\x37\x92\x58\x6c\xb8\xbe\x45\xda\xce\xab\x4e\x7a\xa6\xf1\xc6\x5d\x31\x83\xd7\x0a\x66\x74\x26\x85\xf5\x9b\x48\xfe\xfd\x25\xf9\xeb\x3d\xdd\xf5\x4f\x3f\x25\x3f\xbf\xa7\xbb\x1f\xd7\x97\xfe\xad\x4e\xfa\x49\xa0\x49\x5f\xb4\x3e\x8b\x53\xf4\x68\x55\x0c\x5e\xab\x18\x88\x4b\x74\xdd\x0c\xe5\xd2\xa1\x2f\x49\xe7\xaf\xb7\xd4\xac\x98\xee\x47\x4a\x20\x4a\xe3\x19\xf5\x68\x1f\xd8\x0a\x74\x8f\x4c\x67\xa1\x6b\x1c\xf6\xf3\x6a\x6c\xc3\xcc\xe9\x79\xa2\xa6\xcb\x05\x2f\x39\x0f\xbc\x68\x8e\x41\x33\xc6\x50\x70\xf8\x61\x0c\x78\x8a\xc1\xe0\x69\x37\xf2\x6e\x13\x9d\x8b\x6b\xea\x2a\x43\x37\x7a\x21\x34\x3a\xfe\x74\xfb\x1d\xee\x51\xdc\x23\x69\x85\xcd\x20\x6b\xe7\xd0\xb0\x6e\x06\x71\xe7\x20\x3c\xe4\xb5\x13\x7c\x9d\xe1\x97\x15\xfe\xa4\xbe\x5b\x58\xeb\xd0\xa3\x61\xc1\x93\xcf\x80\xc5\x21\x2a\x4b\xe1\xf8\x8f\x91\x6a\x9f\xd4\xa9\xaf\x52\x48\x3d\x86\x4c\xb8\x18\x3c\x0b\xae\x7d\x0c\xa7\x9a\x58\x5c\x8b\xd4\xe7\xfc\xed\xbe\x52\x5d\xdc\x8c\x48\xa3\xb8\x12\x72\x08\x8a\x45\x23\x9b\xa9\xab\x32\x8c\xc5\xa8\xa6\x8f\xbe\x05\x5e\x1e\xbc\x77\x98\x6a\xa3\x78\x26\xdc\xc8\x2b\xef\xbf\x04\x1e\x73\x1b\xfd\x17\x00\x00\xff\xff\xb5\xce\x5b\xea\x8c\x0a\x00\x00")
+var _schemasYml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xec\x58\x4d\x6f\xdb\x3c\x12\xbe\xeb\x57\x0c\xe0\x02\x01\x16\x52\x3e\xdc\x53\x7c\xcb\x6e\xf7\x50\xc0\xc5\x06\xfd\xf0\x61\x8b\x6e\x4d\x4b\x63\x89\x1b\x8a\x54\xc8\x91\x13\xed\xaf\x5f\x90\xa2\x64\x59\x1f\x8e\x93\x74\xb7\xc5\xfb\xf6\x50\x34\x26\x87\xc3\x87\xcf\xcc\x3c\x1c\x6a\x06\x5f\x0c\xc2\x3a\x55\xd1\x86\xcb\x84\x11\x83\x48\x41\x71\x97\x5e\x30\x63\x90\x2e\x4c\x9c\x61\xce\xce\x53\x05\x51\x71\x97\x82\x1b\x84\x7a\xd0\x9c\x57\xb9\x58\xc3\x56\xab\x1c\x28\x43\xd0\x58\x28\xd0\x4a\x11\x90\x82\x4d\x29\x13\x81\xc1\x0c\x28\xe3\x06\x9c\x5f\x2e\x49\x01\x83\x54\xc1\x96\x0b\x04\xa3\x80\x32\x46\xc0\x09\x62\x26\x61\x83\xc0\x65\x2c\xca\x04\x13\xe0\x12\xf0\x11\xe3\x92\xd8\x46\xa0\x39\x0f\xa2\x28\x0a\xde\xcb\x84\xc7\x8c\x94\x7e\xa7\xe2\x32\x47\x49\x8b\x00\x80\xaa\x02\x17\xa0\x36\xff\xc6\x98\x02\x00\x8d\xf7\x25\xd7\x98\xd8\xa9\x08\x58\xc1\x57\xa8\x0d\x57\xd2\xfd\xbc\xe3\x32\x71\x7f\x98\x02\xe3\x00\xa0\xd0\xaa\x40\x4d\x1c\x8d\x35\x87\x8e\x79\xfd\xbb\xf1\x6e\x48\x73\x99\xfa\x21\x94\x65\xbe\x80\xaf\xbc\x01\x53\x68\x45\x2a\x56\xe2\x9c\xab\x8b\xdd\xd5\x37\x67\x65\x37\x7a\xda\xc5\xe0\x3c\xf5\xe2\x1c\x89\x59\xb6\x1a\x07\x6f\x34\x6e\x17\x70\x36\xbb\xf8\xe0\x27\xce\xdc\x84\x3d\xc3\xc0\x64\xe0\xf2\x53\x81\xf1\xd9\x90\xb9\x4f\x7e\xf1\x51\xf6\x0a\xad\x92\x32\xa6\x11\xa2\xfc\xcc\x60\xfb\xdb\x7a\xbc\x06\x98\xe0\x96\x95\x82\xcc\xc0\xaa\x01\xf1\xce\x1b\xd4\xe6\x3c\x2f\x94\xde\x5b\xd7\xc8\x98\xd6\xac\xf2\x23\x9c\x30\x6f\xa7\x3b\xee\xde\xbb\x85\xde\x49\x73\xd0\x97\xf8\x69\xd6\x5a\x72\x7a\xee\x3e\x63\x5e\x08\x46\xf8\x1a\xb7\x8d\x0f\x1f\x3e\xa1\x5e\xe0\xec\x13\xea\x1d\x8f\x71\x89\x3b\x14\xff\x70\x39\xcf\x77\xde\xa1\x60\x95\x2a\x69\x11\xf4\x56\x2c\xdd\x70\x27\x05\x4e\x0a\xbd\x64\x39\x8e\xc4\xdd\x0e\x1f\xc9\xeb\x82\x11\xa1\x96\x0b\x38\xfb\xca\xa2\xff\xdc\x44\xff\xfc\xbe\xf8\xe6\xff\xba\x8c\xae\xbf\x2f\xbe\xfd\xe5\xac\x49\x9f\xfc\x5e\x1c\x71\x94\x73\xb9\x44\x99\x52\xb6\x80\x2b\x98\xb5\xd8\xa0\x94\x02\x8d\x71\x52\xd3\x86\x06\xb8\x81\x58\xe5\x85\x32\x9c\x2c\x66\xd8\xff\x1a\x90\xf1\xb7\x66\xa6\xc6\xe1\x76\x9e\x46\xe1\xcb\xf4\xae\xe0\x21\x18\xc1\x43\x50\x94\xa1\xae\x8b\x94\x32\x8d\x26\x53\x22\x79\x41\x10\x3f\x37\x6b\x6b\x14\x4c\xa0\x1e\xc6\xed\xc6\x8e\xb6\x15\x0f\x33\x60\x45\x21\x38\x1a\xab\xac\x4c\x88\x0e\x00\x50\xdb\x43\x46\x3c\xc7\x68\x50\x12\xa3\x8e\x9a\xb5\xce\x6f\x3b\x93\xbe\x58\x7d\x4d\x1e\xd8\xf7\x52\xc4\x96\x74\xa1\x31\x66\x84\x3d\x75\xdb\x28\x25\x90\xc9\x03\x1b\xae\xe4\x07\x34\x86\xa5\xd3\x14\x6b\x2c\x04\x8b\x31\xf9\x6b\x35\x6a\x32\x50\xad\xd5\xfc\xd7\x53\xfc\xf9\xaf\xa8\xf8\xab\xf9\x84\xe6\xaf\xe6\xbf\x55\xff\x88\xea\xaf\xe6\x3d\x87\x3f\x52\xf7\x1b\xe7\x3f\x55\xf9\x57\xf3\xdf\xda\xff\x6a\xed\xe7\x24\xa6\xd7\x26\x68\x62\xcd\x8b\xa1\x8e\x76\x6c\xfe\x74\xb7\x07\xcc\x60\xed\x68\x5b\x03\x93\x09\xac\x3b\x24\xad\x81\x69\x04\x52\x45\x24\x6c\x57\xd3\x09\xee\x96\xa3\x05\xc8\x25\xec\xe6\x9e\xda\xff\xe7\xf5\xd3\x17\xac\x7e\xe1\x0c\x59\x6d\xa1\xb7\xac\x36\xc4\x84\x9d\xf4\xad\xc0\x3e\xa0\xec\x8d\xed\x0e\x38\x52\x6a\x47\x62\x6a\x63\xb9\x55\xda\xb1\x68\x08\x0b\x07\xa1\xb2\x00\x70\x87\xba\xda\x07\xf6\x7f\x12\xc6\xa0\x2d\xa2\x27\x55\x44\x97\xc2\x56\x64\xd4\x21\x65\xe4\xa4\xd6\x6a\x94\xfc\x83\x2a\x64\xb2\x0a\x6d\xda\x86\xf0\xa0\xb4\xa1\x10\x36\x68\xfc\xd5\xe9\x72\x66\xcc\x43\x57\x3a\x2c\x61\x96\x6f\x26\x2b\x47\x9c\x8d\x95\xdd\xd9\x9c\x7c\x7b\xe4\x5c\xbe\x77\xc2\x0c\x57\xe3\xe5\xd9\xdb\xda\x8a\xa5\x4b\x02\xd7\x32\x86\x20\x95\x8c\x5a\x69\xea\xec\x68\xdf\xb9\xdd\x3c\x09\xea\x6b\xed\x49\x76\x0b\x46\xd9\x08\x9d\x76\xf8\x08\x9d\x5d\x69\x3d\xaa\x43\x6d\x82\xb7\x48\xdd\xad\x60\xd3\xcc\xa2\xdd\x2f\xf3\x35\xc4\x65\xea\xd2\xbd\x7e\xc3\x61\x02\x4a\x7a\x72\x01\x58\x92\x70\x9b\x62\x4c\xdc\xf6\xd0\x8e\x53\x3d\xa0\x76\x5c\xfb\x06\xd7\xeb\x93\x9c\xb5\x67\xf1\x0c\x6a\x96\x23\xe1\x68\x5a\xb6\xa6\x8b\xa0\x87\x60\xe4\x91\xb8\xf7\x34\xb0\x6e\xb0\xdd\xb6\x26\x23\xc0\x57\xf3\x9f\x00\x7d\x35\x7f\x11\xf8\xe1\xd8\x22\xe8\x07\xf1\xb0\x56\x3a\xc1\xec\x1d\xf1\x78\x6a\x1c\xa4\xef\x68\xf3\x73\x6a\x23\x13\x41\xaa\x54\xf2\xf7\x1d\x4a\xb2\x84\x45\x40\x8a\x98\xe8\xfc\x56\x8d\xc7\xd7\x74\x3d\xff\x9a\x6a\x7b\xde\xd4\x44\xef\x31\x9c\x58\xa0\x30\x83\xbc\x34\x04\xb1\x92\xc4\xb8\x84\x37\x0f\x5c\x26\xea\xc1\x59\x76\x4e\xf0\x03\xbc\xb5\xe7\x3f\xf4\x25\xcb\x7c\x83\x1a\x66\x50\xa0\x8e\xed\x45\x91\x62\x08\x78\x9e\x9e\xc3\xf5\xf5\xf9\xf5\x7e\x0f\x9e\x5b\x91\xbe\xf4\x03\xf8\x18\x8b\xd2\xf0\x1d\x7e\x68\x66\x48\x97\xd8\x58\xb3\xc7\x7a\xec\xea\x72\x68\xcf\x1e\x7b\xf6\x35\xc4\xb1\x03\xc2\xac\x06\xf2\xf6\x32\x09\xdb\x17\x87\x55\xa7\xb7\x97\xc9\x89\x37\x59\xd3\x1c\x8d\x64\xd1\xb3\x72\x40\xb0\x0d\x8a\x29\xf9\x8c\x99\x94\x6a\x4f\xfa\xda\xde\xd8\x6b\xb8\xc3\x2a\xf0\x6f\xa6\xd3\x73\x78\xd7\xbe\x6a\x9f\x99\xa2\xdd\x2c\x70\x43\xde\xd3\xa9\x4b\x5a\xcd\x7d\x12\xab\xbb\x8b\xdd\x5f\x16\xa0\x97\xab\x3e\xda\xc9\x0b\xdb\x0d\x35\x0b\x9f\xee\x09\x04\x85\x20\x08\x43\x48\xc9\xfe\xb3\xa9\x79\x1f\x82\xc4\x7b\xdb\x1d\xd0\x03\xa2\x0c\x41\x95\x64\x78\x82\x75\xa7\xb0\x63\xa2\x9c\xca\xf0\xe6\x1c\xae\x53\xb0\xdd\x41\x83\xc3\x00\x3e\xc6\x58\x50\xe3\xd3\xb5\x0f\xde\xad\x73\x25\xd4\x03\xea\x53\xbc\x4e\x39\x28\x8b\xe2\x75\x0e\x34\xc6\x6a\x87\xba\x9a\xf0\x91\x55\x86\x50\xa3\xe1\x26\x04\x25\x45\xe5\xc0\x74\xc8\x73\x27\x4a\xfd\xeb\x29\x67\x14\x67\x9d\xbb\xa0\x1b\x6e\x98\xd5\xb9\xde\x76\x02\xf8\xc8\x62\xf2\x63\x8e\xdc\x67\x5c\xf8\x9d\x88\x1e\x6b\x76\x83\x9b\x66\xb2\x8b\x64\x24\xab\xb6\xe3\x29\x63\x3b\x31\x5b\x7f\xa5\xd6\x28\x49\x54\xb0\x63\x82\x27\xf6\x01\x01\xcc\x40\x52\x6a\xa7\x0f\xce\x83\x2d\xcd\x57\xba\x38\x94\x82\xc3\xa3\x2c\xdd\x9c\x7f\xb2\x59\x51\x70\x3b\x4f\x18\xdf\xec\x0d\xce\x7c\x9b\x2c\x37\x4a\xdd\x7d\xf9\xb8\x9c\x80\x78\x00\x8a\x49\x60\x1b\xa3\x44\x49\x08\x19\x51\x01\x4a\xbb\xff\x0d\x7c\xf9\xb8\x74\xeb\xd5\x83\xc4\x63\x35\x76\x20\x00\x07\x6f\xc9\x13\x62\xf1\x47\xa6\x61\xd9\x9e\x6d\xb2\x34\x8c\x7b\xcf\x1e\x20\xb1\xdf\xbb\x91\x32\x2c\x4d\xd7\x2e\x38\x56\x2a\x07\x68\xfa\x6c\x8c\x04\xe1\x44\x4f\xdd\x37\xdf\x09\xa1\x8c\x33\xa6\xe9\xf3\x49\x5f\x4a\x6c\xfd\x84\xb0\x61\x3a\x04\x43\x8c\x4a\x13\xc2\x7d\xa9\x88\xd5\xe2\xeb\xcb\x67\x35\xd4\xe0\xee\xdb\x7d\x6b\x15\x13\x65\xdc\xd3\x32\x2e\x09\x53\xd4\x23\xc9\x35\x7c\x3d\xf4\xde\x0e\x03\xbc\xa5\xe4\x13\x3d\xd3\xb2\xfd\x8a\xf6\x04\x29\xc7\xb3\xe6\xf5\x1f\x87\x0c\xc6\x6d\x94\x9f\x71\xc8\x16\xaf\xef\x4c\x7b\xa8\x01\x06\xe8\x26\xf6\x07\x98\x40\x7a\xd4\xbe\x7d\x77\x98\x31\xf3\x2e\xfa\xd1\x33\x0c\x7d\x3b\xe1\x85\x07\xe6\x3e\x6e\x34\xe5\x04\x94\xa1\xc1\xe0\xbf\x03\x00\x6d\xa6\x19\x4a\xb2\x1e\x00\x00")
 
 func schemasYmlBytes() ([]byte, error) {
 	return bindataRead(
@@ -92,7 +92,7 @@ func schemasYml() (*asset, error) {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "schemas.yml", size: 2700, mode: os.FileMode(420), modTime: time.Unix(1576881713, 0)}
+	info := bindataFileInfo{name: "schemas.yml", size: 7858, mode: os.FileMode(420), modTime: time.Unix(1792220014, 0)}
 	a := &asset{bytes: bytes, info: info}
 	return a, nil
 }
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"log"
+	"path"
 	"strings"
 
 	glob2 "github.com/gobwas/glob"
@@ -135,13 +136,32 @@ func parseRepositoryHead(s Source, r *git.Repository) ([]indicator.Patch, []v1.I
 		return nil, nil, fmt.Errorf("failed to fetch commit tree: %s\n", err)
 	}
 
-	return retrievePatchesAndDocuments(tree.Files(), s.Glob)
+	return retrievePatchesAndDocuments(tree.Files(), s.Glob, treeImportResolver(tree))
+}
+
+// Imports in git sources are resolved relative to the root of the repository.
+func treeImportResolver(tree *object.Tree) indicator.ImportResolver {
+	return func(_ string, importPath string) (string, []byte, error) {
+		location := path.Clean(strings.TrimPrefix(importPath, "/"))
+
+		file, err := tree.File(location)
+		if err != nil {
+			return "", nil, err
+		}
+
+		contents, err := file.Contents()
+		if err != nil {
+			return "", nil, err
+		}
+
+		return location, []byte(contents), nil
+	}
 }
 
 // The error returned is always nil, but keeping this signature allows us to return it directly in parseRepositoryHead
-func retrievePatchesAndDocuments(files *object.FileIter, glob string) ([]indicator.Patch, []v1.IndicatorDocument, error) {
+func retrievePatchesAndDocuments(files *object.FileIter, glob string, resolver indicator.ImportResolver) ([]indicator.Patch, []v1.IndicatorDocument, error) {
 	var patchesBytes []unparsedPatch
-	var documentsBytes [][]byte
+	var documentsBytes []unparsedDocument
 
 	if glob == "" {
 		glob = "*.y*ml"
@@ -163,7 +183,7 @@ func retrievePatchesAndDocuments(files *object.FileIter, glob string) ([]indicat
 			}
 
 			switch version {
-			case api_versions.V1:
+			case api_versions.V1, api_versions.V2:
 				kind, err := indicator.KindFromYAML([]byte(contents))
 				if err != nil {
 					log.Print("Could not get the `kind` of the document, ensure that this key is present.")
@@ -171,7 +191,7 @@ func retrievePatchesAndDocuments(files *object.FileIter, glob string) ([]indicat
 				}
 
 				if kind == kinds.IndicatorDocument {
-					documentsBytes = append(documentsBytes, []byte(contents))
+					documentsBytes = append(documentsBytes, unparsedDocument{[]byte(contents), f.Name})
 				} else if kind == kinds.Patch {
 					patchesBytes = append(patchesBytes, unparsedPatch{[]byte(contents), f.Name})
 				} else {
@@ -183,7 +203,7 @@ func retrievePatchesAndDocuments(files *object.FileIter, glob string) ([]indicat
 	})
 
 	patches := readPatches(patchesBytes)
-	documents := processDocuments(documentsBytes, patches)
+	documents := processDocuments(documentsBytes, patches, resolver)
 	return patches, documents, err
 }
 
@@ -192,25 +212,35 @@ type unparsedPatch struct {
 	Filename  string
 }
 
+type unparsedDocument struct {
+	YAMLBytes []byte
+	Filename  string
+}
+
 func readPatches(unparsedPatches []unparsedPatch) []indicator.Patch {
 	var patches []indicator.Patch
 	for _, p := range unparsedPatches {
 		reader := ioutil.NopCloser(bytes.NewReader(p.YAMLBytes))
-		p, err := indicator.PatchFromYAML(reader)
+		patch, err := indicator.PatchFromYAML(reader)
 		if err != nil {
 			log.Println(err)
 			continue
 		}
-		patches = append(patches, p)
+		patch.Origin = p.Filename
+		patches = append(patches, patch)
 	}
+	indicator.SortPatches(patches)
 	return patches
 }
 
-func processDocuments(documentsBytes [][]byte, patches []indicator.Patch) []v1.IndicatorDocument {
+func processDocuments(unparsedDocuments []unparsedDocument, patches []indicator.Patch, resolver indicator.ImportResolver) []v1.IndicatorDocument {
 	var documents []v1.IndicatorDocument
-	for _, documentBytes := range documentsBytes {
-		doc, errs := indicator.ProcessDocument(patches, documentBytes)
+	for _, d := range unparsedDocuments {
+		doc, errs := indicator.ProcessDocument(patches, d.YAMLBytes, indicator.ResolveImports(resolver, d.Filename))
 		if len(errs) > 0 {
+			for _, e := range errs {
+				log.Printf("failed to process %s: %s", d.Filename, e)
+			}
 			continue
 		}
 
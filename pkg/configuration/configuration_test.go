@@ -61,6 +61,32 @@ func TestReadGitConfigurationFile(t *testing.T) {
 	g.Expect(buffer.String()).To(ContainSubstring("Parsed 2 documents and 2 patches from git source"))
 }
 
+func TestGitImports(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	buffer := bytes.NewBuffer(nil)
+	log.SetOutput(buffer)
+
+	fakeRepository := go_test.CreateMemoryRepo(
+		"test_fixtures/library.yml",
+		"test_fixtures/indicators_with_import.yml",
+	)
+
+	fakeGetter := func(s configuration.Source) (*git.Repository, error) {
+		return fakeRepository, nil
+	}
+
+	_, documents := configuration.Read([]configuration.Source{{
+		Type:       "git",
+		Repository: "fake/fake/fake",
+		Glob:       "indicators*.yml",
+	}}, fakeGetter)
+
+	g.Expect(documents).To(HaveLen(1))
+	g.Expect(documents[0].Spec.Indicators).To(HaveLen(2))
+	g.Expect(documents[0].Spec.Indicators[1].Name).To(Equal("http_request_rate"))
+}
+
 func TestGlobMatching(t *testing.T) {
 	t.Run("simple glob", func(t *testing.T) {
 		g := NewGomegaWithT(t)
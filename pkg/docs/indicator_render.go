@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"html/template"
 	"log"
+	"sort"
 	"strings"
 	"unicode"
 
@@ -14,28 +15,50 @@ import (
 )
 
 var indicatorTmpl = template.Must(template.New("Indicator").Parse(`
+{{- if .Deprecated}}
+<p class="deprecated">{{.DeprecationBanner}}</p>
+{{- end}}
 <table>
     <tr>
         <th width="25%">Description</th>
         <td>{{.Description}}</td>
     </tr>
+    {{if .IsComposite}}
+    <tr>
+        <th>Composite</th>
+        <td>{{.CompositeRule}}</td>
+    </tr>
+    {{else}}
     <tr>
         <th>PromQL</th>
         <td>
 			<code>{{.PromQL}}</code>
 		</td>
     </tr>
+    {{end}}
     <tr>
 		{{if .Thresholds}}
         <th>Thresholds</th>
         <td>
-            {{range .Thresholds}} <em>{{.Level}}</em>: {{.Operator}} {{.Value}}<br/> {{end}}
+            {{range .Thresholds}} <em>{{.Level}}</em>: {{.Operator}} {{.Value}}{{.Recovery}}{{.Scope}}{{.AlertOverrides}}<br/> {{end}}
 			{{if ne .ThresholdNote ""}}
 				{{.ThresholdNote}}
 			{{- end}}
         </td>
 		{{- end}}
     </tr>
+	{{if .Alert.Owner}}
+    <tr>
+        <th>Owner</th>
+        <td>{{.Alert.Owner}}</td>
+    </tr>
+	{{- end}}
+	{{if .Alert.RunbookURL}}
+    <tr>
+        <th>Runbook</th>
+        <td><a href="{{.Alert.RunbookURL}}">{{.Alert.RunbookURL}}</a></td>
+    </tr>
+	{{- end}}
 	{{range $key, $value :=.OtherDocumentationFields}}
     	<tr>
     	    <th>{{$key}}</th>
@@ -69,6 +92,43 @@ func (p indicatorPresenter) PromQL() template.HTML {
 	return template.HTML(p.IndicatorSpec.PromQL)
 }
 
+// CompositeRule describes how the status of a composite indicator is derived from its indicators.
+func (p indicatorPresenter) CompositeRule() template.HTML {
+	var indicators []string
+	for _, name := range p.Composite.Indicators {
+		indicators = append(indicators, "<code>"+template.HTMLEscapeString(name)+"</code>")
+	}
+	names := strings.Join(indicators, ", ")
+	level := "<em>" + template.HTMLEscapeString(p.Composite.Level) + "</em>"
+
+	switch p.Composite.Rule {
+	case v1.AnyRule:
+		return template.HTML(fmt.Sprintf("%s when any of %s is %s or worse", level, names, level))
+	case v1.AllRule:
+		return template.HTML(fmt.Sprintf("%s when all of %s are %s or worse", level, names, level))
+	case v1.WorstRule:
+		return template.HTML(fmt.Sprintf("The worst status of %s", names))
+	case v1.BestRule:
+		return template.HTML(fmt.Sprintf("The best status of %s", names))
+	default:
+		return ""
+	}
+}
+
+// DeprecationBanner links to the replacement of deprecated indicators and explains why they are
+// deprecated.
+func (p indicatorPresenter) DeprecationBanner() template.HTML {
+	banner := "<strong>Deprecated.</strong>"
+	if p.ReplacedBy != "" {
+		name := template.HTMLEscapeString(p.ReplacedBy)
+		banner += fmt.Sprintf(` Use <a href="#%s"><code>%s</code></a> instead.`, name, name)
+	}
+	if p.DeprecationMessage != "" {
+		banner += " " + template.HTMLEscapeString(p.DeprecationMessage)
+	}
+	return template.HTML(banner)
+}
+
 func (p indicatorPresenter) Title() string {
 	t, found := p.Documentation["title"]
 	if !found {
@@ -159,9 +219,55 @@ func (t thresholdPresenter) Level() string {
 }
 
 func (t thresholdPresenter) Operator() string {
+	if t.threshold.IsRange() {
+		return v1.GetComparatorAbbrev(t.threshold.Operator)
+	}
 	return v1.GetComparatorSymbol(t.threshold.Operator)
 }
 
 func (t thresholdPresenter) Value() string {
+	if t.threshold.IsRange() && t.threshold.Lower != nil && t.threshold.Upper != nil {
+		return fmt.Sprintf("%v and %v", *t.threshold.Lower, *t.threshold.Upper)
+	}
 	return fmt.Sprintf("%v", t.threshold.Value)
 }
+
+func (t thresholdPresenter) Recovery() string {
+	if t.threshold.Recovery == nil {
+		return ""
+	}
+	return fmt.Sprintf(" (recovers at %v)", *t.threshold.Recovery)
+}
+
+// AlertOverrides shows the owner and runbook of thresholds that override the indicator's.
+func (t thresholdPresenter) AlertOverrides() template.HTML {
+	var overrides []string
+	if t.threshold.Alert.Owner != "" {
+		overrides = append(overrides, "owner: "+template.HTMLEscapeString(t.threshold.Alert.Owner))
+	}
+	if t.threshold.Alert.RunbookURL != "" {
+		overrides = append(overrides, fmt.Sprintf(`<a href="%s">runbook</a>`, template.HTMLEscapeString(t.threshold.Alert.RunbookURL)))
+	}
+	if len(overrides) == 0 {
+		return ""
+	}
+	return template.HTML(fmt.Sprintf(" (%s)", strings.Join(overrides, ", ")))
+}
+
+func (t thresholdPresenter) Scope() string {
+	if !t.threshold.IsScoped() {
+		return ""
+	}
+
+	var keys []string
+	for k := range t.threshold.Matchers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var matchers []string
+	for _, k := range keys {
+		matchers = append(matchers, fmt.Sprintf("%s=%q", k, t.threshold.Matchers[k]))
+	}
+	return fmt.Sprintf(" for {%s}", strings.Join(matchers, ","))
+}
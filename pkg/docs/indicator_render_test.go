@@ -125,4 +125,74 @@ func TestRenderIndicatorHTML(t *testing.T) {
 
 		g.Expect(html).ToNot(ContainSubstring("<th>Thresholds</th>"))
 	})
+	t.Run("it renders the rule of composite indicators instead of promql", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		indicator := v1.IndicatorSpec{
+			Name: "api_health",
+			Composite: &v1.Composite{
+				Rule:       v1.AnyRule,
+				Level:      "critical",
+				Indicators: []string{"api_latency", "api_errors"},
+			},
+		}
+
+		ind := docs.NewIndicatorPresenter(indicator)
+		html := string(ind.HTML())
+
+		g.Expect(html).ToNot(ContainSubstring("<th>PromQL</th>"))
+		g.Expect(html).To(ContainSubstring("<th>Composite</th>"))
+		g.Expect(html).To(ContainSubstring("<em>critical</em> when any of <code>api_latency</code>, <code>api_errors</code> is <em>critical</em> or worse"))
+	})
+	t.Run("it renders the owner and runbook of indicators and thresholds", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		indicator := v1.IndicatorSpec{
+			Name:   "test_indicator",
+			PromQL: `latency`,
+			Alert: v1.AlertMetadata{
+				Owner:      "Latency Team",
+				RunbookURL: "https://example.com/runbooks/latency",
+			},
+			Thresholds: []v1.Threshold{{
+				Level:    "critical",
+				Operator: v1.GreaterThan,
+				Value:    100,
+				Alert: v1.Alert{
+					AlertMetadata: v1.AlertMetadata{
+						RunbookURL: "https://example.com/runbooks/latency-critical",
+					},
+				},
+			}},
+		}
+
+		ind := docs.NewIndicatorPresenter(indicator)
+		html := string(ind.HTML())
+
+		g.Expect(html).To(ContainSubstring("<th>Owner</th>\n        <td>Latency Team</td>"))
+		g.Expect(html).To(ContainSubstring(`<td><a href="https://example.com/runbooks/latency">https://example.com/runbooks/latency</a></td>`))
+		g.Expect(html).To(ContainSubstring(`&gt; 100 (<a href="https://example.com/runbooks/latency-critical">runbook</a>)<br/>`))
+	})
+
+	t.Run("it renders a banner for deprecated indicators", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		indicator := v1.IndicatorSpec{
+			Name:               "test_indicator",
+			PromQL:             `latency`,
+			Deprecated:         true,
+			DeprecationMessage: "Latency is <measured> per request now.",
+			ReplacedBy:         "request_latency",
+		}
+
+		ind := docs.NewIndicatorPresenter(indicator)
+		html := string(ind.HTML())
+		g.Expect(html).To(HavePrefix(`
+<p class="deprecated"><strong>Deprecated.</strong> Use <a href="#request_latency"><code>request_latency</code></a> instead. Latency is &lt;measured&gt; per request now.</p>
+<table>`))
+
+		ind.Deprecated = false
+		html = string(ind.HTML())
+		g.Expect(html).ToNot(ContainSubstring("Deprecated"))
+	})
 }
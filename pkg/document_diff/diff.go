@@ -0,0 +1,318 @@
+package document_diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	v1 "github.com/pivotal/monitoring-indicator-protocol/pkg/k8s/apis/indicatordocument/v1"
+)
+
+type ChangeType string
+
+const (
+	Added   ChangeType = "added"
+	Removed ChangeType = "removed"
+	Changed ChangeType = "changed"
+	// Tightened and Loosened are threshold bound changes which make the threshold breach sooner or later.
+	Tightened ChangeType = "tightened"
+	Loosened  ChangeType = "loosened"
+)
+
+// Change is a single difference between two documents. Path identifies the changed field, with
+// indicators, SLOs, thresholds and layout sections keyed by their name rather than their position,
+// e.g. indicators[latency].thresholds[critical].value. Before and After are the JSON values of the
+// field, and are left out when the field was added or removed.
+type Change struct {
+	Type   ChangeType  `json:"type"`
+	Path   string      `json:"path"`
+	Before interface{} `json:"before,omitempty"`
+	After  interface{} `json:"after,omitempty"`
+}
+
+type ChangeSet struct {
+	From    v1.Product `json:"from"`
+	To      v1.Product `json:"to"`
+	Changes []Change   `json:"changes"`
+}
+
+func (cs ChangeSet) IsEmpty() bool {
+	return len(cs.Changes) == 0
+}
+
+// String describes the changes one per line, e.g.
+// `tightened indicators[latency].thresholds[critical].value: 100 -> 50`.
+func (cs ChangeSet) String() string {
+	var output strings.Builder
+	fmt.Fprintf(&output, "%s %s -> %s %s\n", cs.From.Name, cs.From.Version, cs.To.Name, cs.To.Version)
+	if cs.IsEmpty() {
+		output.WriteString("no changes\n")
+		return output.String()
+	}
+
+	for _, c := range cs.Changes {
+		fmt.Fprintf(&output, "%s %s", c.Type, c.Path)
+		before, beforeOk := describeValue(c.Before)
+		after, afterOk := describeValue(c.After)
+		switch {
+		case c.Type == Added && afterOk:
+			fmt.Fprintf(&output, ": %s", after)
+		case c.Type == Removed && beforeOk:
+			fmt.Fprintf(&output, ": %s", before)
+		case c.Type != Added && c.Type != Removed && beforeOk && afterOk:
+			fmt.Fprintf(&output, ": %s -> %s", before, after)
+		}
+		output.WriteString("\n")
+	}
+	return output.String()
+}
+
+// Values are only described if they fit on a line, i.e. aren't objects.
+func describeValue(v interface{}) (string, bool) {
+	if v == nil {
+		return "", false
+	}
+	if _, ok := v.(map[string]interface{}); ok {
+		return "", false
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", false
+	}
+	return string(b), true
+}
+
+// Diff compares two documents, usually two versions of the same product. Indicators, SLOs, thresholds
+// and layout sections are matched by name, so that reordering them is not a change.
+func Diff(from, to v1.IndicatorDocument) ChangeSet {
+	d := &differ{changes: []Change{}}
+
+	d.diffValues("metadata.labels", toGeneric(from.Labels), toGeneric(to.Labels))
+	d.diffValues("product", toGeneric(from.Spec.Product), toGeneric(to.Spec.Product))
+	d.diffIndicators(from.Spec.Indicators, to.Spec.Indicators)
+	d.diffGenericKeyed("slos", slosByName(from.Spec.SLOs), slosByName(to.Spec.SLOs))
+
+	fromLayout, toLayout := from.Spec.Layout, to.Spec.Layout
+	fromSections, toSections := sectionsByTitle(fromLayout.Sections), sectionsByTitle(toLayout.Sections)
+	fromLayout.Sections, toLayout.Sections = nil, nil
+	d.diffValues("layout", toGeneric(fromLayout), toGeneric(toLayout))
+	d.diffGenericKeyed("layout.sections", fromSections, toSections)
+
+	return ChangeSet{
+		From:    from.Spec.Product,
+		To:      to.Spec.Product,
+		Changes: d.changes,
+	}
+}
+
+type differ struct {
+	changes []Change
+}
+
+func (d *differ) add(c Change) {
+	d.changes = append(d.changes, c)
+}
+
+// A list of objects keyed by their name, in their order in the document.
+type keyedValues struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func (kv *keyedValues) put(key string, value interface{}) {
+	if kv.values == nil {
+		kv.values = make(map[string]interface{})
+	}
+	kv.keys = append(kv.keys, key)
+	kv.values[key] = value
+}
+
+// Keys of the new list come first in their new order, followed by the removed keys. Items with the same
+// key are compared by diffItem.
+func (d *differ) diffKeyed(path string, from, to keyedValues, diffItem func(path string, key string)) {
+	for _, key := range to.keys {
+		itemPath := fmt.Sprintf("%s[%s]", path, key)
+		if _, ok := from.values[key]; !ok {
+			d.add(Change{Type: Added, Path: itemPath, After: toGeneric(to.values[key])})
+			continue
+		}
+		diffItem(itemPath, key)
+	}
+	for _, key := range from.keys {
+		if _, ok := to.values[key]; !ok {
+			d.add(Change{Type: Removed, Path: fmt.Sprintf("%s[%s]", path, key), Before: toGeneric(from.values[key])})
+		}
+	}
+}
+
+func (d *differ) diffGenericKeyed(path string, from, to keyedValues) {
+	d.diffKeyed(path, from, to, func(itemPath string, key string) {
+		d.diffValues(itemPath, toGeneric(from.values[key]), toGeneric(to.values[key]))
+	})
+}
+
+func (d *differ) diffIndicators(from, to []v1.IndicatorSpec) {
+	fromIndicators, toIndicators := keyedValues{}, keyedValues{}
+	for _, i := range from {
+		fromIndicators.put(uniqueKey(fromIndicators, i.Name), i)
+	}
+	for _, i := range to {
+		toIndicators.put(uniqueKey(toIndicators, i.Name), i)
+	}
+
+	d.diffKeyed("indicators", fromIndicators, toIndicators, func(path string, name string) {
+		d.diffIndicator(path, fromIndicators.values[name].(v1.IndicatorSpec), toIndicators.values[name].(v1.IndicatorSpec))
+	})
+}
+
+func (d *differ) diffIndicator(path string, from, to v1.IndicatorSpec) {
+	fromThresholds, toThresholds := thresholdsByKey(from.Thresholds), thresholdsByKey(to.Thresholds)
+	from.Thresholds, to.Thresholds = nil, nil
+	d.diffValues(path, toGeneric(from), toGeneric(to))
+
+	d.diffKeyed(path+".thresholds", fromThresholds, toThresholds, func(thresholdPath string, key string) {
+		d.diffThreshold(thresholdPath, fromThresholds.values[key].(v1.Threshold), toThresholds.values[key].(v1.Threshold))
+	})
+}
+
+// Bound changes of thresholds with the same operator are tightened or loosened, depending on
+// whether the threshold now breaches sooner or later.
+func (d *differ) diffThreshold(path string, from, to v1.Threshold) {
+	start := len(d.changes)
+	d.diffValues(path, toGeneric(from), toGeneric(to))
+	if from.Operator != to.Operator {
+		return
+	}
+
+	for i := start; i < len(d.changes); i++ {
+		c := &d.changes[i]
+		before, beforeOk := c.Before.(float64)
+		after, afterOk := c.After.(float64)
+		if c.Type != Changed || !beforeOk || !afterOk {
+			continue
+		}
+
+		field := c.Path[strings.LastIndex(c.Path, ".")+1:]
+		if tighter, ok := isTighter(to.Operator, field, before, after); ok {
+			c.Type = Loosened
+			if tighter {
+				c.Type = Tightened
+			}
+		}
+	}
+}
+
+func isTighter(operator v1.ThresholdOperator, field string, before, after float64) (bool, bool) {
+	switch {
+	case field == "value" && (operator == v1.GreaterThan || operator == v1.GreaterThanOrEqualTo):
+		return after < before, true
+	case field == "value" && (operator == v1.LessThan || operator == v1.LessThanOrEqualTo):
+		return after > before, true
+	case field == "lower" && operator == v1.Between, field == "upper" && operator == v1.Outside:
+		return after < before, true
+	case field == "upper" && operator == v1.Between, field == "lower" && operator == v1.Outside:
+		return after > before, true
+	default:
+		return false, false
+	}
+}
+
+// Compares the JSON values of a field. Objects are compared field by field, anything else as a whole.
+func (d *differ) diffValues(path string, before, after interface{}) {
+	beforeMap, beforeIsMap := before.(map[string]interface{})
+	afterMap, afterIsMap := after.(map[string]interface{})
+	if !beforeIsMap || !afterIsMap {
+		if !reflect.DeepEqual(before, after) {
+			d.add(Change{Type: Changed, Path: path, Before: before, After: after})
+		}
+		return
+	}
+
+	var keys []string
+	for k := range beforeMap {
+		keys = append(keys, k)
+	}
+	for k := range afterMap {
+		if _, ok := beforeMap[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		fieldPath := path + "." + k
+		b, beforeOk := beforeMap[k]
+		a, afterOk := afterMap[k]
+		switch {
+		case !beforeOk:
+			d.add(Change{Type: Added, Path: fieldPath, After: a})
+		case !afterOk:
+			d.add(Change{Type: Removed, Path: fieldPath, Before: b})
+		default:
+			d.diffValues(fieldPath, b, a)
+		}
+	}
+}
+
+// Thresholds are keyed by their level, and their matchers if they are scoped, e.g.
+// critical{az="z1"}.
+func thresholdsByKey(thresholds []v1.Threshold) keyedValues {
+	kv := keyedValues{}
+	for _, t := range thresholds {
+		key := t.Level
+		if t.IsScoped() {
+			var matchers []string
+			for k, v := range t.Matchers {
+				matchers = append(matchers, fmt.Sprintf("%s=%q", k, v))
+			}
+			sort.Strings(matchers)
+			key = fmt.Sprintf("%s{%s}", key, strings.Join(matchers, ","))
+		}
+		kv.put(uniqueKey(kv, key), t)
+	}
+	return kv
+}
+
+func slosByName(slos []v1.ServiceLevelObjective) keyedValues {
+	kv := keyedValues{}
+	for _, slo := range slos {
+		kv.put(uniqueKey(kv, slo.Name), slo)
+	}
+	return kv
+}
+
+// Sections without a title are keyed by their position.
+func sectionsByTitle(sections []v1.Section) keyedValues {
+	kv := keyedValues{}
+	for i, s := range sections {
+		key := s.Title
+		if key == "" {
+			key = fmt.Sprintf("%d", i)
+		}
+		kv.put(uniqueKey(kv, key), s)
+	}
+	return kv
+}
+
+// Duplicate keys, which are invalid in most places, get their position appended.
+func uniqueKey(kv keyedValues, key string) string {
+	if _, ok := kv.values[key]; ok {
+		return fmt.Sprintf("%s#%d", key, len(kv.keys))
+	}
+	return key
+}
+
+func toGeneric(v interface{}) interface{} {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	var generic interface{}
+	err = json.Unmarshal(b, &generic)
+	if err != nil {
+		return nil
+	}
+	return generic
+}
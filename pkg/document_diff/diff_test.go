@@ -0,0 +1,211 @@
+package document_diff_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal/monitoring-indicator-protocol/pkg/document_diff"
+	v1 "github.com/pivotal/monitoring-indicator-protocol/pkg/k8s/apis/indicatordocument/v1"
+)
+
+func document(version string, indicators ...v1.IndicatorSpec) v1.IndicatorDocument {
+	return v1.IndicatorDocument{
+		Spec: v1.IndicatorDocumentSpec{
+			Product:    v1.Product{Name: "my-product", Version: version},
+			Indicators: indicators,
+		},
+	}
+}
+
+func float64Ptr(f float64) *float64 {
+	return &f
+}
+
+func TestDiff(t *testing.T) {
+	t.Run("returns no changes for equal documents", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		doc := document("1.0.0", v1.IndicatorSpec{Name: "latency", PromQL: "latency"})
+		changes := document_diff.Diff(doc, doc)
+
+		g.Expect(changes.IsEmpty()).To(BeTrue())
+		g.Expect(changes.String()).To(Equal("my-product 1.0.0 -> my-product 1.0.0\nno changes\n"))
+	})
+
+	t.Run("keys indicators by name", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		changes := document_diff.Diff(
+			document("1.0.0",
+				v1.IndicatorSpec{Name: "latency", PromQL: "latency"},
+				v1.IndicatorSpec{Name: "errors", PromQL: "errors"},
+				v1.IndicatorSpec{Name: "throughput", PromQL: "throughput"},
+			),
+			document("1.1.0",
+				v1.IndicatorSpec{Name: "errors", PromQL: "errors"},
+				v1.IndicatorSpec{Name: "latency", PromQL: "rate(latency[1m])"},
+				v1.IndicatorSpec{Name: "saturation", PromQL: "saturation"},
+			),
+		)
+
+		g.Expect(changes.Changes).To(HaveLen(4))
+		g.Expect(changes.Changes[0]).To(Equal(document_diff.Change{
+			Type:   document_diff.Changed,
+			Path:   "product.version",
+			Before: "1.0.0",
+			After:  "1.1.0",
+		}))
+		g.Expect(changes.Changes[1]).To(Equal(document_diff.Change{
+			Type:   document_diff.Changed,
+			Path:   "indicators[latency].promql",
+			Before: "latency",
+			After:  "rate(latency[1m])",
+		}))
+		g.Expect(changes.Changes[2].Type).To(Equal(document_diff.Added))
+		g.Expect(changes.Changes[2].Path).To(Equal("indicators[saturation]"))
+		g.Expect(changes.Changes[2].After).To(HaveKeyWithValue("promql", "saturation"))
+		g.Expect(changes.Changes[3].Type).To(Equal(document_diff.Removed))
+		g.Expect(changes.Changes[3].Path).To(Equal("indicators[throughput]"))
+	})
+
+	t.Run("classifies threshold bound changes", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		changes := document_diff.Diff(
+			document("1.0.0", v1.IndicatorSpec{
+				Name:   "latency",
+				PromQL: "latency",
+				Thresholds: []v1.Threshold{
+					{Level: "critical", Operator: v1.GreaterThan, Value: 100},
+					{Level: "warning", Operator: v1.LessThan, Value: 10},
+					{Level: "info", Operator: v1.Between, Lower: float64Ptr(1), Upper: float64Ptr(2)},
+					{Level: "critical", Operator: v1.GreaterThan, Value: 200, Matchers: map[string]string{"az": "z1"}},
+				},
+			}),
+			document("1.0.0", v1.IndicatorSpec{
+				Name:   "latency",
+				PromQL: "latency",
+				Thresholds: []v1.Threshold{
+					{Level: "critical", Operator: v1.GreaterThan, Value: 50},
+					{Level: "warning", Operator: v1.LessThan, Value: 5},
+					{Level: "info", Operator: v1.Outside, Lower: float64Ptr(1), Upper: float64Ptr(3)},
+					{Level: "critical", Operator: v1.GreaterThan, Value: 200, Matchers: map[string]string{"az": "z2"}},
+				},
+			}),
+		)
+
+		g.Expect(changes.String()).To(Equal(`my-product 1.0.0 -> my-product 1.0.0
+tightened indicators[latency].thresholds[critical].value: 100 -> 50
+loosened indicators[latency].thresholds[warning].value: 10 -> 5
+changed indicators[latency].thresholds[info].operator: "between" -> "outside"
+changed indicators[latency].thresholds[info].upper: 2 -> 3
+added indicators[latency].thresholds[critical{az="z2"}]
+removed indicators[latency].thresholds[critical{az="z1"}]
+`))
+	})
+
+	t.Run("reports changes of range bounds", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		threshold := func(operator v1.ThresholdOperator, lower, upper float64) v1.IndicatorSpec {
+			return v1.IndicatorSpec{Name: "latency", PromQL: "latency", Thresholds: []v1.Threshold{
+				{Level: "warning", Operator: operator, Lower: float64Ptr(lower), Upper: float64Ptr(upper)},
+			}}
+		}
+
+		changes := document_diff.Diff(document("1", threshold(v1.Between, 10, 20)), document("1", threshold(v1.Between, 5, 15)))
+		g.Expect(changes.Changes[0].Type).To(Equal(document_diff.Tightened))
+		g.Expect(changes.Changes[1].Type).To(Equal(document_diff.Loosened))
+
+		changes = document_diff.Diff(document("1", threshold(v1.Outside, 10, 20)), document("1", threshold(v1.Outside, 5, 15)))
+		g.Expect(changes.Changes[0].Type).To(Equal(document_diff.Loosened))
+		g.Expect(changes.Changes[1].Type).To(Equal(document_diff.Tightened))
+	})
+
+	t.Run("reports nested indicator changes field by field", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		changes := document_diff.Diff(
+			document("1.0.0", v1.IndicatorSpec{
+				Name:          "latency",
+				PromQL:        "latency",
+				Documentation: map[string]string{"title": "Latency", "description": "How slow"},
+				Presentation:  v1.Presentation{ChartType: v1.StepChart, Units: "ms"},
+			}),
+			document("1.0.0", v1.IndicatorSpec{
+				Name:          "latency",
+				PromQL:        "latency",
+				Documentation: map[string]string{"title": "Request Latency"},
+				Presentation:  v1.Presentation{ChartType: v1.StepChart, Units: "s", Labels: []string{"job"}},
+			}),
+		)
+
+		g.Expect(changes.String()).To(Equal(`my-product 1.0.0 -> my-product 1.0.0
+removed indicators[latency].documentation.description: "How slow"
+changed indicators[latency].documentation.title: "Latency" -> "Request Latency"
+added indicators[latency].presentation.labels: ["job"]
+changed indicators[latency].presentation.units: "ms" -> "s"
+`))
+	})
+
+	t.Run("keys layout sections by title", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		from := document("1.0.0")
+		from.Spec.Layout = v1.Layout{
+			Title: "Old",
+			Sections: []v1.Section{
+				{Title: "Main", Indicators: []string{"a", "b"}},
+				{Title: "Other", Indicators: []string{"c"}},
+			},
+		}
+		to := document("1.0.0")
+		to.Spec.Layout = v1.Layout{
+			Title: "New",
+			Sections: []v1.Section{
+				{Title: "Other", Indicators: []string{"c"}},
+				{Title: "Main", Indicators: []string{"a", "d"}},
+			},
+		}
+
+		g.Expect(document_diff.Diff(from, to).String()).To(Equal(`my-product 1.0.0 -> my-product 1.0.0
+changed layout.title: "Old" -> "New"
+changed layout.sections[Main].indicators: ["a","b"] -> ["a","d"]
+`))
+	})
+
+	t.Run("keys SLOs by name", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		from := document("1.0.0")
+		from.Spec.SLOs = []v1.ServiceLevelObjective{{Name: "availability", Objective: 99.9}}
+		to := document("1.0.0")
+		to.Spec.SLOs = []v1.ServiceLevelObjective{{Name: "availability", Objective: 99.5}}
+
+		g.Expect(document_diff.Diff(from, to).Changes).To(ConsistOf(document_diff.Change{
+			Type:   document_diff.Changed,
+			Path:   "slos[availability].objective",
+			Before: 99.9,
+			After:  99.5,
+		}))
+	})
+
+	t.Run("marshals to JSON", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		changes := document_diff.Diff(
+			document("1.0.0", v1.IndicatorSpec{Name: "latency", PromQL: "latency"}),
+			document("1.0.0", v1.IndicatorSpec{Name: "latency", PromQL: "latency", Type: v1.KeyPerformanceIndicator}),
+		)
+
+		jsonBytes, err := json.Marshal(changes)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(jsonBytes).To(MatchJSON(`{
+			"from": {"name": "my-product", "version": "1.0.0"},
+			"to": {"name": "my-product", "version": "1.0.0"},
+			"changes": [{"type": "changed", "path": "indicators[latency].type", "before": "other", "after": "kpi"}]
+		}`))
+	})
+}
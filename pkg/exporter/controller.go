@@ -3,6 +3,7 @@ package exporter
 import (
 	"errors"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"time"
@@ -18,6 +19,12 @@ type APIClient interface {
 	IndicatorDocuments() ([]registry.APIDocumentResponse, error)
 }
 
+// WatchingAPIClient can stream the changes to the registry's documents.
+type WatchingAPIClient interface {
+	APIClient
+	Watch(resourceVersion string) (registry.WatchStream, error)
+}
+
 type ControllerConfig struct {
 	RegistryAPIClient APIClient
 	Filesystem        billy.Filesystem
@@ -26,6 +33,10 @@ type ControllerConfig struct {
 	DocType           string
 	Converter         DocumentConverter
 	Reloader          Reloader
+	// Watch updates whenever the documents change instead of every UpdateFrequency, if the
+	// RegistryAPIClient is a WatchingAPIClient. UpdateFrequency is then the delay before watching
+	// again after a failure.
+	Watch bool
 }
 
 type DocumentConverter func(v1.IndicatorDocument) (*File, error)
@@ -49,14 +60,22 @@ func NewController(c ControllerConfig) *Controller {
 
 	return &Controller{
 		Config: c,
+		files:  make(map[string]string),
 	}
 }
 
 type Controller struct {
 	Config ControllerConfig
+	// The name of the file written for each document, by document UID.
+	files map[string]string
 }
 
 func (c *Controller) Start() {
+	if client, ok := c.Config.RegistryAPIClient.(WatchingAPIClient); ok && c.Config.Watch {
+		c.watch(client)
+		return
+	}
+
 	err := c.Update()
 	if err != nil {
 		log.Printf("failed to update %s: %s", c.Config.DocType, err)
@@ -74,6 +93,91 @@ func (c *Controller) Start() {
 	}
 }
 
+// watch applies each change to the documents as it happens. Watches resume from the last event seen,
+// and the documents are only listed again when that is not possible.
+func (c *Controller) watch(client WatchingAPIClient) {
+	resourceVersion := ""
+	for {
+		stream, err := client.Watch(resourceVersion)
+		if err == registry.ErrResourceVersionGone {
+			resourceVersion = ""
+			continue
+		}
+		if err != nil {
+			log.Printf("failed to watch %s: %s", c.Config.DocType, err)
+			time.Sleep(c.Config.UpdateFrequency)
+			continue
+		}
+
+		// The watch starts from now on, so listing the documents afterwards misses no change.
+		if resourceVersion == "" {
+			c.update()
+		}
+
+		for {
+			event, err := stream.Next()
+			if err != nil {
+				if err != io.EOF {
+					log.Printf("failed to read watch of %s: %s", c.Config.DocType, err)
+				}
+				break
+			}
+			err = c.apply(event)
+			if err != nil {
+				log.Printf("failed to apply %s event to %s: %s", event.Type, c.Config.DocType, err)
+			}
+		}
+
+		resourceVersion = stream.ResourceVersion()
+		_ = stream.Close()
+	}
+}
+
+func (c *Controller) update() {
+	err := c.Update()
+	if err != nil {
+		log.Printf("failed to update %s: %s", c.Config.DocType, err)
+	}
+}
+
+// apply writes the document of an added or updated event, and removes the file of an expired or
+// deleted one.
+func (c *Controller) apply(event registry.WatchEvent) error {
+	switch event.Type {
+	case registry.WatchAdded, registry.WatchUpdated:
+		if event.Document == nil {
+			return fmt.Errorf("event for document %s has no document", event.DocumentUID)
+		}
+		c.removeFile(event.DocumentUID)
+		name, err := writeDocument(*event.Document, c.Config)
+		if err != nil {
+			return err
+		}
+		if name != "" {
+			c.files[event.DocumentUID] = name
+		}
+	case registry.WatchExpired, registry.WatchDeleted:
+		c.removeFile(event.DocumentUID)
+	default:
+		return nil
+	}
+
+	return c.Config.Reloader()
+}
+
+func (c *Controller) removeFile(documentUID string) {
+	name, ok := c.files[documentUID]
+	if !ok {
+		return
+	}
+	delete(c.files, documentUID)
+
+	err := c.Config.Filesystem.Remove(fmt.Sprintf("%s/%s", c.Config.OutputDirectory, name))
+	if err != nil {
+		log.Print("failed to delete a document")
+	}
+}
+
 func (c *Controller) Update() error {
 	outputDir := c.Config.OutputDirectory
 	fs := c.Config.Filesystem
@@ -92,7 +196,7 @@ func (c *Controller) Update() error {
 	if err != nil {
 		return fmt.Errorf("failed to delete contents of directory: %s", err)
 	}
-	writeDocuments(apiDocuments, c.Config)
+	c.files = writeDocuments(apiDocuments, c.Config)
 
 	return c.Config.Reloader()
 }
@@ -115,29 +219,45 @@ func clearDirectory(fs billy.Filesystem, d string) error {
 	return nil
 }
 
-func writeDocuments(documents []registry.APIDocumentResponse, config ControllerConfig) {
+// writeDocuments returns the names of the files written, by document UID.
+func writeDocuments(documents []registry.APIDocumentResponse, config ControllerConfig) map[string]string {
 	log.Printf("writing %d indicator documents to output directory", len(documents))
 
+	files := make(map[string]string, len(documents))
 	for _, d := range documents {
-		file, err := config.Converter(registry.ToIndicatorDocument(d))
+		name, err := writeDocument(d, config)
 		if err != nil {
-			log.Print("error converting document")
-			return
+			log.Print(err)
+			return files
 		}
-		if file == nil {
+		if name == "" {
 			log.Print("document contains no indicators to convert (perhaps you set an indicator type filter?)")
-			return
+			return files
 		}
+		files[registry.ToIndicatorDocument(d).BoshUID()] = name
+	}
+	return files
+}
 
-		f, err := config.Filesystem.Create(fmt.Sprintf("%s/%s", config.OutputDirectory, file.Name))
-		if err != nil {
-			log.Print("error creating file")
-			return
-		}
+// writeDocument returns the name of the file written, which is empty if the document has nothing to
+// convert.
+func writeDocument(d registry.APIDocumentResponse, config ControllerConfig) (string, error) {
+	file, err := config.Converter(registry.ToIndicatorDocument(d))
+	if err != nil {
+		return "", errors.New("error converting document")
+	}
+	if file == nil {
+		return "", nil
+	}
 
-		_, err = f.Write(file.Contents)
-		if err != nil {
-			log.Print("error writing file")
-		}
+	f, err := config.Filesystem.Create(fmt.Sprintf("%s/%s", config.OutputDirectory, file.Name))
+	if err != nil {
+		return "", errors.New("error creating file")
+	}
+
+	_, err = f.Write(file.Contents)
+	if err != nil {
+		log.Print("error writing file")
 	}
+	return file.Name, nil
 }
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"io"
 	"log"
 	"sync"
 	"testing"
@@ -232,6 +233,75 @@ var stubConverter = func(document v1.IndicatorDocument) (*exporter.File, error)
 	return &exporter.File{Name: fmt.Sprintf("%s.yml", document.Spec.Product.Name), Contents: []byte("")}, nil
 }
 
+func TestWatching(t *testing.T) {
+	buffer := bytes.NewBuffer(nil)
+	log.SetOutput(buffer)
+
+	t.Run("Start applies the changes to the documents", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		documents := createTestDocuments(2, api_versions.V1)
+		registryClient := &mockWatchingClient{
+			mockRegistryClient: &mockRegistryClient{
+				Documents: documents[:1],
+			},
+			responses: make(chan watchResponse),
+		}
+
+		fs := memfs.New()
+		mockReloader := &mockReloader{}
+		c := exporter.ControllerConfig{
+			RegistryAPIClient: registryClient,
+			Filesystem:        fs,
+			OutputDirectory:   "/",
+			UpdateFrequency:   time.Hour,
+			Watch:             true,
+			Converter:         stubConverter,
+			Reloader:          mockReloader.Reload,
+		}
+		fileNames := func() []string {
+			names, err := go_test.GetFileNames(fs, "/")
+			g.Expect(err).ToNot(HaveOccurred())
+			return names
+		}
+
+		controller := exporter.NewController(c)
+		go controller.Start()
+
+		firstStream := newMockStream("100")
+		registryClient.responses <- watchResponse{stream: firstStream}
+		g.Eventually(registryClient.calls).Should(Equal(1))
+		g.Eventually(fileNames).Should(ConsistOf("test_product_0.yml"))
+
+		firstStream.events <- registry.WatchEvent{
+			Type:            registry.WatchAdded,
+			ResourceVersion: "101",
+			DocumentUID:     registry.ToIndicatorDocument(documents[1]).BoshUID(),
+			Document:        &documents[1],
+		}
+		g.Eventually(fileNames).Should(ConsistOf("test_product_0.yml", "test_product_1.yml"))
+
+		firstStream.events <- registry.WatchEvent{Type: registry.WatchStatusChanged, ResourceVersion: "102"}
+		firstStream.events <- registry.WatchEvent{
+			Type:            registry.WatchDeleted,
+			ResourceVersion: "103",
+			DocumentUID:     registry.ToIndicatorDocument(documents[0]).BoshUID(),
+		}
+		g.Eventually(fileNames).Should(ConsistOf("test_product_1.yml"))
+		g.Expect(mockReloader.calls()).To(Equal(3))
+		g.Expect(registryClient.calls()).To(Equal(1))
+		close(firstStream.events)
+
+		registryClient.responses <- watchResponse{err: registry.ErrResourceVersionGone}
+		registryClient.responses <- watchResponse{stream: newMockStream("200")}
+		g.Eventually(registryClient.calls).Should(Equal(2))
+		g.Consistently(registryClient.calls, 50*time.Millisecond).Should(Equal(2))
+		g.Eventually(fileNames).Should(ConsistOf("test_product_0.yml"))
+
+		g.Expect(registryClient.watchedVersions()).To(Equal([]string{"", "103", ""}))
+	})
+}
+
 func TestReloading(t *testing.T) {
 	t.Run("reloads after updating", func(t *testing.T) {
 		g := NewGomegaWithT(t)
@@ -395,3 +465,60 @@ func (a *mockReloader) calls() int {
 	defer a.mu.Unlock()
 	return a.calls_
 }
+
+type watchResponse struct {
+	stream registry.WatchStream
+	err    error
+}
+
+type mockWatchingClient struct {
+	*mockRegistryClient
+	responses chan watchResponse
+
+	mu       sync.Mutex
+	versions []string
+}
+
+func (a *mockWatchingClient) Watch(resourceVersion string) (registry.WatchStream, error) {
+	a.mu.Lock()
+	a.versions = append(a.versions, resourceVersion)
+	a.mu.Unlock()
+
+	response := <-a.responses
+	return response.stream, response.err
+}
+
+func (a *mockWatchingClient) watchedVersions() []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]string(nil), a.versions...)
+}
+
+type mockStream struct {
+	events          chan registry.WatchEvent
+	resourceVersion string
+}
+
+func newMockStream(resourceVersion string) *mockStream {
+	return &mockStream{
+		events:          make(chan registry.WatchEvent),
+		resourceVersion: resourceVersion,
+	}
+}
+
+func (s *mockStream) Next() (registry.WatchEvent, error) {
+	event, ok := <-s.events
+	if !ok {
+		return registry.WatchEvent{}, io.EOF
+	}
+	s.resourceVersion = event.ResourceVersion
+	return event, nil
+}
+
+func (s *mockStream) ResourceVersion() string {
+	return s.resourceVersion
+}
+
+func (s *mockStream) Close() error {
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	"log"
 	"regexp"
 	"sort"
+	"strings"
 
 	"github.com/grafana-tools/sdk"
 
@@ -81,9 +82,17 @@ func documentSectionsToPanelRows(document v1.IndicatorDocument, board *sdk.Board
 					continue
 				}
 
-				if indicatorSpec.Name == indicatorName {
-					toAdd = append(toAdd, ToGrafanaPanel(indicatorSpec))
+				if indicatorSpec.Name != indicatorName {
+					continue
+				}
+				var panel *sdk.Panel
+				if indicatorSpec.IsComposite() {
+					panel = ToGrafanaCompositePanel(indicatorSpec, document)
+				} else {
+					panel = ToGrafanaPanel(indicatorSpec)
 				}
+				markDeprecated(panel, indicatorSpec)
+				toAdd = append(toAdd, panel)
 			}
 		}
 
@@ -94,6 +103,23 @@ func documentSectionsToPanelRows(document v1.IndicatorDocument, board *sdk.Board
 	}
 }
 
+// The titles of the panels of deprecated indicators are prefixed with [DEPRECATED], and graph panels
+// explain what replaces the indicator in their description.
+func markDeprecated(panel *sdk.Panel, spec v1.IndicatorSpec) {
+	if !spec.Deprecated {
+		return
+	}
+
+	panel.Title = "[DEPRECATED] " + panel.Title
+	if panel.GraphPanel != nil {
+		description := fmt.Sprintf("## deprecated\n%s\n\n", spec.DeprecationNotice())
+		if panel.GraphPanel.Description != nil {
+			description += *panel.GraphPanel.Description
+		}
+		panel.GraphPanel.Description = &description
+	}
+}
+
 func appendPanelRowTitle(board *sdk.Board, section v1.Section) {
 	board.Panels = append(board.Panels, &sdk.Panel{
 		CommonPanel: sdk.CommonPanel{
@@ -116,6 +142,9 @@ func ToGrafanaPanel(spec v1.IndicatorSpec) *sdk.Panel {
 	panel.AddTarget(&sdk.Target{
 		Expr: replaceStep(spec.PromQL),
 	})
+	for _, target := range toGrafanaScopedThresholdTargets(spec.Thresholds) {
+		panel.AddTarget(target)
+	}
 
 	panel.GridPos = struct {
 		H *int `json:"h,omitempty"`
@@ -159,6 +188,147 @@ func ToGrafanaPanel(spec v1.IndicatorSpec) *sdk.Panel {
 	return panel
 }
 
+// ToGrafanaCompositePanel renders a composite indicator as a status panel. Grafana can't read the
+// statuses evaluated by the registry, so the panel queries the severity of the composite instead,
+// computed from the thresholds of its indicators: 0 is HEALTHY, up to 3 for critical, see
+// v1.StatusSeverity.
+func ToGrafanaCompositePanel(spec v1.IndicatorSpec, document v1.IndicatorDocument) *sdk.Panel {
+	title, ok := spec.Documentation["title"]
+	if !ok {
+		title = spec.Name
+	}
+	panel := sdk.NewSinglestat(title)
+
+	var members []v1.IndicatorSpec
+	for _, name := range spec.Composite.Indicators {
+		if member := document.Indicator(name); member != nil && !member.IsComposite() {
+			members = append(members, *member)
+		}
+	}
+	if expr := compositeSeverityExpr(*spec.Composite, members); expr != "" {
+		panel.AddTarget(&sdk.Target{
+			Expr:    expr,
+			Instant: true,
+		})
+	}
+
+	panel.GridPos = struct {
+		H *int `json:"h,omitempty"`
+		W *int `json:"w,omitempty"`
+		X *int `json:"x,omitempty"`
+		Y *int `json:"y,omitempty"`
+	}{
+		H: &HEIGHT,
+		W: &WIDTH,
+	}
+
+	panel.SinglestatPanel.ValueName = "current"
+	panel.SinglestatPanel.NullPointMode = "connected"
+	panel.SinglestatPanel.ValueFontSize = "80%"
+	panel.SinglestatPanel.ColorBackground = true
+	panel.SinglestatPanel.Thresholds = "1,3"
+	panel.SinglestatPanel.Colors = []string{"#299c46", "rgba(237, 129, 40, 0.89)", "#d44a3a"}
+	panel.SinglestatPanel.ValueMaps = compositeValueMaps(members)
+
+	return panel
+}
+
+// Every indicator gets a series with its severity, the highest severity of the thresholds it
+// breaches. Scoped thresholds and recovery values are not taken into account. Indicators without
+// data or thresholds have no series, like UNKNOWN and UNDEFINED indicators are left out of the
+// status evaluated by the registry.
+func compositeSeverityExpr(composite v1.Composite, members []v1.IndicatorSpec) string {
+	var terms []string
+	for _, member := range members {
+		for i, t := range member.Thresholds {
+			if t.IsScoped() {
+				continue
+			}
+			breached := thresholdBreachedExpr(replaceStep(member.PromQL), t)
+			if breached == "" {
+				continue
+			}
+			terms = append(terms, fmt.Sprintf(
+				`label_replace(label_replace(max(%s) * %d, "indicator", %q, "", ""), "threshold", "%d", "", "")`,
+				breached, v1.StatusSeverity(t.Level), member.Name, i,
+			))
+		}
+	}
+	if len(terms) == 0 {
+		return ""
+	}
+
+	severities := fmt.Sprintf("max by (indicator) (%s)", strings.Join(terms, " or "))
+	level := v1.StatusSeverity(composite.Level)
+	switch composite.Rule {
+	case v1.AnyRule:
+		return fmt.Sprintf("(max(%s) >= bool %d) * %d", severities, level, level)
+	case v1.AllRule:
+		return fmt.Sprintf("(min(%s) >= bool %d) * %d", severities, level, level)
+	case v1.WorstRule:
+		return fmt.Sprintf("max(%s)", severities)
+	case v1.BestRule:
+		return fmt.Sprintf("min(%s)", severities)
+	default:
+		return ""
+	}
+}
+
+func thresholdBreachedExpr(promql string, t v1.Threshold) string {
+	switch t.Operator {
+	case v1.Between:
+		if t.Lower == nil || t.Upper == nil {
+			return ""
+		}
+		return fmt.Sprintf("(%s >= bool %v) * (%s <= bool %v)", promql, *t.Lower, promql, *t.Upper)
+	case v1.Outside:
+		if t.Lower == nil || t.Upper == nil {
+			return ""
+		}
+		return fmt.Sprintf("(%s < bool %v) + (%s > bool %v)", promql, *t.Lower, promql, *t.Upper)
+	}
+
+	operators := map[v1.ThresholdOperator]string{
+		v1.LessThan:             "<",
+		v1.LessThanOrEqualTo:    "<=",
+		v1.EqualTo:              "==",
+		v1.NotEqualTo:           "!=",
+		v1.GreaterThanOrEqualTo: ">=",
+		v1.GreaterThan:          ">",
+	}
+	operator, ok := operators[t.Operator]
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("%s %s bool %v", promql, operator, t.Value)
+}
+
+// Levels other than warning and critical share a severity, so they share a value map.
+func compositeValueMaps(members []v1.IndicatorSpec) []sdk.ValueMap {
+	otherLevels := make(map[string]bool)
+	for _, member := range members {
+		for _, t := range member.Thresholds {
+			if v1.StatusSeverity(t.Level) == 1 {
+				otherLevels[t.Level] = true
+			}
+		}
+	}
+
+	valueMaps := []sdk.ValueMap{{Op: "=", Value: "0", TextType: v1.HealthyStatus}}
+	if len(otherLevels) > 0 {
+		var levels []string
+		for level := range otherLevels {
+			levels = append(levels, level)
+		}
+		sort.Strings(levels)
+		valueMaps = append(valueMaps, sdk.ValueMap{Op: "=", Value: "1", TextType: strings.Join(levels, " / ")})
+	}
+	return append(valueMaps,
+		sdk.ValueMap{Op: "=", Value: "2", TextType: "warning"},
+		sdk.ValueMap{Op: "=", Value: "3", TextType: "critical"},
+	)
+}
+
 func ToGrafanaDescription(docs map[string]string) *string {
 	var description string
 
@@ -182,6 +352,15 @@ func ToGrafanaDescription(docs map[string]string) *string {
 func ToGrafanaThresholds(thresholds []v1.Threshold) []sdk.Threshold {
 	var grafanaThresholds []sdk.Threshold
 	for _, t := range thresholds {
+		if t.IsScoped() {
+			continue
+		}
+
+		if t.IsRange() {
+			grafanaThresholds = append(grafanaThresholds, toGrafanaRangeThresholds(t)...)
+			continue
+		}
+
 		var comparator string
 		switch {
 		case t.Operator == v1.LessThanOrEqualTo || t.Operator == v1.LessThan:
@@ -206,6 +385,83 @@ func ToGrafanaThresholds(thresholds []v1.Threshold) []sdk.Threshold {
 	return grafanaThresholds
 }
 
+// Grafana only supports lt/gt thresholds, so a range is drawn as a pair of them. For outside
+// their filled regions cover both sides of the range. Filling the pair for between would shade the
+// whole panel, since each of them covers everything on one side, so between only draws its bounds.
+func toGrafanaRangeThresholds(t v1.Threshold) []sdk.Threshold {
+	if t.Lower == nil || t.Upper == nil {
+		log.Printf("range threshold is missing a bound, threshold skipped: %v\n", t)
+		return nil
+	}
+
+	lowerOp, upperOp := "gt", "lt"
+	fill := false
+	if t.Operator == v1.Outside {
+		lowerOp, upperOp = "lt", "gt"
+		fill = true
+	}
+
+	return []sdk.Threshold{{
+		Value:     float32(*t.Lower),
+		ColorMode: t.Level,
+		Op:        lowerOp,
+		Fill:      fill,
+		Line:      true,
+		Yaxis:     "left",
+	}, {
+		Value:     float32(*t.Upper),
+		ColorMode: t.Level,
+		Op:        upperOp,
+		Fill:      fill,
+		Line:      true,
+		Yaxis:     "left",
+	}}
+}
+
+// Grafana thresholds apply to every series of a panel, so thresholds scoped by label matchers
+// are drawn as reference lines labelled with their scope instead.
+func toGrafanaScopedThresholdTargets(thresholds []v1.Threshold) []*sdk.Target {
+	var targets []*sdk.Target
+	for _, t := range thresholds {
+		if !t.IsScoped() {
+			continue
+		}
+
+		values := []float64{t.Value}
+		if t.IsRange() {
+			if t.Lower == nil || t.Upper == nil {
+				log.Printf("range threshold is missing a bound, threshold skipped: %v\n", t)
+				continue
+			}
+			values = []float64{*t.Lower, *t.Upper}
+		}
+
+		for _, value := range values {
+			targets = append(targets, &sdk.Target{
+				Expr:         fmt.Sprintf("vector(%v)", value),
+				LegendFormat: fmt.Sprintf("%s %s", t.Level, scopeLegend(t.Matchers)),
+			})
+		}
+	}
+
+	return targets
+}
+
+func scopeLegend(matchers map[string]string) string {
+	keys := make([]string, 0, len(matchers))
+	for k := range matchers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, fmt.Sprintf("%s=%q", k, matchers[k]))
+	}
+
+	return fmt.Sprintf("{%s}", strings.Join(pairs, ","))
+}
+
 func replaceStep(str string) string {
 	reg := regexp.MustCompile(`(?i)\$step\b`)
 	return reg.ReplaceAllString(str, `$$__interval`)
@@ -8,6 +8,7 @@ import (
 
 	"github.com/grafana-tools/sdk"
 	. "github.com/onsi/gomega"
+	"github.com/prometheus/prometheus/promql"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/pivotal/monitoring-indicator-protocol/pkg/grafana_dashboard"
@@ -263,6 +264,156 @@ Doc Performance Indicator
 		}}))
 	})
 
+	t.Run("turns range thresholds into a pair of grafana thresholds", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		lower, upper := 5.0, 10.0
+		thresholds := []v1.Threshold{
+			{
+				Level:    "warning",
+				Operator: v1.Between,
+				Lower:    &lower,
+				Upper:    &upper,
+			},
+			{
+				Level:    "critical",
+				Operator: v1.Outside,
+				Lower:    &lower,
+				Upper:    &upper,
+			},
+		}
+
+		g.Expect(grafana_dashboard.ToGrafanaThresholds(thresholds)).To(Equal([]sdk.Threshold{
+			{Value: 5, Op: "gt", Line: true, Fill: false, ColorMode: "warning", Yaxis: "left"},
+			{Value: 10, Op: "lt", Line: true, Fill: false, ColorMode: "warning", Yaxis: "left"},
+			{Value: 5, Op: "lt", Line: true, Fill: true, ColorMode: "critical", Yaxis: "left"},
+			{Value: 10, Op: "gt", Line: true, Fill: true, ColorMode: "critical", Yaxis: "left"},
+		}))
+	})
+
+	t.Run("draws label-scoped thresholds as reference lines", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		indicator := v1.IndicatorSpec{
+			Name:   "test_indicator",
+			PromQL: `latency`,
+			Thresholds: []v1.Threshold{{
+				Level:    "critical",
+				Operator: v1.GreaterThan,
+				Value:    10,
+			}, {
+				Level:    "critical",
+				Operator: v1.GreaterThan,
+				Value:    5,
+				Matchers: map[string]string{"az": "z1"},
+			}},
+		}
+
+		panel := grafana_dashboard.ToGrafanaPanel(indicator)
+
+		g.Expect(panel.GraphPanel.Thresholds).To(Equal([]sdk.Threshold{{
+			Value:     10,
+			Op:        "gt",
+			Line:      true,
+			Fill:      true,
+			ColorMode: "critical",
+			Yaxis:     "left",
+		}}))
+		g.Expect(panel.GraphPanel.Targets).To(Equal([]sdk.Target{{
+			Expr: `latency`,
+		}, {
+			Expr:         `vector(5)`,
+			LegendFormat: `critical {az="z1"}`,
+		}}))
+	})
+
+	t.Run("turns composite indicators into status panels", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		lower, upper := 1.0, 5.0
+
+		document := v1.IndicatorDocument{
+			Spec: v1.IndicatorDocumentSpec{
+				Indicators: []v1.IndicatorSpec{{
+					Name:   "latency",
+					PromQL: `latency`,
+					Thresholds: []v1.Threshold{{
+						Level:    "critical",
+						Operator: v1.GreaterThan,
+						Value:    10,
+					}},
+				}, {
+					Name:   "errors",
+					PromQL: `errors`,
+					Thresholds: []v1.Threshold{{
+						Level:    "warning",
+						Operator: v1.Outside,
+						Lower:    &lower,
+						Upper:    &upper,
+					}},
+				}, {
+					Name: "health",
+					Composite: &v1.Composite{
+						Rule:       v1.WorstRule,
+						Indicators: []string{"latency", "errors"},
+					},
+				}},
+			},
+		}
+
+		panel := grafana_dashboard.ToGrafanaCompositePanel(document.Spec.Indicators[2], document)
+
+		g.Expect(panel.Type).To(Equal("singlestat"))
+		g.Expect(panel.SinglestatPanel.Targets).To(HaveLen(1))
+		expr := panel.SinglestatPanel.Targets[0].Expr
+		g.Expect(expr).To(Equal(`max(max by (indicator) (` +
+			`label_replace(label_replace(max(latency > bool 10) * 3, "indicator", "latency", "", ""), "threshold", "0", "", "") or ` +
+			`label_replace(label_replace(max((errors < bool 1) + (errors > bool 5)) * 2, "indicator", "errors", "", ""), "threshold", "0", "", "")))`,
+		))
+		_, err := promql.ParseExpr(expr)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(panel.SinglestatPanel.ValueMaps).To(Equal([]sdk.ValueMap{
+			{Op: "=", Value: "0", TextType: "HEALTHY"},
+			{Op: "=", Value: "2", TextType: "warning"},
+			{Op: "=", Value: "3", TextType: "critical"},
+		}))
+	})
+
+	t.Run("marks the panels of deprecated indicators", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		document := v1.IndicatorDocument{
+			Spec: v1.IndicatorDocumentSpec{
+				Indicators: []v1.IndicatorSpec{{
+					Name:               "latency",
+					PromQL:             `latency`,
+					Documentation:      map[string]string{"title": "Latency"},
+					Deprecated:         true,
+					DeprecationMessage: "Latency is measured per request now.",
+					ReplacedBy:         "request_latency",
+				}, {
+					Name:   "request_latency",
+					PromQL: `request_latency`,
+				}},
+				Layout: v1.Layout{
+					Sections: []v1.Section{{
+						Title:      "Latency",
+						Indicators: []string{"latency", "request_latency"},
+					}},
+				},
+			},
+		}
+
+		dashboard, err := grafana_dashboard.ToGrafanaDashboard(document, v1.UndefinedType)
+		g.Expect(err).ToNot(HaveOccurred())
+
+		g.Expect(dashboard.Panels[1].Title).To(Equal("[DEPRECATED] Latency"))
+		g.Expect(*dashboard.Panels[1].GraphPanel.Description).To(Equal(
+			"## deprecated\nUse request_latency instead. Latency is measured per request now.\n\n## title\nLatency\n\n",
+		))
+		g.Expect(dashboard.Panels[2].Title).To(Equal("request_latency"))
+		g.Expect(dashboard.Panels[2].GraphPanel.Description).To(BeNil())
+	})
+
 	t.Run("uses the layout information to generate rows", func(t *testing.T) {
 		g := NewGomegaWithT(t)
 
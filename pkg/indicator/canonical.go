@@ -0,0 +1,491 @@
+package indicator
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	v1 "github.com/pivotal/monitoring-indicator-protocol/pkg/k8s/apis/indicatordocument/v1"
+)
+
+// DefaultsMode decides what FormatCanonical does with fields that ReadFile would default if they were
+// left out, e.g. a threshold's alert.for of 1m.
+type DefaultsMode string
+
+const (
+	// KeepDefaults leaves defaulted fields as they are written.
+	KeepDefaults DefaultsMode = "keep"
+	// ShowDefaults writes out every defaulted field.
+	ShowDefaults DefaultsMode = "show"
+	// HideDefaults removes fields that are set to their default.
+	HideDefaults DefaultsMode = "hide"
+)
+
+func ParseDefaultsMode(mode string) (DefaultsMode, error) {
+	switch DefaultsMode(mode) {
+	case KeepDefaults, ShowDefaults, HideDefaults:
+		return DefaultsMode(mode), nil
+	default:
+		return "", fmt.Errorf("unknown defaults mode %s, expected one of keep, show, hide", mode)
+	}
+}
+
+// The shape of a mapping in an indicator document: the canonical order of its keys, and the shapes of
+// its values. Keys that aren't known come after the known ones, in the order they were written in.
+type documentShape struct {
+	keys []string
+	// Free-form maps, like labels, are sorted by key instead.
+	sorted bool
+	fields map[string]*documentShape
+	// The shape of every value of a free-form map.
+	values *documentShape
+	// The values ReadFile defaults keys of the mapping to.
+	defaults map[string]string
+	// Nested mappings that only hold defaults, which are created to show them and removed once their
+	// defaults are hidden.
+	defaulted []string
+}
+
+var sortedMapShape = &documentShape{sorted: true}
+
+var alertMetadataShape = map[string]*documentShape{
+	"labels":      sortedMapShape,
+	"annotations": sortedMapShape,
+}
+
+var alertKeys = []string{"for", "step", "labels", "annotations", "runbookURL", "owner"}
+
+var presentationKeys = []string{"chartType", "currentValue", "frequency", "labels", "units"}
+
+var thresholdAlertShape = &documentShape{
+	keys:     alertKeys,
+	fields:   alertMetadataShape,
+	defaults: map[string]string{"for": "1m", "step": "1m"},
+}
+
+var thresholdShape = &documentShape{
+	keys: []string{"level", "operator", "value", "lower", "upper", "recovery", "matchers", "alert"},
+	fields: map[string]*documentShape{
+		"matchers": sortedMapShape,
+		"alert":    thresholdAlertShape,
+	},
+	defaulted: []string{"alert"},
+}
+
+var presentationShape = &documentShape{
+	keys:     presentationKeys,
+	defaults: map[string]string{"chartType": "step", "units": "short"},
+}
+
+var indicatorShape = &documentShape{
+	keys: []string{
+		"name", "type", "deprecated", "deprecationMessage", "replacedBy", "title", "description", "promql",
+		"composite", "thresholds", "alert", "presentation", "documentation",
+	},
+	fields: map[string]*documentShape{
+		"composite":  {keys: []string{"rule", "level", "indicators"}},
+		"thresholds": thresholdShape,
+		"alert": {
+			keys:   []string{"labels", "annotations", "runbookURL", "owner"},
+			fields: alertMetadataShape,
+		},
+		"presentation": presentationShape,
+	},
+	defaults:  map[string]string{"type": "other"},
+	defaulted: []string{"presentation"},
+}
+
+var canonicalDocumentShape = &documentShape{
+	keys: []string{"apiVersion", "kind", "metadata", "spec"},
+	fields: map[string]*documentShape{
+		"metadata": {
+			keys:   []string{"name", "labels"},
+			fields: map[string]*documentShape{"labels": sortedMapShape},
+		},
+		"spec": {
+			keys: []string{"product", "defaults", "imports", "indicators", "indicatorTemplates", "slos", "layout"},
+			fields: map[string]*documentShape{
+				"product": {keys: []string{"name", "version"}},
+				"defaults": {
+					keys: []string{"alert", "presentation", "documentation"},
+					fields: map[string]*documentShape{
+						"alert":        {keys: alertKeys, fields: alertMetadataShape},
+						"presentation": {keys: presentationKeys},
+					},
+				},
+				"imports": {
+					keys: []string{"path", "thresholds"},
+					fields: map[string]*documentShape{
+						"thresholds": {values: thresholdShape},
+					},
+				},
+				"indicators": indicatorShape,
+				"indicatorTemplates": {
+					keys:   []string{"indicator", "parameters"},
+					fields: map[string]*documentShape{"indicator": indicatorShape},
+				},
+				"slos": {
+					keys:     []string{"name", "goodEvents", "totalEvents", "objective", "window", "documentation"},
+					defaults: map[string]string{"window": "30d"},
+				},
+				"layout": {
+					keys: []string{"title", "description", "owner", "sections"},
+					fields: map[string]*documentShape{
+						"sections": {keys: []string{"title", "description", "indicators"}},
+					},
+				},
+			},
+		},
+	},
+}
+
+// FormatCanonical rewrites an indicator document in canonical form: keys in a fixed order, two space
+// indentation, and threshold operators written as their abbreviations, e.g. gte instead of >=. Comments
+// and ERB are kept, so that the source of a BOSH release can be formatted before it is rendered.
+func FormatCanonical(docBytes []byte, defaults DefaultsMode) ([]byte, error) {
+	protected, restoreERB := protectERB(docBytes)
+
+	var root yaml.Node
+	err := yaml.Unmarshal(protected, &root)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse the document: %s", err)
+	}
+	if len(root.Content) == 0 || root.Content[0].Kind != yaml.MappingNode {
+		return nil, errors.New("could not parse the document: expected a mapping")
+	}
+	f := formatter{mode: defaults, documentDefaults: documentDefaults(root.Content[0])}
+	attachTrailingComment(root.Content[0], f.canonicalize(root.Content[0], canonicalDocumentShape))
+
+	var output bytes.Buffer
+	if bytes.HasPrefix(bytes.TrimSpace(docBytes), []byte("---")) {
+		output.WriteString("---\n")
+	}
+	encoder := yaml.NewEncoder(&output)
+	encoder.SetIndent(2)
+	err = encoder.Encode(&root)
+	if err != nil {
+		return nil, err
+	}
+	err = encoder.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return restoreERB(separateSections(output.Bytes())), nil
+}
+
+type formatter struct {
+	mode DefaultsMode
+	// The document's spec.defaults, which replace the built-in defaults of the shapes.
+	documentDefaults map[*documentShape]map[string]string
+}
+
+// Reads the alert for and step, and presentation chartType and units defaults of the document.
+func documentDefaults(document *yaml.Node) map[*documentShape]map[string]string {
+	defaults := mappingValue(mappingValue(document, "spec"), "defaults")
+	values := make(map[*documentShape]map[string]string)
+	for shape, node := range map[*documentShape]*yaml.Node{
+		thresholdAlertShape: mappingValue(defaults, "alert"),
+		presentationShape:   mappingValue(defaults, "presentation"),
+	} {
+		for key := range shape.defaults {
+			if v := mappingValue(node, key); v != nil && v.Kind == yaml.ScalarNode {
+				if values[shape] == nil {
+					values[shape] = make(map[string]string)
+				}
+				values[shape][key] = v.Value
+			}
+		}
+	}
+	return values
+}
+
+func (f formatter) defaultValue(shape *documentShape, key string) string {
+	if value, ok := f.documentDefaults[shape][key]; ok {
+		return value
+	}
+	return shape.defaults[key]
+}
+
+// Comments at the end of a block, like the ERB closing a conditional indicator, are attached to the last
+// key of the innermost mapping by the YAML parser. They have to stay at the end of the block when keys are
+// reordered or defaults added, so they are returned to the parent until they reach a block that isn't
+// the last one of its parent.
+func (f formatter) canonicalize(node *yaml.Node, shape *documentShape) string {
+	if node.Kind == yaml.SequenceNode {
+		var trailing string
+		for i, item := range node.Content {
+			trailing = f.canonicalize(item, shape)
+			if i < len(node.Content)-1 {
+				attachTrailingComment(item, trailing)
+			}
+		}
+		return trailing
+	}
+	if node.Kind != yaml.MappingNode {
+		return ""
+	}
+
+	var trailing []string
+	var lastKey *yaml.Node
+	if len(node.Content) > 0 {
+		lastKey = node.Content[len(node.Content)-2]
+		trailing = appendComment(trailing, lastKey.FootComment)
+		lastKey.FootComment = ""
+	}
+
+	switch f.mode {
+	case ShowDefaults:
+		f.showDefaults(node, shape)
+	case HideDefaults:
+		f.hideDefaults(node, shape)
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if key.Value == "operator" && value.Kind == yaml.ScalarNode && shape == thresholdShape {
+			if operator := normalizeOperator(value.Value); operator != value.Value {
+				value.Value = operator
+				value.Style = 0
+			}
+		}
+
+		field, ok := shape.fields[key.Value]
+		if !ok {
+			field = shape.values
+		}
+		if field == nil {
+			continue
+		}
+		childTrailing := f.canonicalize(value, field)
+		removed := f.mode == HideDefaults && isDefaulted(shape, key.Value) &&
+			len(value.Content) == 0 && !hasComments(key) && !hasComments(value)
+		if removed {
+			node.Content = append(node.Content[:i], node.Content[i+2:]...)
+			i -= 2
+		}
+		if key == lastKey || removed {
+			trailing = appendComment(trailing, childTrailing)
+		} else {
+			attachTrailingComment(value, childTrailing)
+		}
+	}
+
+	sortKeys(node, shape)
+
+	return strings.Join(trailing, "\n")
+}
+
+func attachTrailingComment(node *yaml.Node, comment string) {
+	if comment == "" {
+		return
+	}
+	// Like the parser, attaches the comment to the innermost last key, so that formatting is idempotent.
+	switch {
+	case node.Kind == yaml.MappingNode && len(node.Content) > 0:
+		lastKey, lastValue := node.Content[len(node.Content)-2], node.Content[len(node.Content)-1]
+		if (lastValue.Kind == yaml.MappingNode || lastValue.Kind == yaml.SequenceNode) && len(lastValue.Content) > 0 {
+			attachTrailingComment(lastValue, comment)
+			return
+		}
+		lastKey.FootComment = strings.Join(appendComment([]string{comment}, lastKey.FootComment), "\n")
+	case node.Kind == yaml.SequenceNode && len(node.Content) > 0:
+		attachTrailingComment(node.Content[len(node.Content)-1], comment)
+	default:
+		node.FootComment = comment
+	}
+}
+
+func appendComment(comments []string, comment string) []string {
+	if comment == "" {
+		return comments
+	}
+	return append(comments, comment)
+}
+
+func isDefaulted(shape *documentShape, key string) bool {
+	for _, k := range shape.defaulted {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// Operators are matched case insensitively, and can be written as symbols, e.g. >= for gte.
+func normalizeOperator(operator string) string {
+	normalized := strings.ToLower(strings.TrimSpace(operator))
+	for op := v1.LessThan; op <= v1.Outside; op++ {
+		symbol := v1.GetComparatorSymbol(op)
+		if normalized == v1.GetComparatorAbbrev(op) || (symbol != "" && normalized == symbol) {
+			return v1.GetComparatorAbbrev(op)
+		}
+	}
+	return operator
+}
+
+func sortKeys(node *yaml.Node, shape *documentShape) {
+	if !shape.sorted && len(shape.keys) == 0 {
+		return
+	}
+
+	rank := make(map[string]int, len(shape.keys))
+	for i, key := range shape.keys {
+		rank[key] = i
+	}
+	keyRank := func(key string) int {
+		if r, ok := rank[key]; ok {
+			return r
+		}
+		return len(shape.keys)
+	}
+
+	pairs := make([][2]*yaml.Node, 0, len(node.Content)/2)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		pairs = append(pairs, [2]*yaml.Node{node.Content[i], node.Content[i+1]})
+	}
+	sort.SliceStable(pairs, func(i, j int) bool {
+		if shape.sorted {
+			return pairs[i][0].Value < pairs[j][0].Value
+		}
+		return keyRank(pairs[i][0].Value) < keyRank(pairs[j][0].Value)
+	})
+
+	node.Content = node.Content[:0]
+	for _, p := range pairs {
+		node.Content = append(node.Content, p[0], p[1])
+	}
+}
+
+func (f formatter) showDefaults(node *yaml.Node, shape *documentShape) {
+	for _, key := range shape.defaulted {
+		if mappingValue(node, key) == nil {
+			node.Content = append(node.Content, stringNode(key), &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"})
+		}
+	}
+	for _, key := range sortedKeys(shape.defaults) {
+		if mappingValue(node, key) == nil {
+			node.Content = append(node.Content, stringNode(key), stringNode(f.defaultValue(shape, key)))
+		}
+	}
+}
+
+// Fields with comments are kept, since removing them would lose the comments.
+func (f formatter) hideDefaults(node *yaml.Node, shape *documentShape) {
+	for key := range shape.defaults {
+		value := f.defaultValue(shape, key)
+		removeKey(node, func(k, v *yaml.Node) bool {
+			return k.Value == key && v.Kind == yaml.ScalarNode && v.Value == value && !hasComments(k) && !hasComments(v)
+		})
+	}
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func removeKey(node *yaml.Node, matches func(key, value *yaml.Node) bool) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if matches(node.Content[i], node.Content[i+1]) {
+			node.Content = append(node.Content[:i], node.Content[i+2:]...)
+			return
+		}
+	}
+}
+
+func hasComments(node *yaml.Node) bool {
+	return node.HeadComment != "" || node.LineComment != "" || node.FootComment != ""
+}
+
+func stringNode(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
+
+var topLevelBlockKeyRegex = regexp.MustCompile(`^[^\s#-][^:]*:( #.*)?$`)
+
+// Separates the top level blocks of the document, like metadata and spec, by a blank line. The comments
+// above a block stay with it.
+func separateSections(formatted []byte) []byte {
+	lines := strings.SplitAfter(string(formatted), "\n")
+	separated := make(map[int]bool)
+	keysSeen := false
+	for i, line := range lines {
+		trimmed := strings.TrimRight(line, "\n")
+		if keysSeen && topLevelBlockKeyRegex.MatchString(trimmed) {
+			start := i
+			for start > 0 && strings.HasPrefix(lines[start-1], "#") {
+				start--
+			}
+			separated[start] = true
+		}
+		if trimmed != "" && trimmed != "---" && !strings.HasPrefix(trimmed, " ") && !strings.HasPrefix(trimmed, "#") {
+			keysSeen = true
+		}
+	}
+
+	var output strings.Builder
+	for i, line := range lines {
+		if separated[i] {
+			output.WriteString("\n")
+		}
+		output.WriteString(line)
+	}
+	return []byte(output.String())
+}
+
+var erbLineRegex = regexp.MustCompile(`(?m)^[ \t]*<%[^\n]*%>[ \t]*$`)
+
+// Replaces ERB with placeholders that parse as YAML, and returns a function that puts the ERB back. ERB
+// on its own line, like `<% if p('enabled') %>`, becomes a comment so that it stays on its own line.
+func protectERB(docBytes []byte) ([]byte, func([]byte) []byte) {
+	prefix := "__erb"
+	for bytes.Contains(docBytes, []byte(prefix)) {
+		prefix = "_" + prefix
+	}
+
+	var tags []string
+	placeholder := func(kind string, tag []byte) []byte {
+		tags = append(tags, string(tag))
+		return []byte(fmt.Sprintf("%s_%s_%d__", prefix, kind, len(tags)-1))
+	}
+
+	protected := erbLineRegex.ReplaceAllFunc(docBytes, func(line []byte) []byte {
+		tag := bytes.TrimSpace(line)
+		if len(erbTagRegex.FindAllIndex(tag, -1)) != 1 {
+			return line
+		}
+		indentation := string(line[:bytes.Index(line, tag)])
+		return append([]byte(indentation+"# "), placeholder("line", tag)...)
+	})
+	protected = erbTagRegex.ReplaceAllFunc(protected, func(tag []byte) []byte {
+		return placeholder("tag", tag)
+	})
+
+	placeholderRegex := regexp.MustCompile(`# ` + prefix + `_line_(\d+)__|` + prefix + `_tag_(\d+)__`)
+	restore := func(formatted []byte) []byte {
+		return placeholderRegex.ReplaceAllFunc(formatted, func(p []byte) []byte {
+			match := placeholderRegex.FindSubmatch(p)
+			index := match[1]
+			if len(index) == 0 {
+				index = match[2]
+			}
+			i, err := strconv.Atoi(string(index))
+			if err != nil || i >= len(tags) {
+				return p
+			}
+			return []byte(tags[i])
+		})
+	}
+	return protected, restore
+}
@@ -0,0 +1,267 @@
+package indicator_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal/monitoring-indicator-protocol/pkg/indicator"
+)
+
+func TestFormatCanonical(t *testing.T) {
+	format := func(g *GomegaWithT, doc string, defaults indicator.DefaultsMode) string {
+		formatted, err := indicator.FormatCanonical([]byte(doc), defaults)
+		g.Expect(err).ToNot(HaveOccurred())
+
+		again, err := indicator.FormatCanonical(formatted, defaults)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(string(again)).To(Equal(string(formatted)), "formatting is not idempotent")
+
+		return string(formatted)
+	}
+
+	t.Run("orders keys and indents canonically", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		g.Expect(format(g, `---
+spec:
+  layout:
+    sections:
+    - indicators: [latency]
+      title: Main
+    title: My Product
+  indicators:
+  - promql: latency
+    thresholds:
+    - operator: gt
+      value: 100
+      level: critical
+      matchers:
+        z: "1"
+        a: "2"
+    name: latency
+  product: {version: 1.0.0, name: my-product}
+metadata:
+  labels:
+    source_id: api
+    deployment: cf
+  custom: kept after known keys
+kind: IndicatorDocument
+apiVersion: indicatorprotocol.io/v1
+`, indicator.KeepDefaults)).To(Equal(`---
+apiVersion: indicatorprotocol.io/v1
+kind: IndicatorDocument
+
+metadata:
+  labels:
+    deployment: cf
+    source_id: api
+  custom: kept after known keys
+
+spec:
+  product: {name: my-product, version: 1.0.0}
+  indicators:
+    - name: latency
+      promql: latency
+      thresholds:
+        - level: critical
+          operator: gt
+          value: 100
+          matchers:
+            a: "2"
+            z: "1"
+  layout:
+    title: My Product
+    sections:
+      - title: Main
+        indicators: [latency]
+`))
+	})
+
+	t.Run("normalizes operators", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		formatted := format(g, `spec:
+  indicators:
+  - name: latency
+    thresholds:
+    - {level: a, operator: ">=", value: 1}
+    - {level: b, operator: "!=", value: 1}
+    - {level: c, operator: GT, value: 1}
+    - {level: d, operator: " Between ", lower: 1, upper: 2}
+    - {level: e, operator: unknown, value: 1}
+`, indicator.KeepDefaults)
+		g.Expect(formatted).To(ContainSubstring("{level: a, operator: gte, value: 1}"))
+		g.Expect(formatted).To(ContainSubstring("{level: b, operator: neq, value: 1}"))
+		g.Expect(formatted).To(ContainSubstring("{level: c, operator: gt, value: 1}"))
+		g.Expect(formatted).To(ContainSubstring("{level: d, operator: between, lower: 1, upper: 2}"))
+		g.Expect(formatted).To(ContainSubstring("{level: e, operator: unknown, value: 1}"))
+	})
+
+	t.Run("keeps comments and ERB", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		g.Expect(format(g, `# The indicators of the API
+spec:
+  indicators:
+  - promql: rate(errors[5m]) # per second
+    # The canonical name
+    name: errors
+    thresholds:
+    - level: critical
+      operator: gt
+      value: <%= p('errors.critical') %>
+<% if p('latency.enabled') %>
+  - promql: latency{source_id="<%= p('source_id') %>"}
+    name: latency
+<% end %>
+  product:
+    name: <%= p('product') %>
+`, indicator.KeepDefaults)).To(Equal(`# The indicators of the API
+spec:
+  product:
+    name: <%= p('product') %>
+  indicators:
+    - # The canonical name
+      name: errors
+      promql: rate(errors[5m]) # per second
+      thresholds:
+        - level: critical
+          operator: gt
+          value: <%= p('errors.critical') %>
+          <% if p('latency.enabled') %>
+    - name: latency
+      promql: latency{source_id="<%= p('source_id') %>"}
+      <% end %>
+`))
+	})
+
+	t.Run("shows defaults", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		g.Expect(format(g, `spec:
+  indicators:
+  - name: latency
+    thresholds:
+    - level: critical
+      operator: gt
+      value: 100
+    presentation:
+      units: ms
+  slos:
+  - name: availability
+`, indicator.ShowDefaults)).To(Equal(`spec:
+  indicators:
+    - name: latency
+      type: other
+      thresholds:
+        - level: critical
+          operator: gt
+          value: 100
+          alert:
+            for: 1m
+            step: 1m
+      presentation:
+        chartType: step
+        units: ms
+  slos:
+    - name: availability
+      window: 30d
+`))
+	})
+
+	t.Run("hides defaults", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		g.Expect(format(g, `spec:
+  indicators:
+  - name: latency
+    type: other
+    thresholds:
+    - level: critical
+      operator: gt
+      value: 100
+      alert:
+        for: 1m
+        step: 1m
+    - level: warning
+      operator: gt
+      value: 50
+      alert:
+        for: 5m
+        step: 1m # checked every minute
+    presentation:
+      chartType: step
+      units: short
+  slos:
+  - name: availability
+    window: 7d
+`, indicator.HideDefaults)).To(Equal(`spec:
+  indicators:
+    - name: latency
+      thresholds:
+        - level: critical
+          operator: gt
+          value: 100
+        - level: warning
+          operator: gt
+          value: 50
+          alert:
+            for: 5m
+            step: 1m # checked every minute
+  slos:
+    - name: availability
+      window: 7d
+`))
+	})
+
+	t.Run("shows and hides the document's defaults", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		doc := `spec:
+  defaults:
+    presentation:
+      units: ms
+    alert:
+      for: 10m
+  indicators:
+  - name: latency
+    thresholds:
+    - level: critical
+      operator: gt
+      value: 100
+      alert:
+        for: 10m
+        step: 1m
+    presentation:
+      units: ms
+`
+		g.Expect(format(g, doc, indicator.HideDefaults)).To(Equal(`spec:
+  defaults:
+    alert:
+      for: 10m
+    presentation:
+      units: ms
+  indicators:
+    - name: latency
+      thresholds:
+        - level: critical
+          operator: gt
+          value: 100
+`))
+		g.Expect(format(g, doc, indicator.ShowDefaults)).To(ContainSubstring(`      presentation:
+        chartType: step
+        units: ms
+`))
+	})
+
+	t.Run("returns an error for invalid YAML", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		_, err := indicator.FormatCanonical([]byte("spec: [\n"), indicator.KeepDefaults)
+		g.Expect(err).To(HaveOccurred())
+
+		_, err = indicator.FormatCanonical([]byte("- a list\n"), indicator.KeepDefaults)
+		g.Expect(err).To(MatchError("could not parse the document: expected a mapping"))
+	})
+}
@@ -0,0 +1,138 @@
+package indicator
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var erbTagRegex = regexp.MustCompile(`(?s)<%(=?)(.*?)%>`)
+var propertyCallRegex = regexp.MustCompile(`^p\(\s*(?:'([^']*)'|"([^"]*)")\s*(?:,\s*(.+?)\s*)?\)$`)
+var numberLiteralRegex = regexp.MustCompile(`^-?\d+(\.\d+)?$`)
+
+// ReadPropertiesFile reads the job properties used to render ERB, e.g. the `properties` of an instance
+// group in a BOSH manifest. The properties can either be at the top level of the file, or below a
+// `properties` key.
+func ReadPropertiesFile(propertiesFile string) (map[string]interface{}, error) {
+	fileBytes, err := ioutil.ReadFile(propertiesFile)
+	if err != nil {
+		return nil, fmt.Errorf("could not read properties file: %s", err)
+	}
+
+	var properties map[string]interface{}
+	err = yaml.Unmarshal(fileBytes, &properties)
+	if err != nil {
+		return nil, fmt.Errorf("could not unmarshal properties file: %s", err)
+	}
+	if nested, ok := properties["properties"].(map[string]interface{}); ok {
+		return nested, nil
+	}
+	if properties == nil {
+		properties = map[string]interface{}{}
+	}
+
+	return properties, nil
+}
+
+// RenderERB makes ReadFile evaluate the document's ERB with the given job properties instead of replacing
+// it with a placeholder. Only `<%= p('name') %>` and `<%= p('name', default) %>` can be evaluated, where
+// the default is a string, number, boolean or nil literal.
+func RenderERB(properties map[string]interface{}) ReadOpt {
+	return func(options *readOptions) {
+		options.erbProperties = properties
+	}
+}
+
+func renderERB(fileBytes []byte, properties map[string]interface{}) ([]byte, error) {
+	var rendered bytes.Buffer
+	last := 0
+
+	for _, match := range erbTagRegex.FindAllSubmatchIndex(fileBytes, -1) {
+		tag := string(fileBytes[match[0]:match[1]])
+		line := bytes.Count(fileBytes[:match[0]], []byte("\n")) + 1
+
+		if match[3] == match[2] {
+			return nil, fmt.Errorf("line %d: unsupported ERB %s, only output tags like <%%= p('name') %%> can be rendered", line, tag)
+		}
+
+		value, err := evaluateERBExpression(strings.TrimSpace(string(fileBytes[match[4]:match[5]])), properties)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", line, err)
+		}
+
+		rendered.Write(fileBytes[last:match[0]])
+		rendered.WriteString(value)
+		last = match[1]
+	}
+	rendered.Write(fileBytes[last:])
+
+	return rendered.Bytes(), nil
+}
+
+func evaluateERBExpression(expression string, properties map[string]interface{}) (string, error) {
+	call := propertyCallRegex.FindStringSubmatch(expression)
+	if call == nil {
+		return "", fmt.Errorf("unsupported ERB expression %q, only p('name') and p('name', default) can be rendered", expression)
+	}
+
+	name := call[1] + call[2]
+	value, ok := lookupProperty(properties, name)
+	if !ok {
+		if call[3] == "" {
+			return "", fmt.Errorf("property %s is not defined in the properties file and has no default", name)
+		}
+		return evaluateLiteral(call[3])
+	}
+
+	return formatProperty(name, value)
+}
+
+// Properties are nested, e.g. p('a.b') is the b key of the a property. Like BOSH, null properties are
+// treated as missing.
+func lookupProperty(properties map[string]interface{}, name string) (interface{}, bool) {
+	var value interface{} = properties
+	for _, key := range strings.Split(name, ".") {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		value, ok = m[key]
+		if !ok || value == nil {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+func formatProperty(name string, value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case int:
+		return strconv.Itoa(v), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	default:
+		return "", fmt.Errorf("property %s is not a string, number or boolean and cannot be rendered", name)
+	}
+}
+
+func evaluateLiteral(literal string) (string, error) {
+	switch {
+	case len(literal) >= 2 && (literal[0] == '\'' || literal[0] == '"') && literal[len(literal)-1] == literal[0]:
+		return literal[1 : len(literal)-1], nil
+	case numberLiteralRegex.MatchString(literal), literal == "true", literal == "false":
+		return literal, nil
+	case literal == "nil":
+		return "", nil
+	default:
+		return "", fmt.Errorf("unsupported default %s, defaults have to be string, number, boolean or nil literals", literal)
+	}
+}
@@ -0,0 +1,113 @@
+package indicator_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal/monitoring-indicator-protocol/pkg/indicator"
+)
+
+func TestRenderERB(t *testing.T) {
+	document := func(thresholds string) string {
+		return `---
+apiVersion: indicatorprotocol.io/v1
+kind: IndicatorDocument
+
+metadata:
+  labels:
+    deployment: <%= p('deployment.name') %>
+
+spec:
+  product:
+    name: my-product
+    version: 0.0.1
+  indicators:
+  - name: latency
+    promql: latency{source_id="<%= p("source_id", 'api') %>"}
+    thresholds:
+` + thresholds
+	}
+
+	t.Run("renders properties and defaults", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		dir := writeFiles(t, map[string]string{
+			"indicators.yml": document(`
+    - level: critical
+      operator: gt
+      value: <%= p('latency.critical') %>
+    - level: warning
+      operator: gt
+      value: <%= p('latency.warning', 250) %>
+`),
+			"properties.yml": `---
+properties:
+  deployment:
+    name: cf
+  latency:
+    critical: 500.5
+    warning: ~
+`,
+		})
+		defer os.RemoveAll(dir)
+
+		properties, err := indicator.ReadPropertiesFile(filepath.Join(dir, "properties.yml"))
+		g.Expect(err).ToNot(HaveOccurred())
+
+		doc, err := indicator.ReadFile(filepath.Join(dir, "indicators.yml"), indicator.RenderERB(properties))
+		g.Expect(err).ToNot(HaveOccurred())
+
+		g.Expect(doc.Labels).To(Equal(map[string]string{"deployment": "cf"}))
+		g.Expect(doc.Spec.Indicators[0].PromQL).To(Equal(`latency{source_id="api"}`))
+		g.Expect(doc.Spec.Indicators[0].Thresholds[0].Value).To(Equal(500.5))
+		g.Expect(doc.Spec.Indicators[0].Thresholds[1].Value).To(Equal(250.0))
+	})
+
+	t.Run("reads properties at the top level of the properties file", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		dir := writeFiles(t, map[string]string{
+			"properties.yml": `---
+deployment:
+  name: cf
+`,
+		})
+		defer os.RemoveAll(dir)
+
+		properties, err := indicator.ReadPropertiesFile(filepath.Join(dir, "properties.yml"))
+		g.Expect(err).ToNot(HaveOccurred())
+
+		g.Expect(properties).To(Equal(map[string]interface{}{
+			"deployment": map[string]interface{}{"name": "cf"},
+		}))
+	})
+
+	t.Run("fails for missing properties and unsupported ERB", func(t *testing.T) {
+		tests := map[string]string{
+			"<%= p('latency.critical') %>":       "line 20: property latency.critical is not defined in the properties file and has no default",
+			"<%= p('latency').to_i %>":           `line 20: unsupported ERB expression "p('latency').to_i", only p('name') and p('name', default) can be rendered`,
+			"<% if p('latency') %>1<% end %>":    "line 20: unsupported ERB <% if p('latency') %>, only output tags like <%= p('name') %> can be rendered",
+			"<%= p('latency', default_value) %>": "line 20: unsupported default default_value, defaults have to be string, number, boolean or nil literals",
+			"<%= p('deployment') %>":             "line 20: property deployment is not a string, number or boolean and cannot be rendered",
+		}
+
+		for erb, expectedError := range tests {
+			g := NewGomegaWithT(t)
+			dir := writeFiles(t, map[string]string{
+				"indicators.yml": document(`
+    - level: critical
+      operator: gt
+      value: ` + erb + `
+`),
+			})
+
+			_, err := indicator.ReadFile(filepath.Join(dir, "indicators.yml"), indicator.RenderERB(map[string]interface{}{
+				"deployment": map[string]interface{}{"name": "cf"},
+			}))
+			g.Expect(err).To(MatchError(ContainSubstring(expectedError)), "%s", erb)
+
+			_ = os.RemoveAll(dir)
+		}
+	})
+}
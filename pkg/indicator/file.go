@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"path/filepath"
 	"regexp"
 	"strings"
 
@@ -14,24 +15,32 @@ import (
 // Reads the IndicatorDocument in the file with the given name,
 // Returns an error if the file can't be read, or the file isn't valid
 // YAML parsable as a document, or the document can't be validated.
+// ERB is replaced by a placeholder, unless it is rendered with RenderERB. The same applies to the
+// documents it imports.
 func ReadFile(indicatorsFile string, opts ...ReadOpt) (IndicatorDocument, error) {
 	fileBytes, err := ioutil.ReadFile(indicatorsFile)
-
-	reg := regexp.MustCompile("<%=.*%>")
-	fileBytes = reg.ReplaceAll(fileBytes, []byte("<%= ERB REMOVED FOR YAML SAFETY %>"))
-
 	if err != nil {
 		return IndicatorDocument{}, err
 	}
 
+	if properties := getReadOpts(opts).erbProperties; properties != nil {
+		fileBytes, err = renderERB(fileBytes, properties)
+		if err != nil {
+			return IndicatorDocument{}, fmt.Errorf("could not render ERB in %s: %s", indicatorsFile, err)
+		}
+	} else {
+		fileBytes = removeERB(fileBytes)
+	}
+
 	reader := ioutil.NopCloser(bytes.NewReader(fileBytes))
+	opts = append([]ReadOpt{ResolveImports(FileImportResolver, filepath.Clean(indicatorsFile))}, opts...)
 	doc, errs := DocumentFromYAML(reader, opts...)
 
 	if len(errs) > 0 {
 		var errorString strings.Builder
 		errorString.WriteString("validation for indicator document failed:\n")
 		for _, e := range errs {
-			_, err = fmt.Fprintf(&errorString, "- %v\n", e)
+			_, err = fmt.Fprintf(&errorString, "- %s\n", DescribeError(e))
 			if err != nil {
 				errorString.WriteString("failed to parse error\n")
 			}
@@ -42,6 +51,11 @@ func ReadFile(indicatorsFile string, opts ...ReadOpt) (IndicatorDocument, error)
 	return doc, nil
 }
 
+func removeERB(fileBytes []byte) []byte {
+	reg := regexp.MustCompile("<%=.*%>")
+	return reg.ReplaceAll(fileBytes, []byte("<%= ERB REMOVED FOR YAML SAFETY %>"))
+}
+
 func getReadOpts(optionsFuncs []ReadOpt) readOptions {
 	options := readOptions{
 		interpolate: true,
@@ -66,5 +80,6 @@ func ReadPatchFile(patchFile string) (Patch, error) {
 	if err != nil {
 		return Patch{}, errors.New("could not unmarshal patch file")
 	}
+	patch.Origin = patchFile
 	return patch, nil
 }
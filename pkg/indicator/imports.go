@@ -0,0 +1,197 @@
+package indicator
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	v1 "github.com/pivotal/monitoring-indicator-protocol/pkg/k8s/apis/indicatordocument/v1"
+)
+
+// ImportResolver returns the contents of the document imported with the given path by the document
+// at the given location. It also returns the location of the imported document, which is used to
+// resolve its own imports and to detect import cycles.
+type ImportResolver func(location string, path string) (importedLocation string, contents []byte, err error)
+
+// FileImportResolver resolves import paths relative to the directory of the importing file.
+func FileImportResolver(location string, path string) (string, []byte, error) {
+	importedLocation := path
+	if !filepath.IsAbs(path) {
+		importedLocation = filepath.Join(filepath.Dir(location), path)
+	}
+
+	contents, err := ioutil.ReadFile(importedLocation)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return filepath.Clean(importedLocation), contents, nil
+}
+
+// DirectoryImportResolver resolves every import path relative to the given directory, regardless of
+// where the importing document came from. Paths leading outside of the directory are rejected.
+func DirectoryImportResolver(directory string) ImportResolver {
+	return func(_ string, path string) (string, []byte, error) {
+		importedLocation := filepath.Join(directory, filepath.FromSlash(path))
+		relative, err := filepath.Rel(directory, importedLocation)
+		if err != nil || relative == ".." || strings.HasPrefix(relative, ".."+string(filepath.Separator)) {
+			return "", nil, fmt.Errorf("%s is outside of the imports directory", path)
+		}
+
+		contents, err := ioutil.ReadFile(importedLocation)
+		if err != nil {
+			return "", nil, err
+		}
+
+		return importedLocation, contents, nil
+	}
+}
+
+// ResolveImports enables the `imports` of documents. The location identifies the document being
+// read to the resolver, e.g. its file path.
+func ResolveImports(resolver ImportResolver, location string) ReadOpt {
+	return func(options *readOptions) {
+		options.importResolver = resolver
+		options.location = location
+	}
+}
+
+func inheritMetadata(metadata map[string]string) ReadOpt {
+	return func(options *readOptions) {
+		options.inheritedMetadata = metadata
+	}
+}
+
+func importedBy(locations []string) ReadOpt {
+	return func(options *readOptions) {
+		options.importedBy = locations
+	}
+}
+
+// Appends the indicators of every imported document to the document's indicators and removes the
+// imports, so that documents are stored and served fully resolved. Imported documents are
+// interpolated with the importing document's metadata before their own, and their ERB is rendered
+// with the importing document's properties. Imported indicators take the defaults of their own
+// document before those of the importing one. The returned origins are keyed by the index of each
+// imported indicator.
+func resolveImports(doc *v1.IndicatorDocument, options readOptions) (map[int]indicatorOrigin, []error) {
+	origins := make(map[int]indicatorOrigin)
+	var es []error
+
+	definedBy := make(map[string]string)
+	for _, i := range doc.Spec.Indicators {
+		definedBy[i.Name] = "the document"
+	}
+
+	for importIdx, imp := range doc.Spec.Imports {
+		path := fmt.Sprintf("spec.imports[%d]", importIdx)
+		if options.importResolver == nil {
+			es = append(es, v1.NewValidationError(path, "imports[%d]: imports cannot be resolved for this document", importIdx))
+			continue
+		}
+
+		importedLocation, contents, err := options.importResolver(options.location, imp.Path)
+		if err != nil {
+			es = append(es, v1.NewValidationError(path+".path", "imports[%d]: could not read %s: %s", importIdx, imp.Path, err))
+			continue
+		}
+
+		chain := append(append([]string{}, options.importedBy...), options.location)
+		if containsString(chain, importedLocation) {
+			es = append(es, v1.NewValidationError(path+".path", "imports[%d]: import cycle detected: %s -> %s", importIdx, strings.Join(chain, " -> "), importedLocation))
+			continue
+		}
+
+		if options.erbProperties != nil {
+			contents, err = renderERB(contents, options.erbProperties)
+			if err != nil {
+				es = append(es, v1.NewValidationError(path+".path", "imports[%d]: could not render ERB in %s: %s", importIdx, imp.Path, err))
+				continue
+			}
+		} else {
+			contents = removeERB(contents)
+		}
+
+		readOpts := []ReadOpt{
+			ResolveImports(options.importResolver, importedLocation),
+			importedBy(chain),
+		}
+		if options.interpolate {
+			readOpts = append(readOpts, inheritMetadata(doc.Labels))
+		}
+		if options.strictInterpolation {
+			readOpts = append(readOpts, StrictInterpolation)
+		}
+		if options.erbProperties != nil {
+			readOpts = append(readOpts, RenderERB(options.erbProperties))
+		}
+
+		imported, errs := DocumentFromYAML(ioutil.NopCloser(bytes.NewReader(contents)), readOpts...)
+		if len(errs) > 0 {
+			for _, e := range errs {
+				es = append(es, importedError(e, fmt.Sprintf("imports[%d]: %s", importIdx, imp.Path)))
+			}
+			continue
+		}
+
+		origin := fmt.Sprintf("imports[%d] (%s)", importIdx, imp.Path)
+		for _, name := range sortedThresholdOverrides(imp.Thresholds) {
+			if imported.Indicator(name) == nil {
+				es = append(es, v1.NewValidationError(path+".thresholds."+name, "imports[%d].thresholds: %s does not define indicator %s", importIdx, imp.Path, name))
+			}
+		}
+
+		for _, i := range imported.Spec.Indicators {
+			if previous, ok := definedBy[i.Name]; ok {
+				es = append(es, v1.NewValidationError(path, "imports[%d]: indicator %s from %s is already defined by %s", importIdx, i.Name, imp.Path, previous))
+				continue
+			}
+			definedBy[i.Name] = origin
+
+			if thresholds, ok := imp.Thresholds[i.Name]; ok {
+				i.Thresholds = append([]v1.Threshold{}, thresholds...)
+				es = append(es, normalizeImportedUnits(importIdx, &i, doc.Spec.Defaults, options.importedUnits)...)
+			}
+
+			origins[len(doc.Spec.Indicators)] = indicatorOrigin{description: origin, path: path}
+			doc.Spec.Indicators = append(doc.Spec.Indicators, i)
+		}
+	}
+
+	doc.Spec.Imports = nil
+
+	return origins, es
+}
+
+// Errors of imported documents keep the position they have in the imported document.
+func importedError(err error, prefix string) error {
+	e, ok := err.(*v1.ValidationError)
+	if !ok {
+		return fmt.Errorf("%s: %s", prefix, err)
+	}
+
+	located := *e
+	located.Message = fmt.Sprintf("%s: %s", prefix, e.Message)
+	return &located
+}
+
+func sortedThresholdOverrides(overrides map[string][]v1.Threshold) []string {
+	names := make([]string, 0, len(overrides))
+	for name := range overrides {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
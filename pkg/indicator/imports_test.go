@@ -0,0 +1,384 @@
+package indicator_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal/monitoring-indicator-protocol/pkg/indicator"
+	v1 "github.com/pivotal/monitoring-indicator-protocol/pkg/k8s/apis/indicatordocument/v1"
+	"github.com/pivotal/monitoring-indicator-protocol/test_fixtures"
+)
+
+const goRuntimeLibrary = `---
+apiVersion: indicatorprotocol.io/v1
+kind: IndicatorDocument
+
+spec:
+  product:
+    name: go-runtime
+    version: 1.0.0
+  indicators:
+  - name: go_goroutines
+    promql: go_goroutines{deployment="$deployment"}
+    thresholds:
+    - level: warning
+      operator: gt
+      value: 1000
+  - name: go_gc_duration
+    promql: go_gc_duration_seconds{deployment="$deployment"}
+`
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "imports")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, contents := range files {
+		path := filepath.Join(dir, name)
+		err = os.MkdirAll(filepath.Dir(path), 0755)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = ioutil.WriteFile(path, []byte(contents), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+func TestImports(t *testing.T) {
+	t.Run("ReadFile adds the indicators of imported documents", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		dir := writeFiles(t, map[string]string{
+			"libraries/go_runtime.yml": goRuntimeLibrary,
+			"product/indicators.yml": `---
+apiVersion: indicatorprotocol.io/v1
+kind: IndicatorDocument
+
+metadata:
+  labels:
+    deployment: my-deployment
+
+spec:
+  product:
+    name: my-product
+    version: 0.0.1
+  imports:
+  - path: ../libraries/go_runtime.yml
+  indicators:
+  - name: my_indicator
+    promql: my_metric
+`,
+		})
+		defer os.RemoveAll(dir)
+
+		doc, err := indicator.ReadFile(filepath.Join(dir, "product/indicators.yml"))
+		g.Expect(err).ToNot(HaveOccurred())
+
+		g.Expect(doc.Spec.Imports).To(BeNil())
+		g.Expect(doc.Spec.Indicators).To(HaveLen(3))
+		g.Expect(doc.Spec.Indicators[1]).To(Equal(v1.IndicatorSpec{
+			Name:   "go_goroutines",
+			PromQL: `go_goroutines{deployment="my-deployment"}`,
+			Thresholds: []v1.Threshold{{
+				Level:    "warning",
+				Operator: v1.GreaterThan,
+				Value:    1000,
+				Alert:    test_fixtures.DefaultAlert(),
+			}},
+			Presentation: test_fixtures.DefaultPresentation(),
+		}))
+		g.Expect(doc.Spec.Indicators[2].Name).To(Equal("go_gc_duration"))
+		g.Expect(doc.Spec.Layout.Sections[0].Indicators).To(Equal([]string{
+			"my_indicator",
+			"go_goroutines",
+			"go_gc_duration",
+		}))
+	})
+
+	t.Run("overrides the thresholds of imported indicators", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		dir := writeFiles(t, map[string]string{
+			"go_runtime.yml": goRuntimeLibrary,
+			"indicators.yml": `---
+apiVersion: indicatorprotocol.io/v1
+kind: IndicatorDocument
+
+metadata:
+  labels:
+    deployment: my-deployment
+
+spec:
+  product:
+    name: my-product
+    version: 0.0.1
+  imports:
+  - path: go_runtime.yml
+    thresholds:
+      go_goroutines:
+      - level: critical
+        operator: gt
+        value: 5000
+`,
+		})
+		defer os.RemoveAll(dir)
+
+		doc, err := indicator.ReadFile(filepath.Join(dir, "indicators.yml"))
+		g.Expect(err).ToNot(HaveOccurred())
+
+		g.Expect(doc.Indicator("go_goroutines").Thresholds).To(Equal([]v1.Threshold{{
+			Level:    "critical",
+			Operator: v1.GreaterThan,
+			Value:    5000,
+			Alert:    test_fixtures.DefaultAlert(),
+		}}))
+	})
+
+	t.Run("applies the defaults of the imported document before those of the importing one", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		dir := writeFiles(t, map[string]string{
+			"library.yml": `---
+apiVersion: indicatorprotocol.io/v1
+kind: IndicatorDocument
+
+spec:
+  product:
+    name: library
+    version: 1.0.0
+  defaults:
+    alert:
+      owner: library-team
+    documentation:
+      recommendedResponse: Scale up
+  indicators:
+  - name: library_indicator
+    promql: library_metric
+    thresholds:
+    - level: warning
+      operator: gt
+      value: 10
+`,
+			"indicators.yml": `---
+apiVersion: indicatorprotocol.io/v1
+kind: IndicatorDocument
+
+spec:
+  product:
+    name: my-product
+    version: 0.0.1
+  defaults:
+    alert:
+      owner: my-team
+      runbookURL: https://example.com/runbook
+      for: 5m
+    presentation:
+      chartType: bar
+      units: ms
+    documentation:
+      recommendedResponse: Page someone
+      title: Imported
+  imports:
+  - path: library.yml
+`,
+		})
+		defer os.RemoveAll(dir)
+
+		doc, err := indicator.ReadFile(filepath.Join(dir, "indicators.yml"))
+		g.Expect(err).ToNot(HaveOccurred())
+
+		imported := doc.Indicator("library_indicator")
+		g.Expect(imported.Alert.Owner).To(Equal("library-team"))
+		g.Expect(imported.Alert.RunbookURL).To(Equal("https://example.com/runbook"))
+		g.Expect(imported.Thresholds[0].Alert.For).To(Equal("5m"))
+		g.Expect(imported.Thresholds[0].Alert.Step).To(Equal("1m"))
+		g.Expect(imported.Presentation.ChartType).To(Equal(v1.BarChart))
+		g.Expect(imported.Presentation.Units).To(Equal("ms"))
+		g.Expect(imported.Documentation).To(Equal(map[string]string{
+			"recommendedResponse": "Scale up",
+			"title":               "Imported",
+		}))
+	})
+
+	t.Run("renders the ERB of imported documents with the importing document's properties", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		dir := writeFiles(t, map[string]string{
+			"library.yml": `---
+apiVersion: indicatorprotocol.io/v1
+kind: IndicatorDocument
+
+spec:
+  product:
+    name: library
+    version: 1.0.0
+  indicators:
+  - name: library_indicator
+    promql: library_metric{source_id="<%= p('source_id') %>"}
+`,
+			"indicators.yml": `---
+apiVersion: indicatorprotocol.io/v1
+kind: IndicatorDocument
+
+spec:
+  product:
+    name: my-product
+    version: 0.0.1
+  imports:
+  - path: library.yml
+`,
+		})
+		defer os.RemoveAll(dir)
+
+		doc, err := indicator.ReadFile(filepath.Join(dir, "indicators.yml"), indicator.RenderERB(map[string]interface{}{
+			"source_id": "api",
+		}))
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(doc.Indicator("library_indicator").PromQL).To(Equal(`library_metric{source_id="api"}`))
+
+		_, err = indicator.ReadFile(filepath.Join(dir, "indicators.yml"), indicator.RenderERB(map[string]interface{}{}))
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(err.Error()).To(ContainSubstring("imports[0]: could not render ERB in library.yml: "))
+
+		doc, err = indicator.ReadFile(filepath.Join(dir, "indicators.yml"))
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(doc.Indicator("library_indicator").PromQL).To(Equal(`library_metric{source_id="<%= ERB REMOVED FOR YAML SAFETY %>"}`))
+	})
+
+	t.Run("returns an error if an override references an indicator that is not imported", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		dir := writeFiles(t, map[string]string{
+			"go_runtime.yml": goRuntimeLibrary,
+			"indicators.yml": `---
+apiVersion: indicatorprotocol.io/v1
+kind: IndicatorDocument
+
+spec:
+  product:
+    name: my-product
+    version: 0.0.1
+  imports:
+  - path: go_runtime.yml
+    thresholds:
+      go_threads:
+      - level: critical
+        operator: gt
+        value: 5000
+`,
+		})
+		defer os.RemoveAll(dir)
+
+		_, err := indicator.ReadFile(filepath.Join(dir, "indicators.yml"))
+		g.Expect(err).To(MatchError(ContainSubstring("imports[0].thresholds: go_runtime.yml does not define indicator go_threads")))
+	})
+
+	t.Run("returns an error if an imported indicator name is already defined", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		dir := writeFiles(t, map[string]string{
+			"go_runtime.yml": goRuntimeLibrary,
+			"other.yml": `---
+apiVersion: indicatorprotocol.io/v1
+kind: IndicatorDocument
+
+spec:
+  product:
+    name: other
+    version: 1.0.0
+  indicators:
+  - name: go_gc_duration
+    promql: other_gc_duration
+`,
+			"indicators.yml": `---
+apiVersion: indicatorprotocol.io/v1
+kind: IndicatorDocument
+
+spec:
+  product:
+    name: my-product
+    version: 0.0.1
+  imports:
+  - path: go_runtime.yml
+  - path: other.yml
+  indicators:
+  - name: go_goroutines
+    promql: my_goroutines
+`,
+		})
+		defer os.RemoveAll(dir)
+
+		_, err := indicator.ReadFile(filepath.Join(dir, "indicators.yml"))
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(err.Error()).To(ContainSubstring("imports[0]: indicator go_goroutines from go_runtime.yml is already defined by the document"))
+		g.Expect(err.Error()).To(ContainSubstring("imports[1]: indicator go_gc_duration from other.yml is already defined by imports[0] (go_runtime.yml)"))
+	})
+
+	t.Run("returns an error if imports form a cycle", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		document := func(name string, imported string) string {
+			return `---
+apiVersion: indicatorprotocol.io/v1
+kind: IndicatorDocument
+
+spec:
+  product:
+    name: ` + name + `
+    version: 1.0.0
+  imports:
+  - path: ` + imported + `
+`
+		}
+		dir := writeFiles(t, map[string]string{
+			"a.yml": document("a", "b.yml"),
+			"b.yml": document("b", "a.yml"),
+		})
+		defer os.RemoveAll(dir)
+
+		_, err := indicator.ReadFile(filepath.Join(dir, "a.yml"))
+		g.Expect(err).To(MatchError(ContainSubstring(
+			"imports[0]: b.yml: imports[0]: import cycle detected: " +
+				filepath.Join(dir, "a.yml") + " -> " + filepath.Join(dir, "b.yml") + " -> " + filepath.Join(dir, "a.yml"),
+		)))
+	})
+
+	t.Run("returns an error if imports cannot be resolved", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		reader := ioutil.NopCloser(strings.NewReader(`---
+apiVersion: indicatorprotocol.io/v1
+kind: IndicatorDocument
+
+spec:
+  product:
+    name: my-product
+    version: 0.0.1
+  imports:
+  - path: go_runtime.yml
+`))
+
+		_, errs := indicator.DocumentFromYAML(reader)
+		g.Expect(errs).To(HaveLen(1))
+		g.Expect(errs[0]).To(MatchError("imports[0]: imports cannot be resolved for this document"))
+	})
+
+	t.Run("does not resolve paths outside of the imports directory", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		dir := writeFiles(t, map[string]string{
+			"libraries/go_runtime.yml": goRuntimeLibrary,
+		})
+		defer os.RemoveAll(dir)
+
+		resolver := indicator.DirectoryImportResolver(filepath.Join(dir, "libraries"))
+
+		_, contents, err := resolver("", "go_runtime.yml")
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(string(contents)).To(Equal(goRuntimeLibrary))
+
+		_, _, err = resolver("", "../secrets.yml")
+		g.Expect(err).To(MatchError("../secrets.yml is outside of the imports directory"))
+	})
+}
@@ -0,0 +1,199 @@
+package indicator
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/pivotal/monitoring-indicator-protocol/pkg/api_versions"
+	v1 "github.com/pivotal/monitoring-indicator-protocol/pkg/k8s/apis/indicatordocument/v1"
+)
+
+var variableNameRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*`)
+
+// Variables that are replaced when the indicator is evaluated rather than when the document is read,
+// e.g. $step by the alert step of each threshold.
+var runtimeVariables = map[string]bool{
+	"step":   true,
+	"window": true,
+}
+
+// Interpolates the document's metadata labels, and the labels of the document importing it, into the
+// document. v2 documents, and v1 documents read with StrictInterpolation, are interpolated strictly, see
+// interpolateStrictly. Other v1 documents keep the compatible interpolation of earlier releases, which
+// replaces every `$label` in the raw YAML and leaves undefined variables as they are.
+func interpolate(docBytes []byte, inherited map[string]string, strict bool) ([]byte, []error) {
+	apiVersion, _ := ApiVersionFromYAML(docBytes)
+	if apiVersion != api_versions.V2 && !strict {
+		var err error
+		if inherited != nil {
+			docBytes = interpolateMetadata(docBytes, inherited)
+		}
+		docBytes, err = interpolateBytes(docBytes)
+		if err != nil {
+			return nil, []error{err}
+		}
+		return docBytes, nil
+	}
+
+	metadata, err := MetadataFromYAML(ioutil.NopCloser(bytes.NewReader(docBytes)))
+	if err != nil {
+		return nil, []error{fmt.Errorf("failed to parse metadata, %s", err)}
+	}
+	variables := make(map[string]string)
+	for k, v := range metadata {
+		variables[k] = v
+	}
+	for k, v := range inherited {
+		variables[k] = v
+	}
+
+	return interpolateStrictly(docBytes, variables)
+}
+
+// Interpolates the variables into the string values of the document, leaving its keys, comments and
+// metadata alone. References are written as `$name`, `${name}` or `${name:-default}`, and `$$` is a
+// literal `$`. Referencing an undefined variable without a default is an error. Template actions like
+// `{{ $value }}` in alert annotations are left for Prometheus to render.
+func interpolateStrictly(docBytes []byte, variables map[string]string) ([]byte, []error) {
+	var root yaml.Node
+	err := yaml.Unmarshal(docBytes, &root)
+	if err != nil {
+		return nil, []error{fmt.Errorf("could not unmarshal document for interpolation: %s", err)}
+	}
+
+	errs := interpolateNode(&root, "", variables)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	interpolated, err := yaml.Marshal(&root)
+	if err != nil {
+		return nil, []error{fmt.Errorf("could not marshal interpolated document: %s", err)}
+	}
+	return interpolated, nil
+}
+
+func interpolateNode(node *yaml.Node, path string, variables map[string]string) []error {
+	var es []error
+
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			es = append(es, interpolateNode(child, path, variables)...)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+			if path == "" && key == "metadata" {
+				continue
+			}
+			es = append(es, interpolateNode(node.Content[i+1], joinFieldPath(path, key), variables)...)
+		}
+	case yaml.SequenceNode:
+		for i, child := range node.Content {
+			es = append(es, interpolateNode(child, fmt.Sprintf("%s[%d]", path, i), variables)...)
+		}
+	case yaml.ScalarNode:
+		if node.ShortTag() != "!!str" || !strings.Contains(node.Value, "$") {
+			return nil
+		}
+
+		value, err := interpolateString(node.Value, variables)
+		if err != nil {
+			return []error{v1.NewValidationError(path, "%s %s", strings.TrimPrefix(path, "spec."), err)}
+		}
+
+		node.Value = value
+		// Unquoted values are resolved again, so that e.g. a threshold value can be a variable.
+		if node.Style == 0 {
+			node.Tag = ""
+		}
+	}
+
+	return es
+}
+
+func joinFieldPath(path string, field string) string {
+	if path == "" {
+		return field
+	}
+	return path + "." + field
+}
+
+func interpolateString(s string, variables map[string]string) (string, error) {
+	var interpolated strings.Builder
+
+	for i := 0; i < len(s); {
+		if strings.HasPrefix(s[i:], "{{") {
+			end := strings.Index(s[i:], "}}")
+			if end == -1 {
+				end = len(s) - i - 2
+			}
+			interpolated.WriteString(s[i : i+end+2])
+			i += end + 2
+			continue
+		}
+		if s[i] != '$' {
+			interpolated.WriteByte(s[i])
+			i++
+			continue
+		}
+
+		rest := s[i+1:]
+		switch {
+		case strings.HasPrefix(rest, "$"):
+			interpolated.WriteByte('$')
+			i += 2
+		case strings.HasPrefix(rest, "{"):
+			end := strings.IndexByte(rest, '}')
+			if end == -1 {
+				return "", fmt.Errorf("has an unterminated variable reference %s", s[i:])
+			}
+			reference := s[i : i+end+2]
+
+			name, defaultValue, hasDefault := rest[1:end], "", false
+			if idx := strings.Index(name, ":-"); idx != -1 {
+				name, defaultValue, hasDefault = name[:idx], name[idx+2:], true
+			}
+			if variableNameRegex.FindString(name) != name {
+				return "", fmt.Errorf("has an invalid variable reference %s", reference)
+			}
+
+			value, ok := variables[name]
+			switch {
+			case runtimeVariables[name]:
+				interpolated.WriteString(reference)
+			case ok && (value != "" || !hasDefault):
+				interpolated.WriteString(value)
+			case hasDefault:
+				interpolated.WriteString(defaultValue)
+			default:
+				return "", undefinedVariableError(name)
+			}
+			i += len(reference)
+		default:
+			name := variableNameRegex.FindString(rest)
+			value, ok := variables[name]
+			switch {
+			case name == "" || runtimeVariables[name]:
+				interpolated.WriteString("$" + name)
+			case ok:
+				interpolated.WriteString(value)
+			default:
+				return "", undefinedVariableError(name)
+			}
+			i += len(name) + 1
+		}
+	}
+
+	return interpolated.String(), nil
+}
+
+func undefinedVariableError(name string) error {
+	return fmt.Errorf("references undefined variable $%s, define it in metadata.labels, give it a default with ${%s:-default} or escape the $ as $$", name, name)
+}
@@ -0,0 +1,257 @@
+package indicator_test
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"text/template"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal/monitoring-indicator-protocol/pkg/indicator"
+	v1 "github.com/pivotal/monitoring-indicator-protocol/pkg/k8s/apis/indicatordocument/v1"
+)
+
+func TestStrictInterpolation(t *testing.T) {
+	document := func(indicators string) string {
+		return `---
+apiVersion: indicatorprotocol.io/v2
+kind: IndicatorDocument
+
+metadata:
+  labels:
+    deployment: cf
+    deployment_name: cf-abc
+    empty: ""
+
+spec:
+  product:
+    name: my-product
+    version: 0.0.1
+  indicators:
+` + indicators
+	}
+
+	t.Run("interpolates labels, defaults and escapes into string values", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		reader := ioutil.NopCloser(strings.NewReader(document(`
+  - name: latency
+    promql: 'latency{deployment="$deployment_name",source="${deployment}_api",az="${az:-z1}",env="${empty:-dev}"}'
+    title: Costs $$5 per ${deployment}
+    thresholds:
+    - level: critical
+      operator: gt
+      value: ${threshold:-500}
+`)))
+		doc, errs := indicator.DocumentFromYAML(reader)
+		g.Expect(errs).To(BeEmpty())
+
+		g.Expect(doc.Spec.Indicators[0].PromQL).To(Equal(`latency{deployment="cf-abc",source="cf_api",az="z1",env="dev"}`))
+		g.Expect(doc.Spec.Indicators[0].Documentation["title"]).To(Equal("Costs $5 per cf"))
+		g.Expect(doc.Spec.Indicators[0].Thresholds[0].Value).To(Equal(500.0))
+	})
+
+	t.Run("leaves runtime variables for later", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		reader := ioutil.NopCloser(strings.NewReader(document(`
+  - name: rate
+    promql: rate(requests[5m])
+    title: Requests per $step over ${window}
+`)))
+		doc, errs := indicator.DocumentFromYAML(reader)
+		g.Expect(errs).To(BeEmpty())
+
+		g.Expect(doc.Spec.Indicators[0].Documentation["title"]).To(Equal("Requests per $step over ${window}"))
+	})
+
+	t.Run("leaves template actions of alert annotations for Prometheus", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		reader := ioutil.NopCloser(strings.NewReader(document(`
+  - name: latency
+    promql: latency{deployment="$deployment"}
+    alert:
+      annotations:
+        summary: "{{ $value }} on {{ $labels.instance }} in $deployment"
+    thresholds:
+    - level: critical
+      operator: gt
+      value: 500
+`)))
+		doc, errs := indicator.DocumentFromYAML(reader)
+		g.Expect(errs).To(BeEmpty())
+
+		summary := doc.Spec.Indicators[0].Alert.Annotations["summary"]
+		g.Expect(summary).To(Equal("{{ $value }} on {{ $labels.instance }} in cf"))
+
+		// Prometheus defines $labels and $value before rendering annotations.
+		tmpl, err := template.New("summary").Parse("{{ $labels := .Labels }}{{ $value := .Value }}" + summary)
+		g.Expect(err).ToNot(HaveOccurred())
+		var rendered strings.Builder
+		err = tmpl.Execute(&rendered, map[string]interface{}{
+			"Labels": map[string]string{"instance": "api/0"},
+			"Value":  512.5,
+		})
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(rendered.String()).To(Equal("512.5 on api/0 in cf"))
+	})
+
+	t.Run("returns located errors for undefined and invalid references", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		reader := ioutil.NopCloser(strings.NewReader(document(`
+  - name: latency
+    promql: latency{deployment="$undefined"}
+  - name: errors
+    promql: errors * ${deployment
+  - name: requests
+    promql: requests{deployment="${not valid}"}
+`)))
+		_, errs := indicator.DocumentFromYAML(reader)
+
+		g.Expect(errs).To(ConsistOf(
+			&v1.ValidationError{
+				Path:    "spec.indicators[0].promql",
+				Message: "indicators[0].promql references undefined variable $undefined, define it in metadata.labels, give it a default with ${undefined:-default} or escape the $ as $$",
+				Line:    18,
+				Column:  5,
+			},
+			&v1.ValidationError{
+				Path:    "spec.indicators[1].promql",
+				Message: "indicators[1].promql has an unterminated variable reference ${deployment",
+				Line:    20,
+				Column:  5,
+			},
+			&v1.ValidationError{
+				Path:    "spec.indicators[2].promql",
+				Message: "indicators[2].promql has an invalid variable reference ${not valid}",
+				Line:    22,
+				Column:  5,
+			},
+		))
+	})
+
+	t.Run("interpolates the labels of the importing document into imported documents", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		dir := writeFiles(t, map[string]string{
+			"library.yml": `---
+apiVersion: indicatorprotocol.io/v2
+kind: IndicatorDocument
+
+metadata:
+  labels:
+    deployment: library
+
+spec:
+  product:
+    name: library
+    version: 1.0.0
+  indicators:
+  - name: library_indicator
+    promql: up{deployment="$deployment",source_id="$source_id"}
+`,
+			"indicators.yml": `---
+apiVersion: indicatorprotocol.io/v1
+kind: IndicatorDocument
+
+metadata:
+  labels:
+    source_id: my-source
+
+spec:
+  product:
+    name: my-product
+    version: 0.0.1
+  imports:
+  - path: library.yml
+`,
+		})
+		defer os.RemoveAll(dir)
+
+		doc, err := indicator.ReadFile(filepath.Join(dir, "indicators.yml"))
+		g.Expect(err).ToNot(HaveOccurred())
+
+		g.Expect(doc.Spec.Indicators[0].PromQL).To(Equal(`up{deployment="library",source_id="my-source"}`))
+	})
+
+	t.Run("v1 documents keep the compatible interpolation", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		reader := ioutil.NopCloser(strings.NewReader(`---
+apiVersion: indicatorprotocol.io/v1
+kind: IndicatorDocument
+
+metadata:
+  labels:
+    deployment: cf
+
+spec:
+  product:
+    name: my-product
+    version: 0.0.1
+  indicators:
+  - name: latency
+    promql: latency{deployment="$deployment_name",az="$undefined"}
+`))
+		doc, errs := indicator.DocumentFromYAML(reader)
+		g.Expect(errs).To(BeEmpty())
+
+		g.Expect(doc.Spec.Indicators[0].PromQL).To(Equal(`latency{deployment="$deployment_name",az="$undefined"}`))
+	})
+
+	t.Run("v1 documents only replace whole variable names", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		reader := ioutil.NopCloser(strings.NewReader(`---
+apiVersion: indicatorprotocol.io/v1
+kind: IndicatorDocument
+
+metadata:
+  labels:
+    deployment: cf
+    deployment_name: cf-abc
+
+spec:
+  product:
+    name: my-product
+    version: 0.0.1
+  indicators:
+  - name: latency
+    promql: latency{deployment="$deployment",name="$deployment_name",id="${deployment}-1"}
+`))
+		doc, errs := indicator.DocumentFromYAML(reader)
+		g.Expect(errs).To(BeEmpty())
+
+		g.Expect(doc.Spec.Indicators[0].PromQL).To(Equal(`latency{deployment="cf",name="cf-abc",id="cf-1"}`))
+	})
+
+	t.Run("v1 documents are interpolated strictly when opted in", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		v1Document := func(promql string) io.ReadCloser {
+			return ioutil.NopCloser(strings.NewReader(`---
+apiVersion: indicatorprotocol.io/v1
+kind: IndicatorDocument
+
+metadata:
+  labels:
+    deployment: cf
+
+spec:
+  product:
+    name: my-product
+    version: 0.0.1
+  indicators:
+  - name: latency
+    promql: ` + promql + `
+`))
+		}
+
+		doc, errs := indicator.DocumentFromYAML(v1Document(`latency{deployment="$deployment",price="$$5",az="${az:-z1}"}`), indicator.StrictInterpolation)
+		g.Expect(errs).To(BeEmpty())
+		g.Expect(doc.Spec.Indicators[0].PromQL).To(Equal(`latency{deployment="cf",price="$5",az="z1"}`))
+
+		_, errs = indicator.DocumentFromYAML(v1Document(`latency{az="$undefined"}`), indicator.StrictInterpolation)
+		g.Expect(errs).To(ConsistOf(
+			MatchError("indicators[0].promql references undefined variable $undefined, define it in metadata.labels, give it a default with ${undefined:-default} or escape the $ as $$"),
+		))
+	})
+}
@@ -2,12 +2,19 @@ package indicator
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"log"
 	"reflect"
+	"regexp"
+	"sort"
+	"strings"
 
+	"github.com/Masterminds/semver/v3"
 	"github.com/cppforlife/go-patch/patch"
+	"github.com/gobwas/glob"
 	"gopkg.in/yaml.v2"
 )
 
@@ -15,84 +22,407 @@ type Patch struct {
 	APIVersion string
 	Match      Match
 	Operations []patch.OpDefinition
+	// Origin describes where the patch was read from, e.g. its file name. It is only used in reports.
+	Origin string
+	// Source is the patch source the registry received the patch from. Patches from different sources
+	// writing the same document path are reported as conflicts.
+	Source string
+	// Patches are applied in ascending order of priority, so that the highest priority wins.
+	Priority int
 }
 
+// SortPatches orders patches by ascending priority, keeping the order of patches with the same priority.
+func SortPatches(patches []Patch) {
+	sort.SliceStable(patches, func(i, j int) bool {
+		return patches[i].Priority < patches[j].Priority
+	})
+}
+
+// Match selects the documents a patch applies to. Name, Version and Metadata have to equal the
+// document's product name, product version and metadata labels. The matchers are more flexible, see
+// ValueMatcher.
 type Match struct {
 	Name     *string
 	Version  *string
 	Metadata map[string]string
+
+	NameMatcher    *ValueMatcher
+	VersionMatcher *ValueMatcher
+	// Unlike Metadata, the document may have labels other than the matched ones.
+	Labels map[string]ValueMatcher
+}
+
+// ValueMatcher matches a value that equals Equals, or, if set, the regular expression, glob or
+// semver range instead. Regular expressions have to match the whole value.
+type ValueMatcher struct {
+	Equals string `json:"equals,omitempty"`
+	Regex  string `json:"regex,omitempty"`
+	Glob   string `json:"glob,omitempty"`
+	Range  string `json:"range,omitempty"`
+}
+
+// A plain string is shorthand for a matcher that checks equality.
+func (m *ValueMatcher) UnmarshalJSON(data []byte) error {
+	var equals string
+	if err := json.Unmarshal(data, &equals); err == nil {
+		*m = ValueMatcher{Equals: equals}
+		return nil
+	}
+
+	type valueMatcher ValueMatcher
+	var matcher valueMatcher
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&matcher); err != nil {
+		return fmt.Errorf("matchers must be a string or contain one of equals, regex, glob or range: %s", err)
+	}
+
+	*m = ValueMatcher(matcher)
+	return m.Validate()
+}
+
+func (m ValueMatcher) Validate() error {
+	set := 0
+	for _, v := range []string{m.Regex, m.Glob, m.Range} {
+		if v != "" {
+			set++
+		}
+	}
+	if set > 1 || (set == 1 && m.Equals != "") {
+		return errors.New("matchers can only contain one of equals, regex, glob or range")
+	}
+
+	var err error
+	switch {
+	case m.Regex != "":
+		_, err = regexp.Compile(m.Regex)
+	case m.Glob != "":
+		_, err = glob.Compile(m.Glob)
+	case m.Range != "":
+		_, err = semver.NewConstraint(m.Range)
+	}
+	return err
+}
+
+// Invalid matchers don't match any value.
+func (m ValueMatcher) Matches(value string) bool {
+	switch {
+	case m.Regex != "":
+		r, err := regexp.Compile("^(?:" + m.Regex + ")$")
+		return err == nil && r.MatchString(value)
+	case m.Glob != "":
+		g, err := glob.Compile(m.Glob)
+		return err == nil && g.Match(value)
+	case m.Range != "":
+		constraint, err := semver.NewConstraint(m.Range)
+		if err != nil {
+			return false
+		}
+		version, err := semver.NewVersion(value)
+		return err == nil && constraint.Check(version)
+	default:
+		return m.Equals == value
+	}
+}
+
+// String describes the matcher, e.g. regex "rabbitmq-.*".
+func (m ValueMatcher) String() string {
+	switch {
+	case m.Regex != "":
+		return fmt.Sprintf("regex %q", m.Regex)
+	case m.Glob != "":
+		return fmt.Sprintf("glob %q", m.Glob)
+	case m.Range != "":
+		return fmt.Sprintf("range %q", m.Range)
+	default:
+		return fmt.Sprintf("%q", m.Equals)
+	}
+}
+
+// PatchReport describes what applying patches did to a document, with one result per patch in the
+// order the patches were applied.
+type PatchReport struct {
+	Patches   []PatchResult   `json:"patches"`
+	Conflicts []PatchConflict `json:"conflicts,omitempty"`
+}
+
+// PatchConflict is a document path written by patches from different sources, in the order they were
+// applied. The last patch, i.e. the one with the highest priority, wins.
+type PatchConflict struct {
+	Path    string             `json:"path"`
+	Patches []ConflictingPatch `json:"patches"`
+}
+
+type ConflictingPatch struct {
+	Source   string `json:"source"`
+	Origin   string `json:"origin"`
+	Priority int    `json:"priority"`
+}
+
+// PatchResult describes a single patch. Reason explains why the patch did not match the document, and
+// Error why its operations could not be parsed, in which case none of them were applied.
+type PatchResult struct {
+	Origin     string            `json:"origin"`
+	Source     string            `json:"source,omitempty"`
+	Priority   int               `json:"priority"`
+	Matched    bool              `json:"matched"`
+	Reason     string            `json:"reason,omitempty"`
+	Error      string            `json:"error,omitempty"`
+	Operations []OperationResult `json:"operations,omitempty"`
+}
+
+type OperationResult struct {
+	Type    string `json:"type"`
+	Path    string `json:"path"`
+	Applied bool   `json:"applied"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Failed is true if any matching patch could not be parsed or any of its operations failed.
+func (r PatchReport) Failed() bool {
+	for _, p := range r.Patches {
+		if p.Error != "" {
+			return true
+		}
+		for _, o := range p.Operations {
+			if o.Error != "" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (r PatchReport) anyMatched() bool {
+	for _, p := range r.Patches {
+		if p.Matched {
+			return true
+		}
+	}
+	return false
 }
 
 func ApplyPatches(patches []Patch, documentBytes []byte) ([]byte, error) {
+	patched, _, err := ApplyPatchesWithReport(patches, documentBytes)
+	return patched, err
+}
+
+// ApplyPatchesWithReport applies the patches matching the document like ApplyPatches, and reports
+// which patches matched and which of their operations were applied. Operations that fail are skipped.
+func ApplyPatchesWithReport(patches []Patch, documentBytes []byte) ([]byte, PatchReport, error) {
+	report := PatchReport{Patches: make([]PatchResult, 0, len(patches))}
+
 	var document interface{}
 	err := yaml.Unmarshal(documentBytes, &document)
 	if err != nil {
-		return []byte{}, errors.New("failed to unmarshal document for patching")
+		return []byte{}, report, errors.New("failed to unmarshal document for patching")
 	}
 
-	for _, p := range patches {
-		if MatchDocument(p, documentBytes) {
-			ops, err := patch.NewOpsFromDefinitions(p.Operations)
+	var writes []patchWrite
+	for i, p := range patches {
+		result := PatchResult{Origin: p.Origin, Source: p.Source, Priority: p.Priority}
+		if result.Origin == "" {
+			result.Origin = fmt.Sprintf("patches[%d]", i)
+		}
+
+		result.Reason = mismatch(p, documentBytes)
+		result.Matched = result.Reason == ""
+		if !result.Matched {
+			report.Patches = append(report.Patches, result)
+			continue
+		}
+
+		for _, definition := range p.Operations {
+			result.Operations = append(result.Operations, OperationResult{
+				Type: definition.Type,
+				Path: stringValue(definition.Path),
+			})
+		}
+
+		ops, err := patch.NewOpsFromDefinitions(p.Operations)
+		if err != nil {
+			log.Printf("failed to parse patch operations of %s: %s", result.Origin, err)
+			result.Error = fmt.Sprintf("failed to parse patch operations: %s", err)
+			report.Patches = append(report.Patches, result)
+			continue
+		}
+
+		for j, o := range ops {
+			var tempDocument interface{}
+			tempDocument, err = o.Apply(document)
 			if err != nil {
-				log.Print(errors.New("failed to parse patch operations"))
+				log.Printf("failed to apply patch operation of %s: %s", result.Origin, err)
+				result.Operations[j].Error = err.Error()
 				continue
 			}
-			for _, o := range ops {
-				var tempDocument interface{}
-				tempDocument, err = o.Apply(document)
-				if err != nil {
-					log.Print(errors.New("failed to apply patch operation"))
-					continue
-				}
-				document = tempDocument
+			result.Operations[j].Applied = true
+			document = tempDocument
+
+			if writesPath(result.Operations[j]) {
+				writes = append(writes, patchWrite{
+					path:  normalizePatchPath(result.Operations[j].Path),
+					patch: ConflictingPatch{Source: result.Source, Origin: result.Origin, Priority: result.Priority},
+				})
 			}
 		}
+
+		report.Patches = append(report.Patches, result)
+	}
+
+	report.Conflicts = findConflicts(writes)
+	for _, c := range report.Conflicts {
+		log.Printf("patches from different sources write %s: %s", c.Path, describeConflictingPatches(c.Patches))
 	}
 
 	patched, err := yaml.Marshal(document)
 	if err != nil {
-		return []byte{}, errors.New("failed to marshal patch document")
+		return []byte{}, report, errors.New("failed to marshal patch document")
+	}
+	return patched, report, nil
+}
+
+type patchWrite struct {
+	path  string
+	patch ConflictingPatch
+}
+
+// Test operations don't change the document, and appending to an array doesn't overwrite anything.
+func writesPath(o OperationResult) bool {
+	return o.Type != "test" && !strings.HasSuffix(o.Path, "/-")
+}
+
+// Removes the markers of optional path segments, so that /spec?/labels and /spec/labels are the same path.
+func normalizePatchPath(path string) string {
+	return strings.Replace(path, "?", "", -1)
+}
+
+// Two writes conflict if they come from different sources and one path is, or contains, the other. The
+// conflict is reported at the path written first.
+func findConflicts(writes []patchWrite) []PatchConflict {
+	var paths []string
+	writesByPath := make(map[string][]bool)
+
+	for i, later := range writes {
+		for j, earlier := range writes[:i] {
+			if earlier.patch.Source == later.patch.Source || !pathsOverlap(earlier.path, later.path) {
+				continue
+			}
+
+			if _, ok := writesByPath[earlier.path]; !ok {
+				paths = append(paths, earlier.path)
+				writesByPath[earlier.path] = make([]bool, len(writes))
+			}
+			writesByPath[earlier.path][j] = true
+			writesByPath[earlier.path][i] = true
+		}
+	}
+
+	conflicts := make([]PatchConflict, 0, len(paths))
+	for _, path := range paths {
+		conflict := PatchConflict{Path: path}
+		for i, conflicting := range writesByPath[path] {
+			if conflicting {
+				conflict.Patches = appendPatchOnce(conflict.Patches, writes[i].patch)
+			}
+		}
+		conflicts = append(conflicts, conflict)
+	}
+
+	return conflicts
+}
+
+func pathsOverlap(a, b string) bool {
+	return a == b || strings.HasPrefix(a, b+"/") || strings.HasPrefix(b, a+"/")
+}
+
+func appendPatchOnce(patches []ConflictingPatch, p ConflictingPatch) []ConflictingPatch {
+	for _, existing := range patches {
+		if existing == p {
+			return patches
+		}
 	}
-	return patched, nil
+	return append(patches, p)
+}
+
+func describeConflictingPatches(patches []ConflictingPatch) string {
+	descriptions := make([]string, 0, len(patches))
+	for _, p := range patches {
+		descriptions = append(descriptions, fmt.Sprintf("%s from %s (priority %d)", p.Origin, p.Source, p.Priority))
+	}
+	return strings.Join(descriptions, ", ")
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
 }
 
 func MatchDocument(patch Patch, documentBytes []byte) bool {
+	return mismatch(patch, documentBytes) == ""
+}
+
+// Returns why the patch does not match the document, or an empty string if it does.
+func mismatch(patch Patch, documentBytes []byte) string {
 	reader := ioutil.NopCloser(bytes.NewReader(documentBytes))
 	product, err := ProductFromYAML(reader)
 	if err != nil {
-		return false
+		return fmt.Sprintf("could not read the product of the document: %s", err)
 	}
 
 	criteria := patch.Match
 	if criteria.Name != nil && *criteria.Name != product.Name {
-		return false
+		return fmt.Sprintf("product name %q does not equal %q", product.Name, *criteria.Name)
 	}
 	if criteria.Version != nil && *criteria.Version != product.Version {
-		return false
+		return fmt.Sprintf("product version %q does not equal %q", product.Version, *criteria.Version)
+	}
+	if criteria.NameMatcher != nil && !criteria.NameMatcher.Matches(product.Name) {
+		return fmt.Sprintf("product name %q does not match %s", product.Name, criteria.NameMatcher)
+	}
+	if criteria.VersionMatcher != nil && !criteria.VersionMatcher.Matches(product.Version) {
+		return fmt.Sprintf("product version %q does not match %s", product.Version, criteria.VersionMatcher)
 	}
 
-	if criteria.Metadata != nil {
+	if criteria.Metadata != nil || criteria.Labels != nil {
 		reader := ioutil.NopCloser(bytes.NewReader(documentBytes))
 		metadata, err := MetadataFromYAML(reader)
 		if err != nil {
-			return false
+			return fmt.Sprintf("could not read the metadata of the document: %s", err)
 		}
-		if !reflect.DeepEqual(metadata, criteria.Metadata) {
-			return false
+		if criteria.Metadata != nil && !reflect.DeepEqual(metadata, criteria.Metadata) {
+			return fmt.Sprintf("metadata %v does not equal %v", metadata, criteria.Metadata)
+		}
+
+		labels := make([]string, 0, len(criteria.Labels))
+		for label := range criteria.Labels {
+			labels = append(labels, label)
+		}
+		sort.Strings(labels)
+		for _, label := range labels {
+			matcher := criteria.Labels[label]
+			value, ok := metadata[label]
+			if !ok {
+				return fmt.Sprintf("label %s is missing", label)
+			}
+			if !matcher.Matches(value) {
+				return fmt.Sprintf("label %s value %q does not match %s", label, value, matcher)
+			}
 		}
 	}
 
 	apiVersion, err := ApiVersionFromYAML(documentBytes)
 	if err != nil {
 		log.Printf("Could not parse the apiVersion of a document")
-		return false
+		return fmt.Sprintf("could not read the apiVersion of the document: %s", err)
 	}
 	if !apiVersionMatches(patch.APIVersion, apiVersion) {
 		log.Printf("A patch apiVersion did not match document apiVersion")
-		return false
+		return fmt.Sprintf("apiVersion %q does not equal the document apiVersion %q", patch.APIVersion, apiVersion)
 	}
 
-	return true
+	return ""
 }
 
 func apiVersionMatches(patchVersion, docVersion string) bool {
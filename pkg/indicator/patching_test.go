@@ -165,6 +165,81 @@ spec:
 		g.Expect(indicator.MatchDocument(matchDeploymentTest, documentBytes)).To(BeFalse())
 		g.Expect(indicator.MatchDocument(matchDeploymentOtherTest, documentBytes)).To(BeTrue())
 	})
+
+	t.Run("matchers", func(t *testing.T) {
+		documentBytes := []byte(`---
+apiVersion: indicatorprotocol.io/v1
+kind: IndicatorDocument
+
+metadata:
+  labels:
+    deployment: cf-abc123
+    az: z1
+    source_id: router_0
+
+spec:
+  product:
+    name: cf-router
+    version: 1.4.2
+
+  indicators:
+  - name: test_indicator
+    promql: test_expr
+`)
+
+		matches := func(m indicator.Match) bool {
+			return indicator.MatchDocument(matchToPatch(api_versions.V1, m), documentBytes)
+		}
+
+		t.Run("matches a subset of labels", func(t *testing.T) {
+			g := NewGomegaWithT(t)
+
+			g.Expect(matches(indicator.Match{
+				Labels: map[string]indicator.ValueMatcher{"az": {Equals: "z1"}},
+			})).To(BeTrue())
+			g.Expect(matches(indicator.Match{
+				Labels: map[string]indicator.ValueMatcher{"az": {Equals: "z2"}},
+			})).To(BeFalse())
+			g.Expect(matches(indicator.Match{
+				Labels: map[string]indicator.ValueMatcher{"region": {Equals: ""}},
+			})).To(BeFalse())
+			g.Expect(matches(indicator.Match{
+				Metadata: map[string]string{"az": "z1"},
+			})).To(BeFalse())
+		})
+
+		t.Run("matches regular expressions and globs", func(t *testing.T) {
+			g := NewGomegaWithT(t)
+
+			g.Expect(matches(indicator.Match{
+				NameMatcher: &indicator.ValueMatcher{Glob: "cf-*"},
+				Labels: map[string]indicator.ValueMatcher{
+					"deployment": {Regex: "cf-[a-z0-9]+"},
+					"source_id":  {Glob: "router_*"},
+				},
+			})).To(BeTrue())
+			g.Expect(matches(indicator.Match{
+				Labels: map[string]indicator.ValueMatcher{"deployment": {Regex: "cf"}},
+			})).To(BeFalse())
+			g.Expect(matches(indicator.Match{
+				NameMatcher: &indicator.ValueMatcher{Glob: "p-*"},
+			})).To(BeFalse())
+		})
+
+		t.Run("matches semver ranges", func(t *testing.T) {
+			g := NewGomegaWithT(t)
+
+			g.Expect(matches(indicator.Match{
+				VersionMatcher: &indicator.ValueMatcher{Range: ">= 1.2, < 2"},
+			})).To(BeTrue())
+			g.Expect(matches(indicator.Match{
+				VersionMatcher: &indicator.ValueMatcher{Range: "~1.3"},
+			})).To(BeFalse())
+			g.Expect(matches(indicator.Match{
+				VersionMatcher: &indicator.ValueMatcher{Range: "not a range"},
+			})).To(BeFalse())
+		})
+	})
 }
 
 func TestPatching(t *testing.T) {
@@ -686,6 +761,114 @@ spec:
 			},
 		}))
 	})
+
+	t.Run("reports which patches matched and which operations were applied", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		var val interface{} = "patched_promql"
+		patches := []indicator.Patch{{
+			APIVersion: api_versions.V1,
+			Match:      indicator.Match{Name: test_fixtures.StrPtr("other-product")},
+			Origin:     "other.yml",
+		}, {
+			APIVersion: api_versions.V1,
+			Match:      indicator.Match{Labels: map[string]indicator.ValueMatcher{"deployment": {Glob: "test-*"}}},
+			Operations: []patch.OpDefinition{{
+				Type:  "replace",
+				Path:  test_fixtures.StrPtr("/spec/indicators/0/promql"),
+				Value: &val,
+			}, {
+				Type: "remove",
+				Path: test_fixtures.StrPtr("/spec/indicators/1"),
+			}},
+		}}
+
+		patchedBytes, report, err := indicator.ApplyPatchesWithReport(patches, v1DocumentBytes)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(string(patchedBytes)).To(ContainSubstring("patched_promql"))
+
+		g.Expect(report.Failed()).To(BeTrue())
+		g.Expect(report.Patches).To(HaveLen(2))
+		g.Expect(report.Patches[0]).To(Equal(indicator.PatchResult{
+			Origin:  "other.yml",
+			Matched: false,
+			Reason:  `product name "testing" does not equal "other-product"`,
+		}))
+		g.Expect(report.Patches[1].Origin).To(Equal("patches[1]"))
+		g.Expect(report.Patches[1].Matched).To(BeTrue())
+		g.Expect(report.Patches[1].Operations[0]).To(Equal(indicator.OperationResult{
+			Type:    "replace",
+			Path:    "/spec/indicators/0/promql",
+			Applied: true,
+		}))
+		g.Expect(report.Patches[1].Operations[1].Applied).To(BeFalse())
+		g.Expect(report.Patches[1].Operations[1].Error).To(ContainSubstring("Expected to find array index '1'"))
+	})
+
+	t.Run("reports patches from different sources writing the same path", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		var promql interface{} = "patched_promql"
+		var indicators interface{} = []interface{}{}
+		replacePromQL := []patch.OpDefinition{{
+			Type:  "replace",
+			Path:  test_fixtures.StrPtr("/spec/indicators/0/promql"),
+			Value: &promql,
+		}}
+		patches := []indicator.Patch{{
+			APIVersion: api_versions.V1,
+			Match:      v1Match,
+			Origin:     "a.yml",
+			Source:     "repo-a",
+			Operations: replacePromQL,
+		}, {
+			APIVersion: api_versions.V1,
+			Match:      v1Match,
+			Origin:     "b.yml",
+			Source:     "repo-a",
+			Operations: replacePromQL,
+		}, {
+			APIVersion: api_versions.V1,
+			Match:      v1Match,
+			Origin:     "c.yml",
+			Source:     "repo-b",
+			Priority:   10,
+			Operations: []patch.OpDefinition{{
+				Type:  "replace",
+				Path:  test_fixtures.StrPtr("/spec/indicators?"),
+				Value: &indicators,
+			}},
+		}}
+
+		_, report, err := indicator.ApplyPatchesWithReport(patches, v1DocumentBytes)
+		g.Expect(err).ToNot(HaveOccurred())
+
+		g.Expect(report.Conflicts).To(Equal([]indicator.PatchConflict{{
+			Path: "/spec/indicators/0/promql",
+			Patches: []indicator.ConflictingPatch{
+				{Source: "repo-a", Origin: "a.yml"},
+				{Source: "repo-a", Origin: "b.yml"},
+				{Source: "repo-b", Origin: "c.yml", Priority: 10},
+			},
+		}}))
+	})
+
+	t.Run("reports patches whose operations cannot be parsed", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		patches := []indicator.Patch{{
+			APIVersion: api_versions.V1,
+			Match:      v1Match,
+			Operations: []patch.OpDefinition{{Type: "unknown"}},
+		}}
+
+		_, report, err := indicator.ApplyPatchesWithReport(patches, v1DocumentBytes)
+		g.Expect(err).ToNot(HaveOccurred())
+
+		g.Expect(report.Failed()).To(BeTrue())
+		g.Expect(report.Patches[0].Error).To(ContainSubstring("failed to parse patch operations"))
+		g.Expect(report.Patches[0].Operations).To(ConsistOf(indicator.OperationResult{Type: "unknown"}))
+	})
 }
 
 func TestPatchingApiCompatibility(t *testing.T) {
@@ -0,0 +1,89 @@
+package indicator
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+
+	v1 "github.com/pivotal/monitoring-indicator-protocol/pkg/k8s/apis/indicatordocument/v1"
+)
+
+var pathSegmentRegex = regexp.MustCompile(`[^.\[\]]+|\[\d+\]`)
+
+// DescribeError formats validation errors with their position in the document, and any other error
+// as it is.
+func DescribeError(err error) string {
+	if e, ok := err.(*v1.ValidationError); ok {
+		if position := e.Position(); position != "" {
+			return fmt.Sprintf("%s: %s", position, e.Message)
+		}
+	}
+	return err.Error()
+}
+
+// Sets the file, line and column of the validation errors to the position of the field at their path
+// in the given YAML. Fields that are missing, e.g. required ones, are located at their closest parent.
+// Errors that have already been located, e.g. in an imported document, are left as they are.
+func locateErrors(errs []error, source []byte, file string) {
+	var root yaml.Node
+	parsed := source != nil && yaml.Unmarshal(source, &root) == nil
+
+	for _, err := range errs {
+		e, ok := err.(*v1.ValidationError)
+		if !ok || e.File != "" || e.Line != 0 {
+			continue
+		}
+
+		e.File = file
+		if parsed {
+			e.Line, e.Column = fieldPosition(&root, e.Path)
+		}
+	}
+}
+
+func fieldPosition(root *yaml.Node, path string) (int, int) {
+	node := resolveAlias(root)
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = resolveAlias(node.Content[0])
+	}
+	line, column := node.Line, node.Column
+
+	for _, segment := range pathSegmentRegex.FindAllString(path, -1) {
+		var next *yaml.Node
+		switch node.Kind {
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if node.Content[i].Value == segment {
+					line, column = node.Content[i].Line, node.Content[i].Column
+					next = node.Content[i+1]
+					break
+				}
+			}
+		case yaml.SequenceNode:
+			if segment[0] != '[' {
+				break
+			}
+			idx, err := strconv.Atoi(segment[1 : len(segment)-1])
+			if err == nil && idx < len(node.Content) {
+				next = node.Content[idx]
+				line, column = next.Line, next.Column
+			}
+		}
+
+		if next == nil {
+			break
+		}
+		node = resolveAlias(next)
+	}
+
+	return line, column
+}
+
+func resolveAlias(node *yaml.Node) *yaml.Node {
+	for node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+	return node
+}
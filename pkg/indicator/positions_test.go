@@ -0,0 +1,184 @@
+package indicator_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal/monitoring-indicator-protocol/pkg/indicator"
+	v1 "github.com/pivotal/monitoring-indicator-protocol/pkg/k8s/apis/indicatordocument/v1"
+)
+
+func TestErrorPositions(t *testing.T) {
+	t.Run("locates validation errors in the document", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		reader := ioutil.NopCloser(strings.NewReader(`---
+apiVersion: indicatorprotocol.io/v1
+kind: IndicatorDocument
+
+spec:
+  product:
+    name: my-product
+    version: 0.0.1
+  indicators:
+  - name: good_indicator
+    promql: up
+  - name: bad_indicator
+    promql: rate(
+    thresholds:
+    - level: critical
+      operator: gt
+      value: 1
+      lower: 0
+  layout:
+    sections:
+    - title: Section
+      indicators: [good_indicator, missing_indicator]
+`))
+		_, errs := indicator.DocumentFromYAML(reader)
+
+		g.Expect(errs).To(ConsistOf(
+			&v1.ValidationError{
+				Path:    "spec.indicators[1].promql",
+				Message: "indicators[1].promql should be valid promql (see https://prometheus.io/docs/)",
+				Line:    13,
+				Column:  5,
+			},
+			&v1.ValidationError{
+				Path:    "spec.indicators[1].thresholds[0]",
+				Message: "indicators[1].thresholds[0] lower and upper can only be used with the between and outside operators",
+				Line:    15,
+				Column:  7,
+			},
+			&v1.ValidationError{
+				Path:    "spec.layout.sections[0].indicators[1]",
+				Message: "layout sections[0] indicators[1] references a non-existent indicator",
+				Line:    22,
+				Column:  36,
+			},
+		))
+	})
+
+	t.Run("locates errors of missing fields at their parent", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		reader := ioutil.NopCloser(strings.NewReader(`---
+apiVersion: indicatorprotocol.io/v2
+kind: IndicatorDocument
+
+spec:
+  product:
+    name: my-product
+`))
+		_, errs := indicator.DocumentFromYAML(reader)
+
+		g.Expect(errs).To(ContainElement(&v1.ValidationError{
+			Path:    "spec.product.version",
+			Message: "IndicatorDocumentV2.spec.product.version in body is required",
+			Line:    6,
+			Column:  3,
+		}))
+	})
+
+	t.Run("locates errors of expanded indicators in their template", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		reader := ioutil.NopCloser(strings.NewReader(`---
+apiVersion: indicatorprotocol.io/v1
+kind: IndicatorDocument
+
+spec:
+  product:
+    name: my-product
+    version: 0.0.1
+  indicatorTemplates:
+  - indicator:
+      name: "{{job}}_latency"
+      promql: "{{query}}"
+    parameters:
+    - job: api
+      query: latency{job="api"
+`))
+		_, errs := indicator.DocumentFromYAML(reader)
+
+		g.Expect(errs).To(ConsistOf(&v1.ValidationError{
+			Path:    "spec.indicatorTemplates[0].indicator.promql",
+			Message: "indicatorTemplates[0].parameters[0]: indicators[0].promql should be valid promql (see https://prometheus.io/docs/)",
+			Line:    12,
+			Column:  7,
+		}))
+	})
+
+	t.Run("does not locate errors in patched documents", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		name := "my-product"
+		patch := indicator.Patch{
+			APIVersion: "indicatorprotocol.io/v1",
+			Match:      indicator.Match{Name: &name},
+		}
+
+		_, errs := indicator.ProcessDocument([]indicator.Patch{patch}, []byte(`---
+apiVersion: indicatorprotocol.io/v1
+kind: IndicatorDocument
+
+spec:
+  product:
+    name: my-product
+    version: 0.0.1
+  indicators:
+  - name: bad_indicator
+    promql: rate(
+`))
+
+		g.Expect(errs).To(ConsistOf(&v1.ValidationError{
+			Path:    "spec.indicators[0].promql",
+			Message: "indicators[0].promql should be valid promql (see https://prometheus.io/docs/)",
+		}))
+	})
+
+	t.Run("ReadFile describes errors with their file, line, column and path", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		dir := writeFiles(t, map[string]string{
+			"library.yml": `---
+apiVersion: indicatorprotocol.io/v1
+kind: IndicatorDocument
+
+spec:
+  product:
+    name: library
+    version: 1.0.0
+  indicators:
+  - name: library_indicator
+    promql: rate(
+`,
+			"indicators.yml": `---
+apiVersion: indicatorprotocol.io/v1
+kind: IndicatorDocument
+
+spec:
+  product:
+    name: my-product
+    version: 0.0.1
+  imports:
+  - path: library.yml
+`,
+		})
+		defer os.RemoveAll(dir)
+
+		_, err := indicator.ReadFile(filepath.Join(dir, "indicators.yml"))
+		g.Expect(err).To(MatchError("validation for indicator document failed:\n" +
+			"- " + filepath.Join(dir, "library.yml") + ":11:5 spec.indicators[0].promql: " +
+			"imports[0]: library.yml: indicators[0].promql should be valid promql (see https://prometheus.io/docs/)\n"))
+	})
+
+	t.Run("DescribeError leaves other errors as they are", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		g.Expect(indicator.DescribeError(errors.New("some error"))).To(Equal("some error"))
+		g.Expect(indicator.DescribeError(v1.NewValidationError("spec.product", "invalid product"))).
+			To(Equal("spec.product: invalid product"))
+	})
+}
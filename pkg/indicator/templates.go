@@ -0,0 +1,166 @@
+package indicator
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	v1 "github.com/pivotal/monitoring-indicator-protocol/pkg/k8s/apis/indicatordocument/v1"
+)
+
+var templateParameterRegex = regexp.MustCompile(`\{\{\s*([a-zA-Z_][a-zA-Z0-9_]*)\s*\}\}`)
+var indicatorErrorRegex = regexp.MustCompile(`^indicators\[(\d+)\]`)
+var indicatorPathRegex = regexp.MustCompile(`^spec\.indicators\[\d+\]`)
+
+// indicatorOrigin describes where an expanded or imported indicator came from. When fieldsInSource is
+// set, the fields of the indicator can be found below path in the document's source.
+type indicatorOrigin struct {
+	description    string
+	path           string
+	fieldsInSource bool
+}
+
+// Appends one indicator per template parameter set to the document's indicators and removes the
+// templates, so that documents are stored and served fully expanded. The returned origins are
+// keyed by the index of each expanded indicator.
+func expandIndicatorTemplates(doc *v1.IndicatorDocument) (map[int]indicatorOrigin, []error) {
+	origins := make(map[int]indicatorOrigin)
+	var es []error
+
+	for templateIdx, template := range doc.Spec.IndicatorTemplates {
+		path := fmt.Sprintf("spec.indicatorTemplates[%d]", templateIdx)
+		if len(template.Parameters) == 0 {
+			es = append(es, v1.NewValidationError(path+".parameters", "indicatorTemplates[%d].parameters must contain at least one parameter set", templateIdx))
+			continue
+		}
+
+		for parameterIdx, parameters := range template.Parameters {
+			origin := fmt.Sprintf("indicatorTemplates[%d].parameters[%d]", templateIdx, parameterIdx)
+
+			indicator, err := expandIndicator(template.Indicator, parameters)
+			if err != nil {
+				es = append(es, v1.NewValidationError(fmt.Sprintf("%s.parameters[%d]", path, parameterIdx), "%s: %s", origin, err))
+				continue
+			}
+
+			origins[len(doc.Spec.Indicators)] = indicatorOrigin{
+				description:    origin,
+				path:           path + ".indicator",
+				fieldsInSource: true,
+			}
+			doc.Spec.Indicators = append(doc.Spec.Indicators, indicator)
+		}
+	}
+
+	doc.Spec.IndicatorTemplates = nil
+
+	return origins, es
+}
+
+// Substitutes the parameters into every string value of the indicator. Working on the JSON
+// representation covers all fields, including the ones added to IndicatorSpec in the future.
+func expandIndicator(template v1.IndicatorSpec, parameters map[string]string) (v1.IndicatorSpec, error) {
+	templateBytes, err := json.Marshal(template)
+	if err != nil {
+		return v1.IndicatorSpec{}, err
+	}
+
+	undefined := undefinedParameters(templateBytes, parameters)
+	if len(undefined) > 0 {
+		return v1.IndicatorSpec{}, fmt.Errorf("parameters [%s] are used by the template but not defined", strings.Join(undefined, " "))
+	}
+
+	var fields interface{}
+	err = json.Unmarshal(templateBytes, &fields)
+	if err != nil {
+		return v1.IndicatorSpec{}, err
+	}
+	fields = substituteParameters(fields, parameters)
+
+	indicatorBytes, err := json.Marshal(fields)
+	if err != nil {
+		return v1.IndicatorSpec{}, err
+	}
+
+	var indicator v1.IndicatorSpec
+	err = json.Unmarshal(indicatorBytes, &indicator)
+	if err != nil {
+		return v1.IndicatorSpec{}, err
+	}
+
+	return indicator, nil
+}
+
+func undefinedParameters(templateBytes []byte, parameters map[string]string) []string {
+	seen := make(map[string]bool)
+	var undefined []string
+	for _, match := range templateParameterRegex.FindAllSubmatch(templateBytes, -1) {
+		name := string(match[1])
+		if _, ok := parameters[name]; !ok && !seen[name] {
+			undefined = append(undefined, name)
+		}
+		seen[name] = true
+	}
+	sort.Strings(undefined)
+	return undefined
+}
+
+func substituteParameters(value interface{}, parameters map[string]string) interface{} {
+	switch v := value.(type) {
+	case string:
+		return templateParameterRegex.ReplaceAllStringFunc(v, func(placeholder string) string {
+			return parameters[templateParameterRegex.FindStringSubmatch(placeholder)[1]]
+		})
+	case map[string]interface{}:
+		for k, field := range v {
+			v[k] = substituteParameters(field, parameters)
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = substituteParameters(item, parameters)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// Prefixes the errors of expanded or imported indicators with the template, parameter set or import
+// they came from, and points their paths at it.
+func attributeIndicatorErrors(errs []error, origins map[int]indicatorOrigin) []error {
+	if len(origins) == 0 {
+		return errs
+	}
+
+	attributed := make([]error, 0, len(errs))
+	for _, err := range errs {
+		matches := indicatorErrorRegex.FindStringSubmatch(err.Error())
+		if matches != nil {
+			idx, _ := strconv.Atoi(matches[1])
+			if origin, ok := origins[idx]; ok {
+				err = attributeError(err, origin)
+			}
+		}
+		attributed = append(attributed, err)
+	}
+
+	return attributed
+}
+
+func attributeError(err error, origin indicatorOrigin) error {
+	e, ok := err.(*v1.ValidationError)
+	if !ok {
+		return fmt.Errorf("%s: %s", origin.description, err)
+	}
+
+	path := origin.path
+	if origin.fieldsInSource {
+		path = indicatorPathRegex.ReplaceAllLiteralString(e.Path, origin.path)
+	}
+
+	return v1.NewValidationError(path, "%s: %s", origin.description, e.Message)
+}
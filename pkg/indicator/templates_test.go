@@ -0,0 +1,185 @@
+package indicator_test
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal/monitoring-indicator-protocol/pkg/indicator"
+	v1 "github.com/pivotal/monitoring-indicator-protocol/pkg/k8s/apis/indicatordocument/v1"
+	"github.com/pivotal/monitoring-indicator-protocol/test_fixtures"
+)
+
+func TestIndicatorTemplates(t *testing.T) {
+	t.Run("expands a template into one indicator per parameter set", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		reader := ioutil.NopCloser(strings.NewReader(`---
+apiVersion: indicatorprotocol.io/v1
+kind: IndicatorDocument
+
+metadata:
+  labels:
+    deployment: my-deployment
+
+spec:
+  product:
+    name: my-product
+    version: 0.0.1
+  indicators:
+  - name: doc_performance_indicator
+    promql: avg_over_time(demo_latency{source_id="doc"}[5m])
+  indicatorTemplates:
+  - indicator:
+      name: "{{job}}_latency"
+      promql: latency{job="{{ job }}",deployment="$deployment"}
+      documentation:
+        title: "{{title}} Latency"
+      thresholds:
+      - level: critical
+        operator: gt
+        value: 100
+        matchers:
+          job: "{{job}}"
+    parameters:
+    - job: router
+      title: Router
+    - job: api
+      title: API
+`))
+		doc, errs := indicator.DocumentFromYAML(reader)
+		g.Expect(errs).To(BeEmpty())
+
+		g.Expect(doc.Spec.IndicatorTemplates).To(BeNil())
+		g.Expect(doc.Spec.Indicators).To(HaveLen(3))
+		g.Expect(doc.Spec.Indicators[1]).To(Equal(v1.IndicatorSpec{
+			Name:   "router_latency",
+			PromQL: `latency{job="router",deployment="my-deployment"}`,
+			Thresholds: []v1.Threshold{{
+				Level:    "critical",
+				Operator: v1.GreaterThan,
+				Value:    100,
+				Matchers: map[string]string{"job": "router"},
+				Alert:    test_fixtures.DefaultAlert(),
+			}},
+			Presentation:  test_fixtures.DefaultPresentation(),
+			Documentation: map[string]string{"title": "Router Latency"},
+		}))
+		g.Expect(doc.Spec.Indicators[2].Name).To(Equal("api_latency"))
+		g.Expect(doc.Spec.Indicators[2].PromQL).To(Equal(`latency{job="api",deployment="my-deployment"}`))
+
+		g.Expect(doc.Spec.Layout.Sections[0].Indicators).To(Equal([]string{
+			"doc_performance_indicator",
+			"router_latency",
+			"api_latency",
+		}))
+	})
+
+	t.Run("expands templates in v2 documents", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		reader := ioutil.NopCloser(strings.NewReader(`---
+apiVersion: indicatorprotocol.io/v2
+kind: IndicatorDocument
+
+spec:
+  product:
+    name: my-product
+    version: 0.0.1
+  indicatorTemplates:
+  - indicator:
+      name: "{{job}}_latency"
+      promql: latency{job="{{job}}"}
+      title: "{{job}} latency"
+    parameters:
+    - job: router
+`))
+		doc, errs := indicator.DocumentFromYAML(reader)
+		g.Expect(errs).To(BeEmpty())
+
+		g.Expect(doc.Spec.Indicators).To(HaveLen(1))
+		g.Expect(doc.Spec.Indicators[0].Name).To(Equal("router_latency"))
+		g.Expect(doc.Spec.Indicators[0].Documentation).To(Equal(map[string]string{"title": "router latency"}))
+	})
+
+	t.Run("returns an error if a parameter set does not define a parameter", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		reader := ioutil.NopCloser(strings.NewReader(`---
+apiVersion: indicatorprotocol.io/v1
+kind: IndicatorDocument
+
+spec:
+  product:
+    name: my-product
+    version: 0.0.1
+  indicatorTemplates:
+  - indicator:
+      name: "{{job}}_latency"
+      promql: latency{job="{{job}}",az="{{az}}",instance="{{instance}}"}
+    parameters:
+    - job: router
+      az: z1
+      instance: "0"
+    - job: api
+`))
+		_, errs := indicator.DocumentFromYAML(reader)
+
+		g.Expect(errs).To(ConsistOf(
+			MatchError("indicatorTemplates[0].parameters[1]: parameters [az instance] are used by the template but not defined"),
+		))
+	})
+
+	t.Run("returns an error if a template has no parameter sets", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		reader := ioutil.NopCloser(strings.NewReader(`---
+apiVersion: indicatorprotocol.io/v1
+kind: IndicatorDocument
+
+spec:
+  product:
+    name: my-product
+    version: 0.0.1
+  indicatorTemplates:
+  - indicator:
+      name: latency
+      promql: latency
+    parameters: []
+`))
+		_, errs := indicator.DocumentFromYAML(reader)
+
+		g.Expect(errs).To(ConsistOf(
+			MatchError("indicatorTemplates[0].parameters must contain at least one parameter set"),
+		))
+	})
+
+	t.Run("attributes validation errors to the template and parameter set", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		reader := ioutil.NopCloser(strings.NewReader(`---
+apiVersion: indicatorprotocol.io/v1
+kind: IndicatorDocument
+
+spec:
+  product:
+    name: my-product
+    version: 0.0.1
+  indicators:
+  - name: broken_indicator
+    promql: rate(
+  indicatorTemplates:
+  - indicator:
+      name: "{{job}}_latency"
+      promql: "{{query}}"
+    parameters:
+    - job: router
+      query: latency{job="router"}
+    - job: api
+      query: latency{job="api"
+`))
+		_, errs := indicator.DocumentFromYAML(reader)
+
+		g.Expect(errs).To(ConsistOf(
+			MatchError("indicators[0].promql should be valid promql (see https://prometheus.io/docs/)"),
+			MatchError("indicatorTemplates[0].parameters[1]: indicators[2].promql should be valid promql (see https://prometheus.io/docs/)"),
+		))
+	})
+}
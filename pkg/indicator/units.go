@@ -0,0 +1,331 @@
+package indicator
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	v1 "github.com/pivotal/monitoring-indicator-protocol/pkg/k8s/apis/indicatordocument/v1"
+)
+
+var valueWithUnitRegex = regexp.MustCompile(`^([+-]?(?:\d+\.?\d*|\.\d+)(?:[eE][+-]?\d+)?)\s*([a-zA-Zµ%]+)$`)
+
+// A unit is factor/divisor of the base unit of its dimension, e.g. a millisecond is 1/1000 seconds.
+type unit struct {
+	dimension string
+	factor    float64
+	divisor   float64
+}
+
+const (
+	timeDimension  = "time"
+	bytesDimension = "bytes"
+	ratioDimension = "ratio"
+)
+
+// The units threshold values can be written in, e.g. `500ms`.
+var valueUnits = map[string]unit{
+	"ns":  {timeDimension, 1, 1e9},
+	"us":  {timeDimension, 1, 1e6},
+	"µs":  {timeDimension, 1, 1e6},
+	"ms":  {timeDimension, 1, 1e3},
+	"s":   {timeDimension, 1, 1},
+	"m":   {timeDimension, 60, 1},
+	"h":   {timeDimension, 60 * 60, 1},
+	"d":   {timeDimension, 24 * 60 * 60, 1},
+	"B":   {bytesDimension, 1, 1},
+	"KB":  {bytesDimension, 1e3, 1},
+	"MB":  {bytesDimension, 1e6, 1},
+	"GB":  {bytesDimension, 1e9, 1},
+	"TB":  {bytesDimension, 1e12, 1},
+	"KiB": {bytesDimension, 1 << 10, 1},
+	"MiB": {bytesDimension, 1 << 20, 1},
+	"GiB": {bytesDimension, 1 << 30, 1},
+	"TiB": {bytesDimension, 1 << 40, 1},
+	"%":   {ratioDimension, 1, 100},
+}
+
+// The presentation units that values with units can be converted to. These are the Grafana unit IDs
+// and, for time, their spelled out names.
+var presentationUnits = map[string]unit{
+	"ns":           valueUnits["ns"],
+	"nanoseconds":  valueUnits["ns"],
+	"µs":           valueUnits["µs"],
+	"us":           valueUnits["us"],
+	"microseconds": valueUnits["us"],
+	"ms":           valueUnits["ms"],
+	"milliseconds": valueUnits["ms"],
+	"s":            valueUnits["s"],
+	"seconds":      valueUnits["s"],
+	"m":            valueUnits["m"],
+	"minutes":      valueUnits["m"],
+	"h":            valueUnits["h"],
+	"hours":        valueUnits["h"],
+	"d":            valueUnits["d"],
+	"days":         valueUnits["d"],
+	"bytes":        valueUnits["B"],
+	"decbytes":     valueUnits["B"],
+	"kbytes":       valueUnits["KiB"],
+	"mbytes":       valueUnits["MiB"],
+	"gbytes":       valueUnits["GiB"],
+	"tbytes":       valueUnits["TiB"],
+	"deckbytes":    valueUnits["KB"],
+	"decmbytes":    valueUnits["MB"],
+	"decgbytes":    valueUnits["GB"],
+	"dectbytes":    valueUnits["TB"],
+	"percent":      valueUnits["%"],
+	"percentunit":  {ratioDimension, 1, 1},
+}
+
+var thresholdValueFields = []string{"value", "lower", "upper", "recovery"}
+
+type valueWithUnit struct {
+	magnitude float64
+	unit      string
+	literal   string
+}
+
+// A threshold value with a unit in the thresholds of an import. It can only be normalized once the
+// import is resolved, because the units of the imported indicator aren't known before.
+type importedValueWithUnit struct {
+	importIdx    int
+	indicator    string
+	thresholdIdx int
+	field        string
+	path         string
+	value        valueWithUnit
+}
+
+// Replaces threshold values written with a unit, e.g. `500ms`, `2GiB` or `90%`, by the value in the
+// indicator's presentation units, or those of the document's spec.defaults if the indicator has none.
+// Values can only be converted between units of the same dimension. Values in import thresholds are
+// replaced by their magnitude and returned, to be normalized when the import is resolved.
+func normalizeUnits(docBytes []byte) ([]byte, []importedValueWithUnit, []error) {
+	var root yaml.Node
+	err := yaml.Unmarshal(docBytes, &root)
+	if err != nil || len(root.Content) == 0 {
+		// Unmarshalling the document reports the error.
+		return docBytes, nil, nil
+	}
+
+	spec := mappingValue(root.Content[0], "spec")
+	n := &unitNormalizer{defaultUnits: presentationUnitsOf(mappingValue(spec, "defaults"))}
+	for i, indicator := range sequenceItems(mappingValue(spec, "indicators")) {
+		n.normalizeIndicator(indicator, fmt.Sprintf("spec.indicators[%d]", i))
+	}
+	for i, template := range sequenceItems(mappingValue(spec, "indicatorTemplates")) {
+		n.normalizeIndicator(mappingValue(template, "indicator"), fmt.Sprintf("spec.indicatorTemplates[%d].indicator", i))
+	}
+	for i, imp := range sequenceItems(mappingValue(spec, "imports")) {
+		n.collectImportedValues(i, mappingValue(imp, "thresholds"), fmt.Sprintf("spec.imports[%d].thresholds", i))
+	}
+
+	if len(n.errors) > 0 {
+		return nil, nil, n.errors
+	}
+	if !n.changed {
+		return docBytes, n.imported, nil
+	}
+
+	normalized, err := yaml.Marshal(&root)
+	if err != nil {
+		return nil, nil, []error{fmt.Errorf("could not marshal document with normalized units: %s", err)}
+	}
+	return normalized, n.imported, nil
+}
+
+type unitNormalizer struct {
+	defaultUnits string
+	changed      bool
+	imported     []importedValueWithUnit
+	errors       []error
+}
+
+func (n *unitNormalizer) normalizeIndicator(indicator *yaml.Node, path string) {
+	units := presentationUnitsOf(indicator)
+	if units == "" {
+		units = n.defaultUnits
+	}
+
+	for i, threshold := range sequenceItems(mappingValue(indicator, "thresholds")) {
+		for _, field := range thresholdValueFields {
+			node := mappingValue(threshold, field)
+			fieldPath := fmt.Sprintf("%s.thresholds[%d].%s", path, i, field)
+
+			value, ok, err := parseValueWithUnit(node)
+			if err != nil {
+				n.errors = append(n.errors, v1.NewValidationError(fieldPath, "%s %s", strings.TrimPrefix(fieldPath, "spec."), err))
+				continue
+			}
+			if !ok {
+				continue
+			}
+
+			normalized, err := convertToUnits(value, units)
+			if err != nil {
+				n.errors = append(n.errors, v1.NewValidationError(fieldPath, "%s %s", strings.TrimPrefix(fieldPath, "spec."), err))
+				continue
+			}
+			setFloat(node, normalized)
+			n.changed = true
+		}
+	}
+}
+
+func (n *unitNormalizer) collectImportedValues(importIdx int, thresholds *yaml.Node, path string) {
+	if thresholds == nil || thresholds.Kind != yaml.MappingNode {
+		return
+	}
+
+	for i := 0; i+1 < len(thresholds.Content); i += 2 {
+		name := thresholds.Content[i].Value
+		for thresholdIdx, threshold := range sequenceItems(thresholds.Content[i+1]) {
+			for _, field := range thresholdValueFields {
+				node := mappingValue(threshold, field)
+				fieldPath := fmt.Sprintf("%s.%s[%d].%s", path, name, thresholdIdx, field)
+
+				value, ok, err := parseValueWithUnit(node)
+				if err != nil {
+					n.errors = append(n.errors, v1.NewValidationError(fieldPath, "%s %s", strings.TrimPrefix(fieldPath, "spec."), err))
+					continue
+				}
+				if !ok {
+					continue
+				}
+
+				n.imported = append(n.imported, importedValueWithUnit{
+					importIdx:    importIdx,
+					indicator:    name,
+					thresholdIdx: thresholdIdx,
+					field:        field,
+					path:         fieldPath,
+					value:        value,
+				})
+				setFloat(node, value.magnitude)
+				n.changed = true
+			}
+		}
+	}
+}
+
+// Normalizes the values with units of an import's thresholds for the imported indicator. Imported
+// indicators without units of their own take the defaults of the importing document, as they do when
+// its spec.defaults are applied.
+func normalizeImportedUnits(importIdx int, indicator *v1.IndicatorSpec, defaults *v1.DocumentDefaults, values []importedValueWithUnit) []error {
+	units := indicator.Presentation.Units
+	if units == "" && defaults != nil {
+		units = defaults.Presentation.Units
+	}
+
+	var es []error
+	for _, v := range values {
+		if v.importIdx != importIdx || v.indicator != indicator.Name || v.thresholdIdx >= len(indicator.Thresholds) {
+			continue
+		}
+
+		normalized, err := convertToUnits(v.value, units)
+		if err != nil {
+			es = append(es, v1.NewValidationError(v.path, "%s %s", strings.TrimPrefix(v.path, "spec."), err))
+			continue
+		}
+		setThresholdField(&indicator.Thresholds[v.thresholdIdx], v.field, normalized)
+	}
+	return es
+}
+
+// Returns false if the node isn't a value with a unit. Plain numbers are left as they are, since they
+// are already in the indicator's units.
+func parseValueWithUnit(node *yaml.Node) (valueWithUnit, bool, error) {
+	if node == nil || node.Kind != yaml.ScalarNode || node.ShortTag() != "!!str" {
+		return valueWithUnit{}, false, nil
+	}
+
+	match := valueWithUnitRegex.FindStringSubmatch(strings.TrimSpace(node.Value))
+	if match == nil {
+		return valueWithUnit{}, false, nil
+	}
+	if _, ok := valueUnits[match[2]]; !ok {
+		return valueWithUnit{}, false, fmt.Errorf("has an unknown unit %s, supported units are %s", match[2], strings.Join(sortedUnitNames(valueUnits), ", "))
+	}
+
+	magnitude, err := strconv.ParseFloat(match[1], 64)
+	if err != nil {
+		return valueWithUnit{}, false, fmt.Errorf("has an invalid number %s", match[1])
+	}
+
+	return valueWithUnit{magnitude: magnitude, unit: match[2], literal: node.Value}, true, nil
+}
+
+func convertToUnits(value valueWithUnit, units string) (float64, error) {
+	from := valueUnits[value.unit]
+	if units == "" {
+		return 0, fmt.Errorf("is %s, but the indicator does not declare presentation.units to convert it to", value.literal)
+	}
+	to, ok := presentationUnits[units]
+	if !ok {
+		return 0, fmt.Errorf("is %s, but presentation.units %s is not a %s unit", value.literal, units, from.dimension)
+	}
+	if from.dimension != to.dimension {
+		return 0, fmt.Errorf("is %s, a %s value, but presentation.units %s is a %s unit", value.literal, from.dimension, units, to.dimension)
+	}
+
+	return (value.magnitude * from.factor * to.divisor) / (from.divisor * to.factor), nil
+}
+
+func presentationUnitsOf(node *yaml.Node) string {
+	if units := mappingValue(mappingValue(node, "presentation"), "units"); units != nil {
+		return units.Value
+	}
+	return ""
+}
+
+func setThresholdField(t *v1.Threshold, field string, value float64) {
+	switch field {
+	case "value":
+		t.Value = value
+	case "lower":
+		t.Lower = &value
+	case "upper":
+		t.Upper = &value
+	case "recovery":
+		t.Recovery = &value
+	}
+}
+
+func setFloat(node *yaml.Node, value float64) {
+	node.Value = strconv.FormatFloat(value, 'f', -1, 64)
+	node.Tag = "!!float"
+	node.Style = 0
+}
+
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func sequenceItems(node *yaml.Node) []*yaml.Node {
+	if node == nil || node.Kind != yaml.SequenceNode {
+		return nil
+	}
+	return node.Content
+}
+
+func sortedUnitNames(units map[string]unit) []string {
+	names := make([]string, 0, len(units))
+	for name := range units {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,327 @@
+package indicator_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal/monitoring-indicator-protocol/pkg/indicator"
+	v1 "github.com/pivotal/monitoring-indicator-protocol/pkg/k8s/apis/indicatordocument/v1"
+)
+
+func TestThresholdUnits(t *testing.T) {
+	document := func(apiVersion string, units string, thresholds string) string {
+		return `---
+apiVersion: ` + apiVersion + `
+kind: IndicatorDocument
+
+spec:
+  product:
+    name: my-product
+    version: 1.0.0
+  indicators:
+  - name: my_indicator
+    promql: my_metric
+    presentation:
+      units: ` + units + `
+    thresholds:
+` + thresholds
+	}
+	read := func(doc string) (v1.IndicatorDocument, []error) {
+		return indicator.DocumentFromYAML(ioutil.NopCloser(strings.NewReader(doc)))
+	}
+
+	t.Run("normalizes values with units to the indicator's units", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		for _, apiVersion := range []string{"indicatorprotocol.io/v1", "indicatorprotocol.io/v2"} {
+			doc, errs := read(document(apiVersion, "seconds", `
+    - level: warning
+      operator: gt
+      value: 500ms
+    - level: critical
+      operator: gt
+      value: 2m
+      recovery: 90s
+`))
+			g.Expect(errs).To(BeEmpty())
+
+			thresholds := doc.Spec.Indicators[0].Thresholds
+			g.Expect(thresholds[0].Value).To(Equal(0.5))
+			g.Expect(thresholds[1].Value).To(Equal(120.0))
+			g.Expect(*thresholds[1].Recovery).To(Equal(90.0))
+		}
+	})
+
+	t.Run("converts between units of the same dimension", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		cases := []struct {
+			units    string
+			value    string
+			expected float64
+		}{
+			{"ms", "1.5s", 1500},
+			{"ns", "2µs", 2000},
+			{"bytes", "2GiB", 2 * 1024 * 1024 * 1024},
+			{"decbytes", "3KB", 3000},
+			{"mbytes", "1GiB", 1024},
+			{"percent", "90%", 90},
+			{"percentunit", "90%", 0.9},
+		}
+		for _, c := range cases {
+			doc, errs := read(document("indicatorprotocol.io/v1", c.units, `
+    - level: warning
+      operator: gt
+      value: `+c.value+`
+`))
+			g.Expect(errs).To(BeEmpty(), c.value)
+			g.Expect(doc.Spec.Indicators[0].Thresholds[0].Value).To(Equal(c.expected), c.value)
+		}
+	})
+
+	t.Run("normalizes the bounds of range thresholds", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		doc, errs := read(document("indicatorprotocol.io/v2", "percentunit", `
+    - level: warning
+      operator: outside
+      lower: 10%
+      upper: 0.95
+`))
+		g.Expect(errs).To(BeEmpty())
+
+		threshold := doc.Spec.Indicators[0].Thresholds[0]
+		g.Expect(*threshold.Lower).To(Equal(0.1))
+		g.Expect(*threshold.Upper).To(Equal(0.95))
+	})
+
+	t.Run("returns errors for values that can't be normalized", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		_, errs := read(document("indicatorprotocol.io/v1", "ms", `
+    - level: warning
+      operator: gt
+      value: 2GiB
+    - level: critical
+      operator: gt
+      value: 5 parsecs
+`))
+		g.Expect(errs).To(ConsistOf(
+			MatchError("indicators[0].thresholds[0].value is 2GiB, a bytes value, but presentation.units ms is a time unit"),
+			MatchError("indicators[0].thresholds[1].value has an unknown unit parsecs, supported units are %, B, GB, GiB, KB, KiB, MB, MiB, TB, TiB, d, h, m, ms, ns, s, us, µs"),
+		))
+		g.Expect(errs[0].(*v1.ValidationError).Line).To(Equal(18))
+
+		_, errs = read(document("indicatorprotocol.io/v1", "short", `
+    - level: warning
+      operator: gt
+      value: 90%
+`))
+		g.Expect(errs).To(ConsistOf(
+			MatchError("indicators[0].thresholds[0].value is 90%, but presentation.units short is not a ratio unit"),
+		))
+	})
+
+	t.Run("returns an error if the indicator has no units", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		_, errs := read(`---
+apiVersion: indicatorprotocol.io/v1
+kind: IndicatorDocument
+
+spec:
+  product:
+    name: my-product
+    version: 1.0.0
+  indicators:
+  - name: my_indicator
+    promql: my_metric
+    thresholds:
+    - level: warning
+      operator: gt
+      value: 500ms
+`)
+		g.Expect(errs).To(ConsistOf(
+			MatchError("indicators[0].thresholds[0].value is 500ms, but the indicator does not declare presentation.units to convert it to"),
+		))
+	})
+
+	t.Run("normalizes values to the units of the document's defaults", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		for _, apiVersion := range []string{"indicatorprotocol.io/v1", "indicatorprotocol.io/v2"} {
+			doc, errs := read(`---
+apiVersion: ` + apiVersion + `
+kind: IndicatorDocument
+
+spec:
+  product:
+    name: my-product
+    version: 1.0.0
+  defaults:
+    presentation:
+      units: ms
+  indicators:
+  - name: my_indicator
+    promql: my_metric
+    thresholds:
+    - level: warning
+      operator: gt
+      value: 2s
+  - name: other_indicator
+    promql: other_metric
+    presentation:
+      units: seconds
+    thresholds:
+    - level: warning
+      operator: gt
+      value: 2s
+`)
+			g.Expect(errs).To(BeEmpty(), apiVersion)
+			g.Expect(doc.Spec.Indicators[0].Presentation.Units).To(Equal("ms"))
+			g.Expect(doc.Spec.Indicators[0].Thresholds[0].Value).To(Equal(2000.0), apiVersion)
+			g.Expect(doc.Spec.Indicators[1].Thresholds[0].Value).To(Equal(2.0), apiVersion)
+		}
+	})
+
+	t.Run("normalizes the thresholds of indicator templates", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		doc, errs := read(`---
+apiVersion: indicatorprotocol.io/v1
+kind: IndicatorDocument
+
+spec:
+  product:
+    name: my-product
+    version: 1.0.0
+  indicatorTemplates:
+  - parameters:
+    - job: api
+    indicator:
+      name: $job_latency
+      promql: latency{job="$job"}
+      presentation:
+        units: ms
+      thresholds:
+      - level: warning
+        operator: gt
+        value: 1.5s
+`)
+		g.Expect(errs).To(BeEmpty())
+		g.Expect(doc.Spec.Indicators[0].Thresholds[0].Value).To(Equal(1500.0))
+	})
+
+	t.Run("normalizes threshold overrides of imports to the imported indicator's units", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		dir := writeFiles(t, map[string]string{
+			"library.yml": document("indicatorprotocol.io/v1", "ms", `
+    - level: warning
+      operator: gt
+      value: 1s
+`),
+			"indicators.yml": `---
+apiVersion: indicatorprotocol.io/v1
+kind: IndicatorDocument
+
+spec:
+  product:
+    name: other-product
+    version: 1.0.0
+  imports:
+  - path: library.yml
+    thresholds:
+      my_indicator:
+      - level: critical
+        operator: gt
+        value: 2s
+`,
+			"invalid.yml": `---
+apiVersion: indicatorprotocol.io/v1
+kind: IndicatorDocument
+
+spec:
+  product:
+    name: other-product
+    version: 1.0.0
+  imports:
+  - path: library.yml
+    thresholds:
+      my_indicator:
+      - level: critical
+        operator: gt
+        value: 95%
+`,
+		})
+		defer os.RemoveAll(dir)
+
+		doc, err := indicator.ReadFile(filepath.Join(dir, "indicators.yml"))
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(doc.Spec.Indicators[0].Thresholds).To(HaveLen(1))
+		g.Expect(doc.Spec.Indicators[0].Thresholds[0].Value).To(Equal(2000.0))
+
+		_, err = indicator.ReadFile(filepath.Join(dir, "invalid.yml"))
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(err.Error()).To(ContainSubstring("imports[0].thresholds.my_indicator[0].value is 95%, a ratio value, but presentation.units ms is a time unit"))
+	})
+
+	t.Run("normalizes threshold overrides of imports to the units of the importing document's defaults", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		dir := writeFiles(t, map[string]string{
+			"library.yml": `---
+apiVersion: indicatorprotocol.io/v1
+kind: IndicatorDocument
+
+spec:
+  product:
+    name: my-product
+    version: 1.0.0
+  indicators:
+  - name: my_indicator
+    promql: my_metric
+`,
+			"indicators.yml": `---
+apiVersion: indicatorprotocol.io/v1
+kind: IndicatorDocument
+
+spec:
+  product:
+    name: other-product
+    version: 1.0.0
+  defaults:
+    presentation:
+      units: ms
+  imports:
+  - path: library.yml
+    thresholds:
+      my_indicator:
+      - level: critical
+        operator: gt
+        value: 2s
+`,
+		})
+		defer os.RemoveAll(dir)
+
+		doc, err := indicator.ReadFile(filepath.Join(dir, "indicators.yml"))
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(doc.Spec.Indicators[0].Presentation.Units).To(Equal("ms"))
+		g.Expect(doc.Spec.Indicators[0].Thresholds[0].Value).To(Equal(2000.0))
+	})
+
+	t.Run("leaves plain numbers as they are", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		doc, errs := read(document("indicatorprotocol.io/v1", "ms", `
+    - level: warning
+      operator: gt
+      value: 500
+`))
+		g.Expect(errs).To(BeEmpty())
+		g.Expect(doc.Spec.Indicators[0].Thresholds[0].Value).To(Equal(500.0))
+	})
+}
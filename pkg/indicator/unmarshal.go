@@ -15,6 +15,7 @@ import (
 	"github.com/ghodss/yaml"
 
 	"github.com/pivotal/monitoring-indicator-protocol/pkg/api_versions"
+	v2 "github.com/pivotal/monitoring-indicator-protocol/pkg/indicator/v2"
 	v1 "github.com/pivotal/monitoring-indicator-protocol/pkg/k8s/apis/indicatordocument/v1"
 )
 
@@ -27,6 +28,15 @@ func DocumentFromYAML(r io.ReadCloser, opts ...ReadOpt) (v1.IndicatorDocument, [
 	}
 
 	readOptions := getReadOpts(opts)
+	source := docBytes
+	if readOptions.sourceOverridden {
+		source = readOptions.source
+	}
+	located := func(errs []error) []error {
+		locateErrors(errs, source, readOptions.location)
+		return errs
+	}
+
 	if len(readOptions.overrides) > 0 {
 		docBytes, err = overrideMetadataBytes(docBytes, readOptions.overrides)
 		if err != nil {
@@ -34,12 +44,19 @@ func DocumentFromYAML(r io.ReadCloser, opts ...ReadOpt) (v1.IndicatorDocument, [
 		}
 	}
 	if readOptions.interpolate {
-		docBytes, err = interpolateBytes(docBytes)
-		if err != nil {
-			return v1.IndicatorDocument{}, []error{err}
+		var errs []error
+		docBytes, errs = interpolate(docBytes, readOptions.inheritedMetadata, readOptions.strictInterpolation)
+		if len(errs) > 0 {
+			return v1.IndicatorDocument{}, located(errs)
 		}
 	}
 
+	docBytes, importedUnits, errs := normalizeUnits(docBytes)
+	if len(errs) > 0 {
+		return v1.IndicatorDocument{}, located(errs)
+	}
+	readOptions.importedUnits = importedUnits
+
 	apiVersion, err := ApiVersionFromYAML(docBytes)
 	if err != nil {
 		return v1.IndicatorDocument{}, []error{err}
@@ -49,24 +66,79 @@ func DocumentFromYAML(r io.ReadCloser, opts ...ReadOpt) (v1.IndicatorDocument, [
 	switch apiVersion {
 	case api_versions.V1:
 		err = yaml.Unmarshal(docBytes, &doc)
+	case api_versions.V2:
+		var errs []error
+		doc, errs = documentFromV2YAML(docBytes)
+		if len(errs) > 0 {
+			return v1.IndicatorDocument{}, located(errs)
+		}
 	default:
-		err = fmt.Errorf("invalid apiVersion, supported versions are: [indicatorprotocol.io/v1]")
+		err = fmt.Errorf("invalid apiVersion, supported versions are: [%s %s]", api_versions.V1, api_versions.V2)
 	}
 
 	if err != nil {
 		return v1.IndicatorDocument{}, []error{err}
 	}
 
-	v1.PopulateDefaults(&doc)
+	origins, errs := expandIndicatorTemplates(&doc)
+	if len(errs) > 0 {
+		return v1.IndicatorDocument{}, located(errs)
+	}
+
+	importOrigins, errs := resolveImports(&doc, readOptions)
+	if len(errs) > 0 {
+		return v1.IndicatorDocument{}, located(errs)
+	}
+	for idx, origin := range importOrigins {
+		origins[idx] = origin
+	}
 
-	validationErrors := doc.Validate()
+	validated := &doc
+	if len(readOptions.importedBy) > 0 {
+		// Imported indicators only take the defaults of their own document here. The defaults of the
+		// importing document, and then the built-in ones, apply once they are part of it.
+		if doc.Spec.Defaults != nil {
+			for i := range doc.Spec.Indicators {
+				doc.Spec.Defaults.ApplyTo(&doc.Spec.Indicators[i])
+			}
+		}
+		validated = doc.DeepCopy()
+	}
+	v1.PopulateDefaults(validated)
+
+	validationErrors, warnings := validated.ValidateWithWarnings()
+	if readOptions.reportWarning != nil {
+		for _, w := range located(attributeIndicatorErrors(warnings, origins)) {
+			readOptions.reportWarning(w)
+		}
+	}
 	if len(validationErrors) > 0 {
-		return v1.IndicatorDocument{}, validationErrors
+		return v1.IndicatorDocument{}, located(attributeIndicatorErrors(validationErrors, origins))
 	}
 
 	return doc, []error{}
 }
 
+// Validates the given bytes against the v2 schema before converting them to the v1 model, so
+// that schema errors refer to the fields the author actually wrote.
+func documentFromV2YAML(docBytes []byte) (v1.IndicatorDocument, []error) {
+	errs, valid := v1.ValidateBytesBySchema(docBytes, "IndicatorDocumentV2")
+	if !valid {
+		return v1.IndicatorDocument{}, errs
+	}
+
+	var doc v2.IndicatorDocument
+	err := yaml.Unmarshal(docBytes, &doc)
+	if err != nil {
+		return v1.IndicatorDocument{}, []error{err}
+	}
+	if errs := doc.Validate(); len(errs) > 0 {
+		return v1.IndicatorDocument{}, errs
+	}
+
+	return v2.ToV1(doc), nil
+}
+
 // Assuming the given bytes are yaml, upserts the given key/value pairs into the `metadata.labels` of the given
 // yaml.
 func overrideMetadataBytes(docBytes []byte, overrides map[string]string) ([]byte, error) {
@@ -110,7 +182,7 @@ func writeMetadataToYaml(docBytes []byte, metadata map[string]string) ([]byte, e
 		return nil, err
 	}
 	switch apiVersion {
-	case api_versions.V1:
+	case api_versions.V1, api_versions.V2:
 		var docMap map[string]interface{}
 		err := yaml.Unmarshal(docBytes, &docMap)
 		if err != nil {
@@ -139,13 +211,18 @@ func interpolateBytes(docBytes []byte) ([]byte, error) {
 		return []byte{}, fmt.Errorf("failed to parse metadata, %s", err)
 	}
 
+	return interpolateMetadata(docBytes, metadata), nil
+}
+
+// Replaces `$key` and `${key}` by the value of each metadata key. `$key` only matches whole names, so
+// `$deployment_name` is left alone by a `deployment` key.
+func interpolateMetadata(docBytes []byte, metadata map[string]string) []byte {
 	for key, value := range metadata {
-		regString := fmt.Sprintf(`(\$%s)(\b|\_|$)|(\$\{%s\})`, key, key)
-		regex := regexp.MustCompile(regString)
-		docBytes = regex.ReplaceAll(docBytes, []byte(fmt.Sprintf("%s$2", value)))
+		regex := regexp.MustCompile(fmt.Sprintf(`\$%s\b|\$\{%s\}`, regexp.QuoteMeta(key), regexp.QuoteMeta(key)))
+		docBytes = regex.ReplaceAllLiteral(docBytes, []byte(value))
 	}
 
-	return docBytes, nil
+	return docBytes
 }
 
 func ApiVersionFromYAML(docBytes []byte) (string, error) {
@@ -184,12 +261,9 @@ func PatchFromYAML(reader io.ReadCloser) (Patch, error) {
 
 	return Patch{
 		APIVersion: yamlPatch.APIVersion,
-		Match: Match{
-			Name:     yamlPatch.Match.Product.Name,
-			Version:  yamlPatch.Match.Product.Version,
-			Metadata: yamlPatch.Match.Metadata,
-		},
+		Match:      yamlPatch.Match.toMatch(),
 		Operations: yamlPatch.Operations,
+		Priority:   yamlPatch.Priority,
 	}, nil
 }
 
@@ -203,7 +277,7 @@ func ProductFromYAML(reader io.ReadCloser) (v1.Product, error) {
 	apiVersion, err := ApiVersionFromYAML(docBytes)
 	var product v1.Product
 	switch apiVersion {
-	case api_versions.V1:
+	case api_versions.V1, api_versions.V2:
 		var d struct {
 			Spec struct {
 				Product v1.Product
@@ -233,7 +307,7 @@ func MetadataFromYAML(reader io.ReadCloser) (map[string]string, error) {
 	}
 	var metadata map[string]string
 	switch apiVersion {
-	case api_versions.V1:
+	case api_versions.V1, api_versions.V2:
 		var d struct {
 			Metadata struct {
 				Labels map[string]string
@@ -255,20 +329,52 @@ type yamlPatch struct {
 	APIVersion string               `yaml:"apiVersion"`
 	Match      yamlMatch            `yaml:"match"`
 	Operations []patch.OpDefinition `yaml:"operations"`
+	Priority   int                  `yaml:"priority"`
 }
 
 type yamlMatch struct {
 	Product struct {
-		Name    *string `yaml:"name,omitempty"`
-		Version *string `yaml:"version,omitempty"`
+		Name    *ValueMatcher `yaml:"name,omitempty"`
+		Version *ValueMatcher `yaml:"version,omitempty"`
 	} `yaml:"product,omitempty"`
-	Metadata map[string]string `yaml:"metadata,omitempty"`
+	Metadata map[string]string       `yaml:"metadata,omitempty"`
+	Labels   map[string]ValueMatcher `yaml:"labels,omitempty"`
+}
+
+// Product names and versions given as plain strings keep matching exactly, through Name and Version.
+func (m yamlMatch) toMatch() Match {
+	match := Match{
+		Metadata: m.Metadata,
+		Labels:   m.Labels,
+	}
+
+	if name := m.Product.Name; name != nil {
+		if *name == (ValueMatcher{Equals: name.Equals}) {
+			match.Name = &name.Equals
+		} else {
+			match.NameMatcher = name
+		}
+	}
+	if version := m.Product.Version; version != nil {
+		if *version == (ValueMatcher{Equals: version.Equals}) {
+			match.Version = &version.Equals
+		} else {
+			match.VersionMatcher = version
+		}
+	}
+
+	return match
 }
 
 func SkipMetadataInterpolation(options *readOptions) {
 	options.interpolate = false
 }
 
+// StrictInterpolation interpolates v1 documents the way v2 documents are, see interpolateStrictly.
+func StrictInterpolation(options *readOptions) {
+	options.strictInterpolation = true
+}
+
 func OverrideMetadata(overrideMetadata map[string]string) func(options *readOptions) {
 	return func(options *readOptions) {
 		for k, v := range overrideMetadata {
@@ -277,21 +383,41 @@ func OverrideMetadata(overrideMetadata map[string]string) func(options *readOpti
 	}
 }
 
-func ProcessDocument(patches []Patch, documentBytes []byte) (v1.IndicatorDocument, []error) {
-	patchedDocBytes, err := ApplyPatches(patches, documentBytes)
+// ReportWarnings calls the given function with every problem found by the PromQL lint rules that
+// doesn't make the document invalid.
+func ReportWarnings(report func(warning error)) ReadOpt {
+	return func(options *readOptions) {
+		options.reportWarning = report
+	}
+}
+
+func ProcessDocument(patches []Patch, documentBytes []byte, opts ...ReadOpt) (v1.IndicatorDocument, []error) {
+	doc, _, errs := ProcessDocumentWithReport(patches, documentBytes, opts...)
+	return doc, errs
+}
+
+// ProcessDocumentWithReport is ProcessDocument, but also reports what the patches did to the document.
+func ProcessDocumentWithReport(patches []Patch, documentBytes []byte, opts ...ReadOpt) (v1.IndicatorDocument, PatchReport, []error) {
+	patchedDocBytes, report, err := ApplyPatchesWithReport(patches, documentBytes)
 	if err != nil {
 		log.Print("failed to apply patches to document")
-		return v1.IndicatorDocument{}, []error{err}
+		return v1.IndicatorDocument{}, report, []error{err}
+	}
+
+	// Errors can only be located in the document as it was written if no patch changed it.
+	var source []byte
+	if !report.anyMatched() {
+		source = documentBytes
 	}
 
 	reader := ioutil.NopCloser(bytes.NewReader(patchedDocBytes))
-	doc, errs := DocumentFromYAML(reader)
+	doc, errs := DocumentFromYAML(reader, append(opts, locateErrorsIn(source))...)
 	if len(errs) > 0 {
 		log.Print("failed to unmarshal document")
-		return v1.IndicatorDocument{}, errs
+		return v1.IndicatorDocument{}, report, errs
 	}
 
-	return doc, nil
+	return doc, report, nil
 }
 
 func ParseMetadata(input string) map[string]string {
@@ -308,6 +434,29 @@ func ParseMetadata(input string) map[string]string {
 }
 
 type readOptions struct {
-	interpolate bool
-	overrides   map[string]string
+	interpolate    bool
+	overrides      map[string]string
+	importResolver ImportResolver
+	location       string
+	importedBy     []string
+	reportWarning  func(error)
+	// Whether v1 documents are interpolated like v2 documents.
+	strictInterpolation bool
+	// The metadata of the importing document, interpolated into imported documents before their own.
+	inheritedMetadata map[string]string
+	erbProperties     map[string]interface{}
+	// Threshold values with units in import thresholds, normalized once the imports are resolved.
+	importedUnits []importedValueWithUnit
+
+	source           []byte
+	sourceOverridden bool
+}
+
+// Validation errors are located in the given YAML instead of the YAML that is read. Without a source,
+// errors only have a path.
+func locateErrorsIn(source []byte) ReadOpt {
+	return func(options *readOptions) {
+		options.source = source
+		options.sourceOverridden = true
+	}
 }
@@ -170,6 +170,66 @@ spec:
 			g.Expect(d.Spec.Indicators[0].Presentation).To(Equal(test_fixtures.DefaultPresentation()))
 		})
 
+		t.Run("populates the document's defaults", func(t *testing.T) {
+			g := NewGomegaWithT(t)
+
+			for _, apiVersion := range []string{api_versions.V1, api_versions.V2} {
+				reader := ioutil.NopCloser(strings.NewReader(`---
+apiVersion: ` + apiVersion + `
+kind: IndicatorDocument
+
+spec:
+  product:
+    name: well-performing-component
+    version: 0.0.1
+  defaults:
+    alert:
+      for: 10m
+      step: 5m
+    presentation:
+      chartType: bar
+      units: seconds
+    documentation:
+      thresholdNote: Tuned for production
+
+  indicators:
+  - name: test_performance_indicator
+    promql: promql_query
+    thresholds:
+    - operator: lt
+      value: 0
+      level: warning
+`))
+				d, errs := indicator.DocumentFromYAML(reader)
+				g.Expect(errs).To(BeEmpty(), apiVersion)
+
+				i := d.Spec.Indicators[0]
+				g.Expect(i.Thresholds[0].Alert).To(Equal(v1.Alert{For: "10m", Step: "5m"}), apiVersion)
+				g.Expect(i.Presentation.ChartType).To(Equal(v1.BarChart), apiVersion)
+				g.Expect(i.Presentation.Units).To(Equal("seconds"), apiVersion)
+				g.Expect(i.Documentation).To(HaveKeyWithValue("thresholdNote", "Tuned for production"), apiVersion)
+			}
+		})
+
+		t.Run("returns an error for invalid default alert metadata", func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			reader := ioutil.NopCloser(strings.NewReader(`---
+apiVersion: indicatorprotocol.io/v1
+kind: IndicatorDocument
+
+spec:
+  product:
+    name: well-performing-component
+    version: 0.0.1
+  defaults:
+    alert:
+      labels:
+        level: page
+`))
+			_, errs := indicator.DocumentFromYAML(reader)
+			g.Expect(errs).To(ContainElement(MatchError(`defaults.alert label "level" is reserved, it is set on every generated alert`)))
+		})
+
 		t.Run("handles thresholds", func(t *testing.T) {
 			t.Run("it handles all the operators", func(t *testing.T) {
 				g := NewGomegaWithT(t)
@@ -505,6 +565,85 @@ spec:
 }
 
 func TestPatchFromYAML(t *testing.T) {
+	t.Run("parses matchers", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		reader := ioutil.NopCloser(strings.NewReader(`---
+apiVersion: indicatorprotocol.io/v1
+kind: IndicatorDocumentPatch
+
+match:
+  product:
+    name:
+      glob: cf-*
+    version:
+      range: ^1.2
+  labels:
+    az: z1
+    deployment:
+      regex: cf-.*
+
+operations: []
+`))
+		p, err := indicator.PatchFromYAML(reader)
+		g.Expect(err).ToNot(HaveOccurred())
+
+		g.Expect(p.Match).To(Equal(indicator.Match{
+			NameMatcher:    &indicator.ValueMatcher{Glob: "cf-*"},
+			VersionMatcher: &indicator.ValueMatcher{Range: "^1.2"},
+			Labels: map[string]indicator.ValueMatcher{
+				"az":         {Equals: "z1"},
+				"deployment": {Regex: "cf-.*"},
+			},
+		}))
+	})
+
+	t.Run("parses the priority", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		reader := ioutil.NopCloser(strings.NewReader(`---
+apiVersion: indicatorprotocol.io/v1
+kind: IndicatorDocumentPatch
+priority: 10
+
+match:
+  product:
+    name: cf
+
+operations: []
+`))
+		p, err := indicator.PatchFromYAML(reader)
+		g.Expect(err).ToNot(HaveOccurred())
+
+		g.Expect(p.Priority).To(Equal(10))
+	})
+
+	t.Run("returns an error for invalid matchers", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		reader := ioutil.NopCloser(strings.NewReader(`---
+apiVersion: indicatorprotocol.io/v1
+kind: IndicatorDocumentPatch
+
+match:
+  labels:
+    deployment:
+      regex: cf-(
+`))
+		_, err := indicator.PatchFromYAML(reader)
+		g.Expect(err).To(MatchError(ContainSubstring("error parsing regexp: missing closing ): `cf-(`")))
+
+		reader = ioutil.NopCloser(strings.NewReader(`---
+apiVersion: indicatorprotocol.io/v1
+kind: IndicatorDocumentPatch
+
+match:
+  product:
+    version:
+      range: ^1.2
+      glob: 1.*
+`))
+		_, err = indicator.PatchFromYAML(reader)
+		g.Expect(err).To(MatchError(ContainSubstring("matchers can only contain one of equals, regex, glob or range")))
+	})
+
 	t.Run("apiVersion indicatorprotocol.io/v1", func(t *testing.T) {
 		t.Run("parses all the fields", func(t *testing.T) {
 			g := NewGomegaWithT(t)
@@ -679,3 +818,96 @@ product:
 		g.Expect(err.Error()).To(Equal("could not unmarshal apiVersion, check that document contains valid YAML"))
 	})
 }
+
+func TestDocumentFromYAMLV2(t *testing.T) {
+	t.Run("converts the document to the v1 model", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		reader := ioutil.NopCloser(strings.NewReader(`---
+apiVersion: indicatorprotocol.io/v2
+kind: IndicatorDocument
+
+metadata:
+  labels:
+    deployment: well-performing-deployment
+
+spec:
+  product:
+    name: well-performing-component
+    version: 0.0.1
+  indicators:
+  - name: test_performance_indicator
+    promql: prom{deployment="$deployment"}
+    title: Test Performance Indicator
+    description: This is a valid markdown description.
+    documentation:
+      recommendedResponse: Panic!
+    thresholds:
+    - level: warning
+      operator: lte
+      value: 500
+`))
+		doc, errs := indicator.DocumentFromYAML(reader)
+		g.Expect(errs).To(BeEmpty())
+
+		g.Expect(doc.APIVersion).To(Equal(api_versions.V1))
+		g.Expect(doc.Labels).To(Equal(map[string]string{"deployment": "well-performing-deployment"}))
+		g.Expect(doc.Spec.Indicators).To(ConsistOf(v1.IndicatorSpec{
+			Name:   "test_performance_indicator",
+			PromQL: `prom{deployment="well-performing-deployment"}`,
+			Thresholds: []v1.Threshold{{
+				Level:    "warning",
+				Operator: v1.LessThanOrEqualTo,
+				Value:    500,
+				Alert:    test_fixtures.DefaultAlert(),
+			}},
+			Presentation: test_fixtures.DefaultPresentation(),
+			Documentation: map[string]string{
+				"title":               "Test Performance Indicator",
+				"description":         "This is a valid markdown description.",
+				"recommendedResponse": "Panic!",
+			},
+		}))
+	})
+
+	t.Run("validates the document against the v2 schema", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		reader := ioutil.NopCloser(strings.NewReader(`---
+apiVersion: indicatorprotocol.io/v2
+kind: IndicatorDocument
+
+spec:
+  product:
+    name: well-performing-component
+    version: 0.0.1
+  indicators:
+  - name: test_performance_indicator
+    promql: test_query
+    title: 5
+`))
+		_, errs := indicator.DocumentFromYAML(reader)
+		g.Expect(errs).To(HaveLen(1))
+		g.Expect(errs[0].Error()).To(ContainSubstring("title"))
+	})
+
+	t.Run("reads product and metadata", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		document := `---
+apiVersion: indicatorprotocol.io/v2
+kind: IndicatorDocument
+metadata:
+  labels:
+    deployment: abc
+spec:
+  product:
+    name: my-product
+    version: 1.0.0
+`
+		product, err := indicator.ProductFromYAML(ioutil.NopCloser(strings.NewReader(document)))
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(product).To(Equal(v1.Product{Name: "my-product", Version: "1.0.0"}))
+
+		metadata, err := indicator.MetadataFromYAML(ioutil.NopCloser(strings.NewReader(document)))
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(metadata).To(Equal(map[string]string{"deployment": "abc"}))
+	})
+}
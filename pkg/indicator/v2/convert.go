@@ -0,0 +1,353 @@
+package v2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/pivotal/monitoring-indicator-protocol/pkg/api_versions"
+	v1 "github.com/pivotal/monitoring-indicator-protocol/pkg/k8s/apis/indicatordocument/v1"
+)
+
+const (
+	titleField       = "title"
+	descriptionField = "description"
+)
+
+// ToV1 converts a v2 document into the v1 model used throughout the rest of the code base.
+// The resulting document has an apiVersion of indicatorprotocol.io/v1.
+func ToV1(doc IndicatorDocument) v1.IndicatorDocument {
+	var indicators []v1.IndicatorSpec
+	if doc.Spec.Indicators != nil {
+		indicators = make([]v1.IndicatorSpec, 0, len(doc.Spec.Indicators))
+		for _, i := range doc.Spec.Indicators {
+			indicators = append(indicators, indicatorToV1(i))
+		}
+	}
+
+	return v1.IndicatorDocument{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: api_versions.V1,
+			Kind:       doc.Kind,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   doc.Metadata.Name,
+			Labels: doc.Metadata.Labels,
+		},
+		Spec: v1.IndicatorDocumentSpec{
+			Product: v1.Product{
+				Name:    doc.Spec.Product.Name,
+				Version: doc.Spec.Product.Version,
+			},
+			Defaults:           doc.Spec.Defaults,
+			Imports:            importsToV1(doc.Spec.Imports),
+			Indicators:         indicators,
+			IndicatorTemplates: templatesToV1(doc.Spec.IndicatorTemplates),
+			SLOs:               slosToV1(doc.Spec.SLOs),
+			Layout:             layoutToV1(doc.Spec.Layout),
+		},
+	}
+}
+
+// FromV1 converts a v1 document into its v2 representation. Only non-empty documentation titles and
+// descriptions are promoted, so that converting the result back with ToV1 yields the original
+// document.
+func FromV1(doc v1.IndicatorDocument) IndicatorDocument {
+	var indicators []IndicatorSpec
+	if doc.Spec.Indicators != nil {
+		indicators = make([]IndicatorSpec, 0, len(doc.Spec.Indicators))
+		for _, i := range doc.Spec.Indicators {
+			indicators = append(indicators, indicatorFromV1(i))
+		}
+	}
+
+	return IndicatorDocument{
+		APIVersion: api_versions.V2,
+		Kind:       doc.Kind,
+		Metadata: Metadata{
+			Name:   doc.Name,
+			Labels: doc.Labels,
+		},
+		Spec: IndicatorDocumentSpec{
+			Product: Product{
+				Name:    doc.Spec.Product.Name,
+				Version: doc.Spec.Product.Version,
+			},
+			Defaults:           doc.Spec.Defaults,
+			Imports:            importsFromV1(doc.Spec.Imports),
+			Indicators:         indicators,
+			IndicatorTemplates: templatesFromV1(doc.Spec.IndicatorTemplates),
+			SLOs:               slosFromV1(doc.Spec.SLOs),
+			Layout:             layoutFromV1(doc.Spec.Layout),
+		},
+	}
+}
+
+// The documentation of the indicator includes its title and description. Documents are validated
+// not to set them in both places before they are converted.
+func indicatorToV1(i IndicatorSpec) v1.IndicatorSpec {
+	var documentation map[string]string
+	if i.Documentation != nil || i.Title != "" || i.Description != "" {
+		documentation = make(map[string]string, len(i.Documentation)+2)
+		for k, v := range i.Documentation {
+			documentation[k] = v
+		}
+	}
+	if i.Title != "" {
+		documentation[titleField] = i.Title
+	}
+	if i.Description != "" {
+		documentation[descriptionField] = i.Description
+	}
+
+	return v1.IndicatorSpec{
+		Product:       i.Product,
+		Name:          i.Name,
+		Type:          i.Type,
+		PromQL:        i.PromQL,
+		Composite:     i.Composite,
+		Thresholds:    thresholdsToV1(i.Thresholds),
+		Alert:         i.Alert,
+		Documentation: documentation,
+		Presentation: v1.Presentation{
+			ChartType:    i.Presentation.ChartType,
+			CurrentValue: i.Presentation.CurrentValue,
+			Frequency:    i.Presentation.Frequency,
+			Labels:       i.Presentation.Labels,
+			Units:        i.Presentation.Units,
+		},
+		Deprecated:         i.Deprecated,
+		DeprecationMessage: i.DeprecationMessage,
+		ReplacedBy:         i.ReplacedBy,
+	}
+}
+
+func indicatorFromV1(i v1.IndicatorSpec) IndicatorSpec {
+	var documentation map[string]string
+	if i.Documentation != nil {
+		documentation = make(map[string]string, len(i.Documentation))
+	}
+	for k, v := range i.Documentation {
+		if (k == titleField || k == descriptionField) && v != "" {
+			continue
+		}
+		documentation[k] = v
+	}
+
+	return IndicatorSpec{
+		Product:       i.Product,
+		Name:          i.Name,
+		Type:          i.Type,
+		PromQL:        i.PromQL,
+		Composite:     i.Composite,
+		Title:         i.Documentation[titleField],
+		Description:   i.Documentation[descriptionField],
+		Thresholds:    thresholdsFromV1(i.Thresholds),
+		Alert:         i.Alert,
+		Documentation: documentation,
+		Presentation: Presentation{
+			ChartType:    i.Presentation.ChartType,
+			CurrentValue: i.Presentation.CurrentValue,
+			Frequency:    i.Presentation.Frequency,
+			Labels:       i.Presentation.Labels,
+			Units:        i.Presentation.Units,
+		},
+		Deprecated:         i.Deprecated,
+		DeprecationMessage: i.DeprecationMessage,
+		ReplacedBy:         i.ReplacedBy,
+	}
+}
+
+func thresholdsToV1(thresholds []Threshold) []v1.Threshold {
+	var converted []v1.Threshold
+	if thresholds != nil {
+		converted = make([]v1.Threshold, 0, len(thresholds))
+		for _, t := range thresholds {
+			converted = append(converted, v1.Threshold{
+				Level:    t.Level,
+				Operator: t.Operator,
+				Value:    t.Value,
+				Lower:    t.Lower,
+				Upper:    t.Upper,
+				Recovery: t.Recovery,
+				Matchers: t.Matchers,
+				Alert: v1.Alert{
+					For:           t.Alert.For,
+					Step:          t.Alert.Step,
+					AlertMetadata: t.Alert.AlertMetadata,
+				},
+			})
+		}
+	}
+	return converted
+}
+
+func thresholdsFromV1(thresholds []v1.Threshold) []Threshold {
+	var converted []Threshold
+	if thresholds != nil {
+		converted = make([]Threshold, 0, len(thresholds))
+		for _, t := range thresholds {
+			converted = append(converted, Threshold{
+				Level:    t.Level,
+				Operator: t.Operator,
+				Value:    t.Value,
+				Lower:    t.Lower,
+				Upper:    t.Upper,
+				Recovery: t.Recovery,
+				Matchers: t.Matchers,
+				Alert: Alert{
+					For:           t.Alert.For,
+					Step:          t.Alert.Step,
+					AlertMetadata: t.Alert.AlertMetadata,
+				},
+			})
+		}
+	}
+	return converted
+}
+
+func importsToV1(imports []Import) []v1.Import {
+	if imports == nil {
+		return nil
+	}
+	converted := make([]v1.Import, 0, len(imports))
+	for _, imp := range imports {
+		var thresholds map[string][]v1.Threshold
+		if imp.Thresholds != nil {
+			thresholds = make(map[string][]v1.Threshold, len(imp.Thresholds))
+			for name, t := range imp.Thresholds {
+				thresholds[name] = thresholdsToV1(t)
+			}
+		}
+		converted = append(converted, v1.Import{
+			Path:       imp.Path,
+			Thresholds: thresholds,
+		})
+	}
+	return converted
+}
+
+func importsFromV1(imports []v1.Import) []Import {
+	if imports == nil {
+		return nil
+	}
+	converted := make([]Import, 0, len(imports))
+	for _, imp := range imports {
+		var thresholds map[string][]Threshold
+		if imp.Thresholds != nil {
+			thresholds = make(map[string][]Threshold, len(imp.Thresholds))
+			for name, t := range imp.Thresholds {
+				thresholds[name] = thresholdsFromV1(t)
+			}
+		}
+		converted = append(converted, Import{
+			Path:       imp.Path,
+			Thresholds: thresholds,
+		})
+	}
+	return converted
+}
+
+func templatesToV1(templates []IndicatorTemplate) []v1.IndicatorTemplate {
+	if templates == nil {
+		return nil
+	}
+	converted := make([]v1.IndicatorTemplate, 0, len(templates))
+	for _, t := range templates {
+		converted = append(converted, v1.IndicatorTemplate{
+			Indicator:  indicatorToV1(t.Indicator),
+			Parameters: t.Parameters,
+		})
+	}
+	return converted
+}
+
+func templatesFromV1(templates []v1.IndicatorTemplate) []IndicatorTemplate {
+	if templates == nil {
+		return nil
+	}
+	converted := make([]IndicatorTemplate, 0, len(templates))
+	for _, t := range templates {
+		converted = append(converted, IndicatorTemplate{
+			Indicator:  indicatorFromV1(t.Indicator),
+			Parameters: t.Parameters,
+		})
+	}
+	return converted
+}
+
+func slosToV1(slos []ServiceLevelObjective) []v1.ServiceLevelObjective {
+	if slos == nil {
+		return nil
+	}
+	converted := make([]v1.ServiceLevelObjective, 0, len(slos))
+	for _, slo := range slos {
+		converted = append(converted, v1.ServiceLevelObjective{
+			Name:          slo.Name,
+			GoodEvents:    slo.GoodEvents,
+			TotalEvents:   slo.TotalEvents,
+			Objective:     slo.Objective,
+			Window:        slo.Window,
+			Documentation: slo.Documentation,
+		})
+	}
+	return converted
+}
+
+func slosFromV1(slos []v1.ServiceLevelObjective) []ServiceLevelObjective {
+	if slos == nil {
+		return nil
+	}
+	converted := make([]ServiceLevelObjective, 0, len(slos))
+	for _, slo := range slos {
+		converted = append(converted, ServiceLevelObjective{
+			Name:          slo.Name,
+			GoodEvents:    slo.GoodEvents,
+			TotalEvents:   slo.TotalEvents,
+			Objective:     slo.Objective,
+			Window:        slo.Window,
+			Documentation: slo.Documentation,
+		})
+	}
+	return converted
+}
+
+func layoutToV1(l Layout) v1.Layout {
+	var sections []v1.Section
+	if l.Sections != nil {
+		sections = make([]v1.Section, 0, len(l.Sections))
+		for _, s := range l.Sections {
+			sections = append(sections, v1.Section{
+				Title:       s.Title,
+				Description: s.Description,
+				Indicators:  s.Indicators,
+			})
+		}
+	}
+
+	return v1.Layout{
+		Owner:       l.Owner,
+		Title:       l.Title,
+		Description: l.Description,
+		Sections:    sections,
+	}
+}
+
+func layoutFromV1(l v1.Layout) Layout {
+	var sections []Section
+	if l.Sections != nil {
+		sections = make([]Section, 0, len(l.Sections))
+		for _, s := range l.Sections {
+			sections = append(sections, Section{
+				Title:       s.Title,
+				Description: s.Description,
+				Indicators:  s.Indicators,
+			})
+		}
+	}
+
+	return Layout{
+		Owner:       l.Owner,
+		Title:       l.Title,
+		Description: l.Description,
+		Sections:    sections,
+	}
+}
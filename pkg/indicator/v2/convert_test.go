@@ -0,0 +1,176 @@
+package v2_test
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ghodss/yaml"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/pivotal/monitoring-indicator-protocol/pkg/api_versions"
+	"github.com/pivotal/monitoring-indicator-protocol/pkg/indicator"
+	v2 "github.com/pivotal/monitoring-indicator-protocol/pkg/indicator/v2"
+	v1 "github.com/pivotal/monitoring-indicator-protocol/pkg/k8s/apis/indicatordocument/v1"
+)
+
+func TestConversion(t *testing.T) {
+	t.Run("round trips a v1 document through v2", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		doc, err := indicator.ReadFile("../../../example_indicators.yml", indicator.SkipMetadataInterpolation)
+		g.Expect(err).ToNot(HaveOccurred())
+
+		converted := v2.FromV1(doc)
+		g.Expect(converted.APIVersion).To(Equal(api_versions.V2))
+
+		g.Expect(v2.ToV1(converted)).To(Equal(doc))
+	})
+
+	t.Run("round trips a v1 document through v2 YAML", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		doc, err := indicator.ReadFile("../../../example_indicators.yml", indicator.SkipMetadataInterpolation)
+		g.Expect(err).ToNot(HaveOccurred())
+
+		v2Bytes, err := yaml.Marshal(v2.FromV1(doc))
+		g.Expect(err).ToNot(HaveOccurred())
+
+		file, err := ioutil.TempFile("", "v2-document")
+		g.Expect(err).ToNot(HaveOccurred())
+		defer os.Remove(file.Name())
+		_, err = file.Write(v2Bytes)
+		g.Expect(err).ToNot(HaveOccurred())
+
+		roundTripped, err := indicator.ReadFile(file.Name(), indicator.SkipMetadataInterpolation)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(roundTripped).To(Equal(doc))
+	})
+
+	t.Run("promotes title and description out of the documentation", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		doc := v1.IndicatorDocument{
+			Spec: v1.IndicatorDocumentSpec{
+				Indicators: []v1.IndicatorSpec{{
+					Name:   "test_indicator",
+					PromQL: "test_query",
+					Documentation: map[string]string{
+						"title":               "Test Indicator",
+						"description":         "A description",
+						"recommendedResponse": "Panic!",
+					},
+				}},
+			},
+		}
+
+		converted := v2.FromV1(doc)
+		g.Expect(converted.Spec.Indicators[0].Title).To(Equal("Test Indicator"))
+		g.Expect(converted.Spec.Indicators[0].Description).To(Equal("A description"))
+		g.Expect(converted.Spec.Indicators[0].Documentation).To(Equal(map[string]string{
+			"recommendedResponse": "Panic!",
+		}))
+
+		g.Expect(v2.ToV1(converted).Spec.Indicators[0].Documentation).To(Equal(doc.Spec.Indicators[0].Documentation))
+	})
+	t.Run("keeps empty titles and descriptions in the documentation", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		doc := v1.IndicatorDocument{
+			TypeMeta: metav1.TypeMeta{APIVersion: api_versions.V1},
+			Spec: v1.IndicatorDocumentSpec{
+				Indicators: []v1.IndicatorSpec{{
+					Name:          "test_indicator",
+					PromQL:        "test_query",
+					Documentation: map[string]string{"title": ""},
+				}, {
+					Name:          "other_indicator",
+					PromQL:        "other_query",
+					Documentation: map[string]string{},
+				}},
+			},
+		}
+
+		converted := v2.FromV1(doc)
+		g.Expect(converted.Spec.Indicators[0].Documentation).To(Equal(map[string]string{"title": ""}))
+		g.Expect(converted.Validate()).To(BeEmpty())
+
+		g.Expect(v2.ToV1(converted)).To(Equal(doc))
+	})
+
+	t.Run("rejects titles and descriptions that are also set in the documentation", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		_, errs := indicator.DocumentFromYAML(ioutil.NopCloser(strings.NewReader(`---
+apiVersion: indicatorprotocol.io/v2
+kind: IndicatorDocument
+
+spec:
+  product:
+    name: my-product
+    version: 1.0.0
+  indicators:
+  - name: my_indicator
+    promql: my_metric
+    title: My Indicator
+    description: Measures things
+    documentation:
+      title: Another Title
+      description: ""
+`)))
+		g.Expect(errs).To(ConsistOf(
+			MatchError("indicators[0].documentation.title cannot be set together with indicators[0].title"),
+			MatchError("indicators[0].documentation.description cannot be set together with indicators[0].description"),
+		))
+		g.Expect(errs[0].(*v1.ValidationError).Line).To(Equal(15))
+	})
+
+	t.Run("carries the alert metadata of indicators and thresholds", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		doc := v1.IndicatorDocument{
+			Spec: v1.IndicatorDocumentSpec{
+				Indicators: []v1.IndicatorSpec{{
+					Name:   "test_indicator",
+					PromQL: "test_query",
+					Alert: v1.AlertMetadata{
+						Labels:     map[string]string{"team": "a-team"},
+						RunbookURL: "https://example.com/runbook",
+					},
+					Thresholds: []v1.Threshold{{
+						Level:    "critical",
+						Operator: v1.GreaterThan,
+						Alert: v1.Alert{
+							For: "5m",
+							AlertMetadata: v1.AlertMetadata{
+								Annotations: map[string]string{"summary": "{{ $value }} is too high"},
+								Owner:       "b-team",
+							},
+						},
+					}},
+				}},
+			},
+		}
+
+		g.Expect(v2.ToV1(v2.FromV1(doc)).Spec.Indicators).To(Equal(doc.Spec.Indicators))
+	})
+	t.Run("carries the deprecation of indicators", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		doc := v1.IndicatorDocument{
+			Spec: v1.IndicatorDocumentSpec{
+				Indicators: []v1.IndicatorSpec{{
+					Name:               "test_indicator",
+					PromQL:             "test_query",
+					Deprecated:         true,
+					DeprecationMessage: "Use the other one.",
+					ReplacedBy:         "other_indicator",
+				}},
+			},
+		}
+
+		g.Expect(v2.ToV1(v2.FromV1(doc)).Spec.Indicators).To(Equal(doc.Spec.Indicators))
+	})
+}
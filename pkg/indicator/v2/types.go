@@ -0,0 +1,112 @@
+package v2
+
+import (
+	v1 "github.com/pivotal/monitoring-indicator-protocol/pkg/k8s/apis/indicatordocument/v1"
+)
+
+// IndicatorDocument is the indicatorprotocol.io/v2 representation of an indicator document.
+// It is only a wire format: documents are converted to the v1 model with ToV1 as soon as they
+// are read, and all registry, exporter and CLI code works on that single model.
+type IndicatorDocument struct {
+	APIVersion string                `json:"apiVersion"`
+	Kind       string                `json:"kind"`
+	Metadata   Metadata              `json:"metadata,omitempty"`
+	Spec       IndicatorDocumentSpec `json:"spec"`
+}
+
+type Metadata struct {
+	Name   string            `json:"name,omitempty"`
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+type IndicatorDocumentSpec struct {
+	Product            Product                 `json:"product"`
+	Defaults           *v1.DocumentDefaults    `json:"defaults,omitempty"`
+	Imports            []Import                `json:"imports,omitempty"`
+	Indicators         []IndicatorSpec         `json:"indicators,omitempty"`
+	IndicatorTemplates []IndicatorTemplate     `json:"indicatorTemplates,omitempty"`
+	SLOs               []ServiceLevelObjective `json:"slos,omitempty"`
+	Layout             Layout                  `json:"layout,omitempty"`
+}
+
+type Import struct {
+	Path       string                 `json:"path"`
+	Thresholds map[string][]Threshold `json:"thresholds,omitempty"`
+}
+
+type IndicatorTemplate struct {
+	Indicator  IndicatorSpec       `json:"indicator"`
+	Parameters []map[string]string `json:"parameters"`
+}
+
+type ServiceLevelObjective struct {
+	Name          string            `json:"name"`
+	GoodEvents    string            `json:"goodEvents"`
+	TotalEvents   string            `json:"totalEvents"`
+	Objective     float64           `json:"objective"`
+	Window        string            `json:"window,omitempty"`
+	Documentation map[string]string `json:"documentation,omitempty"`
+}
+
+type Product struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// IndicatorSpec differs from v1 by promoting the `title` and `description` documentation
+// entries to top-level fields.
+type IndicatorSpec struct {
+	Product       string            `json:"product,omitempty"`
+	Name          string            `json:"name"`
+	Type          v1.IndicatorType  `json:"type"`
+	PromQL        string            `json:"promql,omitempty"`
+	Composite     *v1.Composite     `json:"composite,omitempty"`
+	Title         string            `json:"title,omitempty"`
+	Description   string            `json:"description,omitempty"`
+	Thresholds    []Threshold       `json:"thresholds,omitempty"`
+	Alert         v1.AlertMetadata  `json:"alert,omitempty"`
+	Documentation map[string]string `json:"documentation,omitempty"`
+	Presentation  Presentation      `json:"presentation,omitempty"`
+
+	Deprecated         bool   `json:"deprecated,omitempty"`
+	DeprecationMessage string `json:"deprecationMessage,omitempty"`
+	ReplacedBy         string `json:"replacedBy,omitempty"`
+}
+
+type Threshold struct {
+	Level    string               `json:"level"`
+	Operator v1.ThresholdOperator `json:"operator"`
+	Value    float64              `json:"value"`
+	Lower    *float64             `json:"lower,omitempty"`
+	Upper    *float64             `json:"upper,omitempty"`
+	Recovery *float64             `json:"recovery,omitempty"`
+	Matchers map[string]string    `json:"matchers,omitempty"`
+	Alert    Alert                `json:"alert,omitempty"`
+}
+
+type Alert struct {
+	For              string `json:"for,omitempty"`
+	Step             string `json:"step,omitempty"`
+	v1.AlertMetadata `json:",inline"`
+}
+
+type Presentation struct {
+	ChartType    v1.ChartType `json:"chartType,omitempty"`
+	CurrentValue bool         `json:"currentValue,omitempty"`
+	Frequency    int64        `json:"frequency,omitempty"`
+	Labels       []string     `json:"labels,omitempty"`
+	Units        string       `json:"units,omitempty"`
+}
+
+type Layout struct {
+	Owner       string    `json:"owner,omitempty"`
+	Title       string    `json:"title,omitempty"`
+	Description string    `json:"description,omitempty"`
+	Sections    []Section `json:"sections,omitempty"`
+}
+
+type Section struct {
+	Title       string   `json:"title,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Indicators  []string `json:"indicators,omitempty"`
+}
@@ -0,0 +1,37 @@
+package v2
+
+import (
+	"fmt"
+
+	v1 "github.com/pivotal/monitoring-indicator-protocol/pkg/k8s/apis/indicatordocument/v1"
+)
+
+// Validate returns the errors of the document that its v1 conversion cannot represent. The title and
+// description of an indicator can either be set on the indicator or in its documentation, not both.
+func (doc IndicatorDocument) Validate() []error {
+	var es []error
+	for idx, i := range doc.Spec.Indicators {
+		es = append(es, validatePromotedDocumentation(i, fmt.Sprintf("spec.indicators[%d]", idx), fmt.Sprintf("indicators[%d]", idx))...)
+	}
+	for idx, t := range doc.Spec.IndicatorTemplates {
+		es = append(es, validatePromotedDocumentation(t.Indicator, fmt.Sprintf("spec.indicatorTemplates[%d].indicator", idx), fmt.Sprintf("indicatorTemplates[%d].indicator", idx))...)
+	}
+	return es
+}
+
+func validatePromotedDocumentation(i IndicatorSpec, path string, name string) []error {
+	var es []error
+	fields := []struct {
+		key   string
+		value string
+	}{
+		{titleField, i.Title},
+		{descriptionField, i.Description},
+	}
+	for _, f := range fields {
+		if _, ok := i.Documentation[f.key]; ok && f.value != "" {
+			es = append(es, v1.NewValidationError(path+".documentation."+f.key, "%s.documentation.%s cannot be set together with %s.%s", name, f.key, name, f.key))
+		}
+	}
+	return es
+}
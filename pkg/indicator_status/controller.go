@@ -4,6 +4,7 @@ import (
 	"log"
 	"time"
 
+	"github.com/pivotal/monitoring-indicator-protocol/pkg/prometheus_client"
 	"github.com/pivotal/monitoring-indicator-protocol/pkg/registry"
 )
 
@@ -15,7 +16,7 @@ type StatusUpdater interface {
 }
 
 type PromQLClient interface {
-	QueryVectorValues(promql string) ([]float64, error)
+	QueryVectorSamples(promql string) ([]prometheus_client.Sample, error)
 }
 
 type StatusController struct {
@@ -62,23 +63,27 @@ func (c StatusController) updateStatuses() error {
 		return err
 	}
 	for _, indicatorDocument := range apiv0Documents {
+		statuses := make(map[string]string)
 		for _, indicator := range indicatorDocument.Spec.Indicators {
-			if len(indicator.Thresholds) == 0 {
+			if indicator.Composite != nil || len(indicator.Thresholds) == 0 {
 				continue
 			}
 
-			values, err := c.promQLClient.QueryVectorValues(indicator.PromQL)
+			samples, err := c.promQLClient.QueryVectorSamples(indicator.PromQL)
 			if err != nil {
 				log.Print("Error querying Prometheus")
 				continue
 			}
 			thresholds := registry.ConvertThresholds(indicator.Thresholds)
-			status := Match(thresholds, values)
+			status := MatchSamples(thresholds, samples, previousStatus(indicator))
+			statuses[indicator.Name] = status
 			statusUpdates = append(statusUpdates, registry.ApiV1UpdateIndicatorStatus{
 				Name:   indicator.Name,
 				Status: &status,
+				Value:  singleValue(samples),
 			})
 		}
+		statusUpdates = append(statusUpdates, compositeStatusUpdates(indicatorDocument, statuses)...)
 		err := c.statusUpdater.BulkStatusUpdate(statusUpdates, indicatorDocument.UID)
 		if err != nil {
 			log.Print(err)
@@ -87,3 +92,37 @@ func (c StatusController) updateStatuses() error {
 
 	return nil
 }
+
+// Composite indicators are evaluated from the statuses just matched for the other indicators of the
+// document, so that they are never behind their indicators.
+func compositeStatusUpdates(doc registry.APIDocumentResponse, statuses map[string]string) []registry.ApiV1UpdateIndicatorStatus {
+	var updates []registry.ApiV1UpdateIndicatorStatus
+	for _, indicator := range doc.Spec.Indicators {
+		if indicator.Composite == nil {
+			continue
+		}
+		status := registry.ConvertComposite(indicator.Composite).Evaluate(statuses)
+		updates = append(updates, registry.ApiV1UpdateIndicatorStatus{
+			Name:   indicator.Name,
+			Status: &status,
+		})
+	}
+	return updates
+}
+
+// The value of an indicator is only reported when its query returns a single series, as there is no
+// single value otherwise.
+func singleValue(samples []prometheus_client.Sample) *float64 {
+	if len(samples) != 1 {
+		return nil
+	}
+	value := samples[0].Value
+	return &value
+}
+
+func previousStatus(indicator registry.APIIndicatorResponse) string {
+	if indicator.Status == nil || indicator.Status.Value == nil {
+		return ""
+	}
+	return *indicator.Status.Value
+}
@@ -10,6 +10,7 @@ import (
 	. "github.com/onsi/gomega"
 
 	"github.com/pivotal/monitoring-indicator-protocol/pkg/indicator_status"
+	"github.com/pivotal/monitoring-indicator-protocol/pkg/prometheus_client"
 	"github.com/pivotal/monitoring-indicator-protocol/pkg/registry"
 	"github.com/pivotal/monitoring-indicator-protocol/test_fixtures"
 )
@@ -65,10 +66,12 @@ func TestStatusController(t *testing.T) {
 			registry.ApiV1UpdateIndicatorStatus{
 				Name:   "error_rate",
 				Status: test_fixtures.StrPtr("critical"),
+				Value:  test_fixtures.FloatPtr(50),
 			},
 			registry.ApiV1UpdateIndicatorStatus{
 				Name:   "happiness_rate",
 				Status: test_fixtures.StrPtr("warning"),
+				Value:  test_fixtures.FloatPtr(9),
 			},
 		))
 	})
@@ -117,6 +120,74 @@ func TestStatusController(t *testing.T) {
 		))
 	})
 
+	t.Run("updates composite indicator statuses from the other statuses of the document", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		fakeQueryClient := setupFakeQueryClientWithVectorResponses(map[string][]float64{
+			"rate(errors[5m])":  {50},
+			"rate(happies[1m])": {11},
+		})
+
+		fakeRegistryClient := setupFakeRegistryClient([]registry.APIIndicatorResponse{
+			{
+				Name:   "error_rate",
+				PromQL: "rate(errors[5m])",
+				Thresholds: []registry.APIThresholdResponse{
+					{
+						Level:    "critical",
+						Operator: "gte",
+						Value:    50,
+					},
+				},
+			}, {
+				Name:   "happiness_rate",
+				PromQL: "rate(happies[1m])",
+				Thresholds: []registry.APIThresholdResponse{
+					{
+						Level:    "warning",
+						Operator: "lt",
+						Value:    10,
+					},
+				},
+			}, {
+				Name: "health",
+				Composite: &registry.APICompositeResponse{
+					Rule:       "worst",
+					Indicators: []string{"error_rate", "happiness_rate"},
+				},
+			},
+		})
+
+		controller := indicator_status.NewStatusController(
+			fakeRegistryClient,
+			fakeRegistryClient,
+			fakeQueryClient,
+			time.Minute,
+		)
+
+		go controller.Start()
+
+		g.Eventually(fakeRegistryClient.countBulkUpdates).Should(Equal(1))
+
+		g.Expect(fakeQueryClient.GetQueries()).To(HaveLen(2))
+		g.Expect(fakeRegistryClient.statusesForUID("uaa-abc-123")).To(ConsistOf(
+			registry.ApiV1UpdateIndicatorStatus{
+				Name:   "error_rate",
+				Status: test_fixtures.StrPtr("critical"),
+				Value:  test_fixtures.FloatPtr(50),
+			},
+			registry.ApiV1UpdateIndicatorStatus{
+				Name:   "happiness_rate",
+				Status: test_fixtures.StrPtr("HEALTHY"),
+				Value:  test_fixtures.FloatPtr(11),
+			},
+			registry.ApiV1UpdateIndicatorStatus{
+				Name:   "health",
+				Status: test_fixtures.StrPtr("critical"),
+			},
+		))
+	})
+
 	t.Run("only queries indicators with thresholds", func(t *testing.T) {
 
 		g := NewGomegaWithT(t)
@@ -175,12 +246,16 @@ type fakeQueryClient struct {
 	sync.Mutex
 }
 
-func (s *fakeQueryClient) QueryVectorValues(query string) ([]float64, error) {
+func (s *fakeQueryClient) QueryVectorSamples(query string) ([]prometheus_client.Sample, error) {
 	s.Lock()
 	defer s.Unlock()
 	s.queries = append(s.queries, query)
 
-	return s.responses[query], nil
+	var samples []prometheus_client.Sample
+	for _, v := range s.responses[query] {
+		samples = append(samples, prometheus_client.Sample{Value: v})
+	}
+	return samples, nil
 }
 
 func (s *fakeQueryClient) GetQueries() []string {
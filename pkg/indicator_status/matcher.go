@@ -4,25 +4,48 @@ import (
 	"sort"
 
 	"github.com/pivotal/monitoring-indicator-protocol/pkg/k8s/apis/indicatordocument/v1"
+	"github.com/pivotal/monitoring-indicator-protocol/pkg/prometheus_client"
 )
 
-const healthy = "HEALTHY"
-const Undefined = "UNDEFINED"
-const unknown = "UNKNOWN"
+const healthy = v1.HealthyStatus
+const Undefined = v1.UndefinedStatus
+const unknown = v1.UnknownStatus
 
 // Match takes thresholds and values and determines what threshold has been
 // breached. It returns nil if nothing was breached.
 func Match(thresholds []v1.Threshold, values []float64) string {
+	return MatchWithPreviousStatus(thresholds, values, "")
+}
+
+// MatchWithPreviousStatus behaves like Match, but honors the recovery value of
+// thresholds: a threshold whose level is the previous status stays breached
+// until the value crosses its recovery value.
+func MatchWithPreviousStatus(thresholds []v1.Threshold, values []float64, previousStatus string) string {
+	samples := make([]prometheus_client.Sample, 0, len(values))
+	for _, value := range values {
+		samples = append(samples, prometheus_client.Sample{Value: value})
+	}
+
+	return MatchSamples(thresholds, samples, previousStatus)
+}
+
+// MatchSamples behaves like MatchWithPreviousStatus, but evaluates each threshold
+// only against the series it applies to, according to the threshold matchers.
+func MatchSamples(thresholds []v1.Threshold, samples []prometheus_client.Sample, previousStatus string) string {
 	if len(thresholds) == 0 {
 		return Undefined
 	}
-	if len(values) == 0 {
+	if len(samples) == 0 {
 		return unknown
 	}
 	var breachedThresholdLevels []string
 	for _, threshold := range thresholds {
-		for _, value := range values {
-			if isBreached(threshold, value) {
+		previouslyBreached := threshold.Level == previousStatus
+		for _, sample := range samples {
+			if !threshold.AppliesTo(sample.Labels, thresholds) {
+				continue
+			}
+			if isBreached(threshold, sample.Value, previouslyBreached) {
 				breachedThresholdLevels = append(breachedThresholdLevels, threshold.Level)
 			}
 		}
@@ -40,7 +63,16 @@ func selectThreshold(thresholdLevels []string) string {
 	return thresholdLevels[0]
 }
 
-func isBreached(threshold v1.Threshold, value float64) bool {
+func isBreached(threshold v1.Threshold, value float64, previouslyBreached bool) bool {
+	if previouslyBreached && threshold.Recovery != nil {
+		switch threshold.Operator {
+		case v1.LessThanOrEqualTo, v1.LessThan:
+			return value < *threshold.Recovery
+		case v1.GreaterThanOrEqualTo, v1.GreaterThan:
+			return value > *threshold.Recovery
+		}
+	}
+
 	switch threshold.Operator {
 	case v1.LessThanOrEqualTo:
 		return value <= threshold.Value
@@ -54,6 +86,16 @@ func isBreached(threshold v1.Threshold, value float64) bool {
 		return value != threshold.Value
 	case v1.EqualTo:
 		return value == threshold.Value
+	case v1.Between:
+		if threshold.Lower == nil || threshold.Upper == nil {
+			return false
+		}
+		return value >= *threshold.Lower && value <= *threshold.Upper
+	case v1.Outside:
+		if threshold.Lower == nil || threshold.Upper == nil {
+			return false
+		}
+		return value < *threshold.Lower || value > *threshold.Upper
 
 	default:
 		return false
@@ -7,6 +7,7 @@ import (
 
 	"github.com/pivotal/monitoring-indicator-protocol/pkg/indicator_status"
 	"github.com/pivotal/monitoring-indicator-protocol/pkg/k8s/apis/indicatordocument/v1"
+	"github.com/pivotal/monitoring-indicator-protocol/pkg/prometheus_client"
 )
 
 func TestStatusMatcher(t *testing.T) {
@@ -155,6 +156,144 @@ func TestStatusMatcher(t *testing.T) {
 			g.Expect(status).NotTo(BeNil())
 			g.Expect(status).To(Equal("breached"))
 		})
+
+		t.Run("between", func(t *testing.T) {
+			g := NewGomegaWithT(t)
+
+			threshold := []v1.Threshold{{
+				Level:    "warning",
+				Operator: v1.Between,
+				Lower:    float64Ptr(5),
+				Upper:    float64Ptr(10),
+			}}
+
+			g.Expect(indicator_status.Match(threshold, []float64{5})).To(Equal("warning"))
+			g.Expect(indicator_status.Match(threshold, []float64{10})).To(Equal("warning"))
+			g.Expect(indicator_status.Match(threshold, []float64{4})).To(Equal("HEALTHY"))
+			g.Expect(indicator_status.Match(threshold, []float64{11})).To(Equal("HEALTHY"))
+		})
+
+		t.Run("outside", func(t *testing.T) {
+			g := NewGomegaWithT(t)
+
+			threshold := []v1.Threshold{{
+				Level:    "critical",
+				Operator: v1.Outside,
+				Lower:    float64Ptr(5),
+				Upper:    float64Ptr(10),
+			}}
+
+			g.Expect(indicator_status.Match(threshold, []float64{4})).To(Equal("critical"))
+			g.Expect(indicator_status.Match(threshold, []float64{11})).To(Equal("critical"))
+			g.Expect(indicator_status.Match(threshold, []float64{5})).To(Equal("HEALTHY"))
+			g.Expect(indicator_status.Match(threshold, []float64{10})).To(Equal("HEALTHY"))
+		})
+	})
+
+	t.Run("hysteresis", func(t *testing.T) {
+		t.Run("stays breached until the value crosses the recovery value", func(t *testing.T) {
+			g := NewGomegaWithT(t)
+
+			threshold := []v1.Threshold{{
+				Level:    "critical",
+				Operator: v1.GreaterThan,
+				Value:    90,
+				Recovery: float64Ptr(80),
+			}}
+
+			g.Expect(indicator_status.MatchWithPreviousStatus(threshold, []float64{85}, "critical")).To(Equal("critical"))
+			g.Expect(indicator_status.MatchWithPreviousStatus(threshold, []float64{80}, "critical")).To(Equal("HEALTHY"))
+			g.Expect(indicator_status.MatchWithPreviousStatus(threshold, []float64{85}, "HEALTHY")).To(Equal("HEALTHY"))
+			g.Expect(indicator_status.MatchWithPreviousStatus(threshold, []float64{95}, "HEALTHY")).To(Equal("critical"))
+		})
+
+		t.Run("works for lower bound thresholds", func(t *testing.T) {
+			g := NewGomegaWithT(t)
+
+			threshold := []v1.Threshold{{
+				Level:    "warning",
+				Operator: v1.LessThanOrEqualTo,
+				Value:    10,
+				Recovery: float64Ptr(20),
+			}}
+
+			g.Expect(indicator_status.MatchWithPreviousStatus(threshold, []float64{15}, "warning")).To(Equal("warning"))
+			g.Expect(indicator_status.MatchWithPreviousStatus(threshold, []float64{20}, "warning")).To(Equal("HEALTHY"))
+			g.Expect(indicator_status.MatchWithPreviousStatus(threshold, []float64{15}, "critical")).To(Equal("HEALTHY"))
+		})
+
+		t.Run("ignores the previous status without a recovery value", func(t *testing.T) {
+			g := NewGomegaWithT(t)
+
+			threshold := []v1.Threshold{{
+				Level:    "critical",
+				Operator: v1.GreaterThan,
+				Value:    90,
+			}}
+
+			g.Expect(indicator_status.MatchWithPreviousStatus(threshold, []float64{85}, "critical")).To(Equal("HEALTHY"))
+		})
+	})
+
+	t.Run("label-scoped thresholds", func(t *testing.T) {
+		thresholds := []v1.Threshold{{
+			Level:    "critical",
+			Operator: v1.GreaterThan,
+			Value:    10,
+		}, {
+			Level:    "critical",
+			Operator: v1.GreaterThan,
+			Value:    5,
+			Matchers: map[string]string{"az": "z1"},
+		}}
+		sample := func(az string, value float64) prometheus_client.Sample {
+			return prometheus_client.Sample{
+				Labels: map[string]string{"az": az, "job": "router"},
+				Value:  value,
+			}
+		}
+
+		t.Run("only evaluates scoped thresholds against matching series", func(t *testing.T) {
+			g := NewGomegaWithT(t)
+
+			status := indicator_status.MatchSamples(thresholds, []prometheus_client.Sample{
+				sample("z1", 7),
+				sample("z2", 7),
+			}, "")
+
+			g.Expect(status).To(Equal("critical"))
+
+			status = indicator_status.MatchSamples(thresholds, []prometheus_client.Sample{
+				sample("z1", 4),
+				sample("z2", 7),
+			}, "")
+
+			g.Expect(status).To(Equal("HEALTHY"))
+		})
+
+		t.Run("overrides unscoped thresholds of the same level for matching series", func(t *testing.T) {
+			g := NewGomegaWithT(t)
+
+			lenient := []v1.Threshold{{
+				Level:    "critical",
+				Operator: v1.GreaterThan,
+				Value:    10,
+			}, {
+				Level:    "critical",
+				Operator: v1.GreaterThan,
+				Value:    20,
+				Matchers: map[string]string{"az": "z1"},
+			}}
+
+			g.Expect(indicator_status.MatchSamples(lenient, []prometheus_client.Sample{sample("z1", 15)}, "")).To(Equal("HEALTHY"))
+			g.Expect(indicator_status.MatchSamples(lenient, []prometheus_client.Sample{sample("z2", 15)}, "")).To(Equal("critical"))
+		})
+
+		t.Run("does not apply scoped thresholds to unlabelled values", func(t *testing.T) {
+			g := NewGomegaWithT(t)
+
+			g.Expect(indicator_status.Match(thresholds, []float64{7})).To(Equal("HEALTHY"))
+		})
 	})
 
 	t.Run("threshold priority", func(t *testing.T) {
@@ -277,3 +416,7 @@ func TestStatusMatcher(t *testing.T) {
 		g.Expect(status).To(Equal("critical"))
 	})
 }
+
+func float64Ptr(f float64) *float64 {
+	return &f
+}
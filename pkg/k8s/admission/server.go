@@ -191,15 +191,21 @@ func indicatorDocumentDefaultHandler(responseWriter http.ResponseWriter, r *http
 		return
 	}
 
+	var defaults v1.DocumentDefaults
+	if doc.Spec.Defaults != nil {
+		defaults = *doc.Spec.Defaults
+	}
+
 	patchOperations := getLayoutPatches(doc)
 	for idx, i := range doc.Spec.Indicators {
 		for t, threshold := range i.Thresholds {
 			patchOperations = append(patchOperations,
-				getAlertPatches(threshold.Alert, fmt.Sprintf("/spec/indicators/%d/thresholds/%d", idx, t))...)
+				getAlertPatches(threshold.Alert, defaults.Alert, fmt.Sprintf("/spec/indicators/%d/thresholds/%d", idx, t))...)
 		}
 		patchOperations = append(patchOperations,
-			getPresentationPatches(i.Presentation, fmt.Sprintf("/spec/indicators/%d", idx))...)
+			getPresentationPatches(i.Presentation, defaults.Presentation, fmt.Sprintf("/spec/indicators/%d", idx))...)
 		patchOperations = append(patchOperations, getThresholdPatches(i.Thresholds, fmt.Sprintf("/spec/indicators/%d", idx))...)
+		patchOperations = append(patchOperations, getMergedDefaultPatches(i, defaults, fmt.Sprintf("/spec/indicators/%d", idx))...)
 	}
 
 	patchBytes, err := marshalPatches(patchOperations)
@@ -286,9 +292,9 @@ func indicatorDefaultHandler(responseWriter http.ResponseWriter, request *http.R
 	var patchOperations []patch
 	for t, threshold := range k8sIndicator.Spec.Thresholds {
 		patchOperations = append(patchOperations,
-			getAlertPatches(threshold.Alert, fmt.Sprintf("/spec/thresholds/%d", t))...)
+			getAlertPatches(threshold.Alert, v1.Alert{}, fmt.Sprintf("/spec/thresholds/%d", t))...)
 	}
-	patchOperations = append(patchOperations, getPresentationPatches(k8sIndicator.Spec.Presentation, "/spec")...)
+	patchOperations = append(patchOperations, getPresentationPatches(k8sIndicator.Spec.Presentation, v1.Presentation{}, "/spec")...)
 	patchOperations = append(patchOperations, getThresholdPatches(k8sIndicator.Spec.Thresholds, "/spec")...)
 
 	patchBytes, err := marshalPatches(patchOperations)
@@ -440,17 +446,28 @@ func getThresholdPatches(threshold []v1.Threshold, context string) []patch {
 	return []patch{}
 }
 
-func getPresentationPatches(presentation v1.Presentation, context string) []patch {
+// The defaults are those of the indicator's document, if it has any.
+func getPresentationPatches(presentation v1.Presentation, defaults v1.Presentation, context string) []patch {
+	chartType := defaults.ChartType
+	if chartType == v1.UndefinedChart {
+		chartType = v1.StepChart
+	}
+	labels := defaults.Labels
+	if labels == nil {
+		labels = []string{}
+	}
+
 	if reflect.DeepEqual(presentation, v1.Presentation{}) {
 		return []patch{
 			{
 				Op:   "add",
 				Path: fmt.Sprintf("%s/presentation", context),
 				Value: v1.Presentation{
-					ChartType:    v1.StepChart,
-					CurrentValue: false,
-					Frequency:    0,
-					Labels:       []string{},
+					ChartType:    chartType,
+					CurrentValue: defaults.CurrentValue,
+					Frequency:    defaults.Frequency,
+					Labels:       labels,
+					Units:        defaults.Units,
 				},
 			},
 		}
@@ -461,41 +478,61 @@ func getPresentationPatches(presentation v1.Presentation, context string) []patc
 		patchOperations = append(patchOperations, patch{
 			Op:    "add",
 			Path:  fmt.Sprintf("%s/presentation/chartType", context),
-			Value: v1.StepChart,
+			Value: chartType,
 		})
 	}
 	if !presentation.CurrentValue {
 		patchOperations = append(patchOperations, patch{
 			Op:    "add",
 			Path:  fmt.Sprintf("%s/presentation/currentValue", context),
-			Value: false,
+			Value: defaults.CurrentValue,
 		})
 	}
 	if presentation.Frequency == 0 {
 		patchOperations = append(patchOperations, patch{
 			Op:    "add",
 			Path:  fmt.Sprintf("%s/presentation/frequency", context),
-			Value: 0,
+			Value: defaults.Frequency,
 		})
 	}
 	if len(presentation.Labels) == 0 {
+		if presentation.Labels != nil {
+			labels = []string{}
+		}
 		patchOperations = append(patchOperations, patch{
 			Op:    "add",
 			Path:  fmt.Sprintf("%s/presentation/labels", context),
-			Value: []string{},
+			Value: labels,
+		})
+	}
+	if presentation.Units == "" && defaults.Units != "" {
+		patchOperations = append(patchOperations, patch{
+			Op:    "add",
+			Path:  fmt.Sprintf("%s/presentation/units", context),
+			Value: defaults.Units,
 		})
 	}
 	return patchOperations
 }
 
-func getAlertPatches(alert v1.Alert, context string) []patch {
-	if alert.For == "" && alert.Step == "" {
+// The defaults are those of the threshold's document, if it has any.
+func getAlertPatches(alert v1.Alert, defaults v1.Alert, context string) []patch {
+	alertFor := defaults.For
+	if alertFor == "" {
+		alertFor = "1m"
+	}
+	alertStep := defaults.Step
+	if alertStep == "" {
+		alertStep = "1m"
+	}
+
+	if alert.For == "" && alert.Step == "" && alert.AlertMetadata.IsEmpty() {
 		return []patch{{
 			Op:   "add",
 			Path: fmt.Sprintf("%s/alert", context),
 			Value: v1.Alert{
-				For:  "1m",
-				Step: "1m",
+				For:  alertFor,
+				Step: alertStep,
 			},
 		}}
 	}
@@ -504,14 +541,39 @@ func getAlertPatches(alert v1.Alert, context string) []patch {
 		patchOperations = append(patchOperations, patch{
 			Op:    "add",
 			Path:  fmt.Sprintf("%s/alert/for", context),
-			Value: "1m",
+			Value: alertFor,
 		})
 	}
 	if alert.Step == "" {
 		patchOperations = append(patchOperations, patch{
 			Op:    "add",
 			Path:  fmt.Sprintf("%s/alert/step", context),
-			Value: "1m",
+			Value: alertStep,
+		})
+	}
+	return patchOperations
+}
+
+// The document's default alert metadata and documentation are merged key by key into the indicator's,
+// so they replace the indicator's whole alert and documentation.
+func getMergedDefaultPatches(indicator v1.IndicatorSpec, defaults v1.DocumentDefaults, context string) []patch {
+	defaulted := indicator
+	defaulted.Thresholds = nil
+	defaults.ApplyTo(&defaulted)
+
+	var patchOperations []patch
+	if !reflect.DeepEqual(defaulted.Alert, indicator.Alert) {
+		patchOperations = append(patchOperations, patch{
+			Op:    "add",
+			Path:  fmt.Sprintf("%s/alert", context),
+			Value: defaulted.Alert,
+		})
+	}
+	if !reflect.DeepEqual(defaulted.Documentation, indicator.Documentation) {
+		patchOperations = append(patchOperations, patch{
+			Op:    "add",
+			Path:  fmt.Sprintf("%s/documentation", context),
+			Value: defaulted.Documentation,
 		})
 	}
 	return patchOperations
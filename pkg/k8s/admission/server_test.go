@@ -374,7 +374,7 @@ func TestValidators(t *testing.T) {
 				t.Errorf("unable to decode resp body: %s", err)
 			}
 
-			g.Expect(actualResp.Response.Result.Message).To(ContainSubstring("IndicatorSpec.thresholds.operator in body should be one of [lt lte gt gte eq neq]"))
+			g.Expect(actualResp.Response.Result.Message).To(ContainSubstring("IndicatorSpec.thresholds.operator in body should be one of [lt lte gt gte eq neq between outside]"))
 			g.Expect(actualResp.Response.Allowed).To(BeFalse())
 		})
 
@@ -560,6 +560,50 @@ func TestDefaultValues(t *testing.T) {
 			g.Expect(actualResp.Response.Patch).To(MatchJSON(patch))
 		})
 
+		t.Run("patches default alert `for` and `step` without replacing the alert metadata", func(t *testing.T) {
+			g := NewGomegaWithT(t)
+
+			server := startServer(g)
+			defer func() {
+				_ = server.Close()
+			}()
+
+			reqBody := newIndicatorRequest("CREATE", `{
+						    "name": "latency",
+						    "promql": "rate(apiserver_request_count[5m]) * 60",
+							"presentation": { 
+								"chartType" : "step", 
+								"currentValue" : true,
+								"frequency": 10,
+								"labels": ["pod"]
+							},
+							"thresholds": [
+								{
+									"operator": "gt",
+									"value": 12,
+									"level": "critical",
+									"alert": { "owner": "a-team" }
+								}
+							]
+						  }`)
+			resp, err := http.Post(fmt.Sprintf("http://%s/defaults/indicator", server.Addr()), "application/json", reqBody)
+			g.Expect(err).To(BeNil())
+			g.Expect(resp.StatusCode).To(Equal(200))
+
+			var actualResp v1beta1.AdmissionReview
+			err = json.NewDecoder(resp.Body).Decode(&actualResp)
+			if err != nil {
+				t.Errorf("unable to decode resp body: %s", err)
+			}
+
+			patch := []byte(`[
+				{"op":"add","path":"/spec/thresholds/0/alert/for","value":"1m"},
+				{"op":"add","path":"/spec/thresholds/0/alert/step","value":"1m"}
+			]`)
+			g.Expect(actualResp.Response.Patch).NotTo(BeNil())
+			g.Expect(actualResp.Response.Patch).To(MatchJSON(patch))
+		})
+
 		t.Run("patches default alert for multiple thresholds", func(t *testing.T) {
 			g := NewGomegaWithT(t)
 
@@ -1132,6 +1176,54 @@ func TestDefaultValues(t *testing.T) {
 			g.Expect(actualResp.Response.Patch).To(unmarshalledmatchers.ContainUnorderedJSON(patch))
 		})
 
+		t.Run("patches the document's defaults into its indicators", func(t *testing.T) {
+			g := NewGomegaWithT(t)
+
+			server := startServer(g)
+			defer func() {
+				_ = server.Close()
+			}()
+
+			reqBody := newIndicatorDocumentRequest("UPDATE", `{
+							"product": {"name":"uaa", "version":"v1.2.3"},
+							"defaults": {
+								"alert": {"for": "10m", "step": "5m", "owner": "uaa-team"},
+								"presentation": {"chartType": "bar", "units": "ms"},
+								"documentation": {"thresholdNote": "Tuned for production"}
+							},
+							"layout": {
+								"title": "UAA",
+								"sections":[{"title": "Metrics", "indicators": ["latency"]}]
+							},
+							"indicators": [{
+								"name": "latency",
+								"promql": "latency",
+								"thresholds": [{"level": "critical", "operator": "gt", "value": 100, "alert": {"step": "1m"}}],
+								"presentation": {"frequency": 10, "labels": ["pod"]},
+								"documentation": {"title": "Latency"}
+							}]
+						  }`, "{}")
+			resp, err := http.Post(fmt.Sprintf("http://%s/defaults/indicatordocument", server.Addr()), "application/json", reqBody)
+			g.Expect(err).To(BeNil())
+			g.Expect(resp.StatusCode).To(Equal(200))
+
+			var actualResp v1beta1.AdmissionReview
+			err = json.NewDecoder(resp.Body).Decode(&actualResp)
+			if err != nil {
+				t.Errorf("unable to decode resp body: %s", err)
+			}
+
+			patch := []byte(`[
+{"op":"add","path":"/spec/indicators/0/thresholds/0/alert/for","value":"10m"},
+{"op":"add","path":"/spec/indicators/0/presentation/chartType","value":"bar"},
+{"op":"add","path":"/spec/indicators/0/presentation/currentValue","value":false},
+{"op":"add","path":"/spec/indicators/0/presentation/units","value":"ms"},
+{"op":"add","path":"/spec/indicators/0/alert","value":{"owner":"uaa-team"}},
+{"op":"add","path":"/spec/indicators/0/documentation","value":{"title":"Latency","thresholdNote":"Tuned for production"}}]`)
+			g.Expect(actualResp.Response.Patch).NotTo(BeNil())
+			g.Expect(actualResp.Response.Patch).To(MatchJSON(patch))
+		})
+
 		t.Run("does not patch noop", func(t *testing.T) {
 			g := NewGomegaWithT(t)
 
@@ -0,0 +1,115 @@
+package v1
+
+import (
+	"encoding/json"
+)
+
+// Statuses an indicator can have besides the levels of its thresholds.
+const (
+	HealthyStatus   = "HEALTHY"
+	UndefinedStatus = "UNDEFINED"
+	UnknownStatus   = "UNKNOWN"
+)
+
+type CompositeRule string
+
+const (
+	AnyRule   CompositeRule = "any"
+	AllRule   CompositeRule = "all"
+	WorstRule CompositeRule = "worst"
+	BestRule  CompositeRule = "best"
+)
+
+// Composite derives the status of an indicator from the statuses of other indicators in the same
+// document, instead of from PromQL and thresholds of its own:
+//   - any is Level when any of the indicators is at least as severe as Level, and HEALTHY otherwise
+//   - all is Level when all of the indicators are at least as severe as Level, and HEALTHY otherwise
+//   - worst is the most severe status of the indicators
+//   - best is the least severe status of the indicators
+//
+// Indicators without a status, or with an UNKNOWN or UNDEFINED status, are left out. If none are
+// left, the status is UNKNOWN.
+type Composite struct {
+	Rule       CompositeRule `json:"rule"`
+	Level      string        `json:"level,omitempty"`
+	Indicators []string      `json:"indicators"`
+}
+
+func (is IndicatorSpec) IsComposite() bool {
+	return is.Composite != nil
+}
+
+// MarshalJSON leaves out the promql of composite indicators, which have none.
+func (is IndicatorSpec) MarshalJSON() ([]byte, error) {
+	type indicatorSpec IndicatorSpec
+	if is.IsComposite() && is.PromQL == "" {
+		return json.Marshal(struct {
+			indicatorSpec
+			PromQL string `json:"promql,omitempty"`
+		}{indicatorSpec: indicatorSpec(is)})
+	}
+	return json.Marshal(indicatorSpec(is))
+}
+
+// Evaluate returns the status of the composite given the statuses of indicators, keyed by name.
+func (c Composite) Evaluate(statuses map[string]string) string {
+	var known []string
+	for _, name := range c.Indicators {
+		status, ok := statuses[name]
+		if !ok || status == "" || status == UnknownStatus || status == UndefinedStatus {
+			continue
+		}
+		known = append(known, status)
+	}
+	if len(known) == 0 {
+		return UnknownStatus
+	}
+
+	switch c.Rule {
+	case AnyRule, AllRule:
+		breached := 0
+		for _, status := range known {
+			if status == c.Level || StatusSeverity(status) > StatusSeverity(c.Level) {
+				breached++
+			}
+		}
+		if (c.Rule == AnyRule && breached > 0) || (c.Rule == AllRule && breached == len(known)) {
+			return c.Level
+		}
+		return HealthyStatus
+	case WorstRule, BestRule:
+		result := known[0]
+		for _, status := range known[1:] {
+			if MoreSevere(status, result) == (c.Rule == WorstRule) {
+				result = status
+			}
+		}
+		return result
+	default:
+		return UnknownStatus
+	}
+}
+
+// MoreSevere reports whether status a is more severe than status b. critical is the most severe,
+// followed by warning, any other threshold level and finally HEALTHY. Other levels are ordered
+// alphabetically, so that the result is deterministic.
+func MoreSevere(a, b string) bool {
+	if StatusSeverity(a) != StatusSeverity(b) {
+		return StatusSeverity(a) > StatusSeverity(b)
+	}
+	return a < b
+}
+
+// StatusSeverity orders statuses by severity, from HEALTHY at 0 to critical at 3.
+func StatusSeverity(status string) int {
+	switch status {
+	case HealthyStatus:
+		return 0
+	case "warning":
+		return 2
+	case "critical":
+		return 3
+	default:
+		return 1
+	}
+}
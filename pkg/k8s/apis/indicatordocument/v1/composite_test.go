@@ -0,0 +1,52 @@
+package v1_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal/monitoring-indicator-protocol/pkg/k8s/apis/indicatordocument/v1"
+)
+
+func TestCompositeEvaluate(t *testing.T) {
+	statuses := map[string]string{
+		"healthy":   "HEALTHY",
+		"warning":   "warning",
+		"critical":  "critical",
+		"custom":    "degraded",
+		"unknown":   "UNKNOWN",
+		"undefined": "UNDEFINED",
+	}
+
+	cases := []struct {
+		description string
+		composite   v1.Composite
+		status      string
+	}{
+		{"any is the level if an indicator is at least as severe",
+			v1.Composite{Rule: v1.AnyRule, Level: "warning", Indicators: []string{"healthy", "critical"}}, "warning"},
+		{"any is healthy if no indicator is as severe",
+			v1.Composite{Rule: v1.AnyRule, Level: "critical", Indicators: []string{"healthy", "warning"}}, "HEALTHY"},
+		{"all is the level if every indicator is at least as severe",
+			v1.Composite{Rule: v1.AllRule, Level: "warning", Indicators: []string{"warning", "critical"}}, "warning"},
+		{"all is healthy if an indicator is less severe",
+			v1.Composite{Rule: v1.AllRule, Level: "warning", Indicators: []string{"warning", "healthy"}}, "HEALTHY"},
+		{"worst is the most severe status",
+			v1.Composite{Rule: v1.WorstRule, Indicators: []string{"custom", "critical", "healthy"}}, "critical"},
+		{"other levels are more severe than healthy and less severe than warning",
+			v1.Composite{Rule: v1.WorstRule, Indicators: []string{"custom", "healthy"}}, "degraded"},
+		{"best is the least severe status",
+			v1.Composite{Rule: v1.BestRule, Indicators: []string{"custom", "warning", "critical"}}, "degraded"},
+		{"unknown, undefined and missing statuses are left out",
+			v1.Composite{Rule: v1.BestRule, Indicators: []string{"unknown", "undefined", "missing", "warning"}}, "warning"},
+		{"the status is unknown without any known status",
+			v1.Composite{Rule: v1.WorstRule, Indicators: []string{"unknown", "missing"}}, "UNKNOWN"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			g.Expect(c.composite.Evaluate(statuses)).To(Equal(c.status))
+		})
+	}
+}
@@ -4,14 +4,21 @@ import (
 	"fmt"
 )
 
-// If the given document is missing data, fills it in with sane defaults. Populates the layout as
+// If the given document is missing data, fills it in with sane defaults. Indicators are first defaulted
+// by the document's spec.defaults, if it has any. Populates the layout as
 // the standard SLI/KLI/Metrics three-row setup. Defaults the title of the layout to "<name> - <version>".
-// Defaults the alert to `[1m]` steps. Ensures that some values, for example chart's labels, are [] instead of nil.
+// Defaults the alert to `[1m]` steps and SLO windows to 30 days. Ensures that some values, for example chart's labels, are [] instead of nil.
 func PopulateDefaults(doc *IndicatorDocument) {
+	if doc.Spec.Defaults != nil {
+		for i := range doc.Spec.Indicators {
+			doc.Spec.Defaults.ApplyTo(&doc.Spec.Indicators[i])
+		}
+	}
 	populateDefaultAlert(doc)
 	populateDefaultPresentation(doc)
 	populateDefaultLayout(doc)
 	populateDefaultTitle(doc)
+	populateDefaultSLOWindow(doc)
 }
 
 func populateDefaultLayout(id *IndicatorDocument) {
@@ -86,3 +93,52 @@ func populateDefaultTitle(doc *IndicatorDocument) {
 			fmt.Sprintf("%s - %s", doc.Spec.Product.Name, doc.Spec.Product.Version)
 	}
 }
+
+func populateDefaultSLOWindow(doc *IndicatorDocument) {
+	for i, slo := range doc.Spec.SLOs {
+		if slo.Window == "" {
+			doc.Spec.SLOs[i].Window = "30d"
+		}
+	}
+}
+
+// ApplyTo fills in the fields of the indicator that it doesn't set with the defaults.
+func (d DocumentDefaults) ApplyTo(is *IndicatorSpec) {
+	for t := range is.Thresholds {
+		alert := &is.Thresholds[t].Alert
+		if alert.For == "" {
+			alert.For = d.Alert.For
+		}
+		if alert.Step == "" {
+			alert.Step = d.Alert.Step
+		}
+	}
+
+	if is.Alert.RunbookURL == "" {
+		is.Alert.RunbookURL = d.Alert.RunbookURL
+	}
+	if is.Alert.Owner == "" {
+		is.Alert.Owner = d.Alert.Owner
+	}
+	is.Alert.Labels = mergeMaps(d.Alert.Labels, is.Alert.Labels)
+	is.Alert.Annotations = mergeMaps(d.Alert.Annotations, is.Alert.Annotations)
+
+	p := &is.Presentation
+	if p.ChartType == UndefinedChart {
+		p.ChartType = d.Presentation.ChartType
+	}
+	if d.Presentation.CurrentValue {
+		p.CurrentValue = true
+	}
+	if p.Frequency == 0 {
+		p.Frequency = d.Presentation.Frequency
+	}
+	if p.Labels == nil && d.Presentation.Labels != nil {
+		p.Labels = append([]string{}, d.Presentation.Labels...)
+	}
+	if p.Units == "" {
+		p.Units = d.Presentation.Units
+	}
+
+	is.Documentation = mergeMaps(d.Documentation, is.Documentation)
+}
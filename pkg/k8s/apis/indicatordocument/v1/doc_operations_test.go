@@ -394,6 +394,106 @@ func TestPopulateDefaults(t *testing.T) {
 		})
 	})
 
+	t.Run("Document defaults", func(t *testing.T) {
+		t.Run("applies the document's defaults to indicators that don't set the field", func(t *testing.T) {
+			g := NewGomegaWithT(t)
+
+			doc := v1.IndicatorDocument{
+				Spec: v1.IndicatorDocumentSpec{
+					Defaults: &v1.DocumentDefaults{
+						Alert: v1.Alert{
+							For:  "10m",
+							Step: "5m",
+							AlertMetadata: v1.AlertMetadata{
+								Labels: map[string]string{"team": "uaa", "severity": "page"},
+								Owner:  "uaa-team",
+							},
+						},
+						Presentation: v1.Presentation{
+							ChartType: v1.BarChart,
+							Units:     "ms",
+							Labels:    []string{"pod"},
+						},
+						Documentation: map[string]string{"thresholdNote": "Tuned for production"},
+					},
+					Indicators: []v1.IndicatorSpec{
+						{
+							Name:   "defaulted",
+							PromQL: "latency",
+							Thresholds: []v1.Threshold{
+								{Level: "critical", Operator: v1.GreaterThan, Value: 100},
+							},
+						},
+						{
+							Name:   "overridden",
+							PromQL: "latency",
+							Thresholds: []v1.Threshold{
+								{Level: "critical", Operator: v1.GreaterThan, Value: 100, Alert: v1.Alert{Step: "1m"}},
+							},
+							Alert: v1.AlertMetadata{
+								Labels: map[string]string{"severity": "ticket"},
+							},
+							Presentation: v1.Presentation{
+								ChartType: v1.StepChart,
+								Units:     "s",
+							},
+							Documentation: map[string]string{"thresholdNote": "Tuned for staging"},
+						},
+					},
+				},
+			}
+
+			v1.PopulateDefaults(&doc)
+
+			defaulted := doc.Spec.Indicators[0]
+			g.Expect(defaulted.Thresholds[0].Alert.For).To(Equal("10m"))
+			g.Expect(defaulted.Thresholds[0].Alert.Step).To(Equal("5m"))
+			g.Expect(defaulted.Alert).To(Equal(v1.AlertMetadata{
+				Labels: map[string]string{"team": "uaa", "severity": "page"},
+				Owner:  "uaa-team",
+			}))
+			g.Expect(defaulted.Presentation).To(Equal(v1.Presentation{
+				ChartType: v1.BarChart,
+				Units:     "ms",
+				Labels:    []string{"pod"},
+			}))
+			g.Expect(defaulted.Documentation).To(Equal(map[string]string{"thresholdNote": "Tuned for production"}))
+
+			overridden := doc.Spec.Indicators[1]
+			g.Expect(overridden.Thresholds[0].Alert.For).To(Equal("10m"))
+			g.Expect(overridden.Thresholds[0].Alert.Step).To(Equal("1m"))
+			g.Expect(overridden.Alert.Labels).To(Equal(map[string]string{"team": "uaa", "severity": "ticket"}))
+			g.Expect(overridden.Presentation.ChartType).To(Equal(v1.StepChart))
+			g.Expect(overridden.Presentation.Units).To(Equal("s"))
+			g.Expect(overridden.Documentation).To(Equal(map[string]string{"thresholdNote": "Tuned for staging"}))
+		})
+
+		t.Run("falls back to the built-in defaults", func(t *testing.T) {
+			g := NewGomegaWithT(t)
+
+			doc := v1.IndicatorDocument{
+				Spec: v1.IndicatorDocumentSpec{
+					Defaults: &v1.DocumentDefaults{
+						Alert: v1.Alert{For: "10m"},
+					},
+					Indicators: []v1.IndicatorSpec{{
+						Name:   "latency",
+						PromQL: "latency",
+						Thresholds: []v1.Threshold{
+							{Level: "critical", Operator: v1.GreaterThan, Value: 100},
+						},
+					}},
+				},
+			}
+
+			v1.PopulateDefaults(&doc)
+
+			g.Expect(doc.Spec.Indicators[0].Thresholds[0].Alert).To(Equal(v1.Alert{For: "10m", Step: "1m"}))
+			g.Expect(doc.Spec.Indicators[0].Presentation.ChartType).To(Equal(v1.StepChart))
+			g.Expect(doc.Spec.Indicators[0].Presentation.Units).To(Equal("short"))
+		})
+	})
+
 	t.Run("If fully complete, not changed", func(t *testing.T) {
 		g := NewGomegaWithT(t)
 
@@ -51,9 +51,61 @@ func getMetadataSHA(metadata map[string]string) [20]byte {
 
 // IndicatorDocumentSpec is the spec for a IndicatorDocument resource
 type IndicatorDocumentSpec struct {
-	Product    Product         `json:"product"`
-	Indicators []IndicatorSpec `json:"indicators,omitempty"`
-	Layout     Layout          `json:"layout,omitempty"`
+	Product            Product                 `json:"product"`
+	Defaults           *DocumentDefaults       `json:"defaults,omitempty"`
+	Imports            []Import                `json:"imports,omitempty"`
+	Indicators         []IndicatorSpec         `json:"indicators,omitempty"`
+	IndicatorTemplates []IndicatorTemplate     `json:"indicatorTemplates,omitempty"`
+	SLOs               []ServiceLevelObjective `json:"slos,omitempty"`
+	Layout             Layout                  `json:"layout,omitempty"`
+}
+
+// DocumentDefaults apply to every indicator of the document that doesn't set the field itself. Alert
+// for and step apply to each threshold, the alert's metadata and documentation are merged key by key
+// with the indicator's. Since an indicator's currentValue can't be told apart from an unset one, a
+// currentValue default of true applies to every indicator. Imported indicators take the defaults of
+// their own document first.
+type DocumentDefaults struct {
+	Alert         Alert             `json:"alert,omitempty"`
+	Presentation  Presentation      `json:"presentation,omitempty"`
+	Documentation map[string]string `json:"documentation,omitempty"`
+}
+
+// Import adds the indicators of another document, such as a shared indicator library, to this one
+// when the document is read. Thresholds replaces the thresholds of imported indicators, keyed by
+// indicator name.
+type Import struct {
+	Path       string                 `json:"path"`
+	Thresholds map[string][]Threshold `json:"thresholds,omitempty"`
+}
+
+// IndicatorTemplate is expanded into one indicator per parameter set when a document is read.
+// Every `{{name}}` placeholder in the string fields of the indicator is replaced by the value
+// of that parameter.
+type IndicatorTemplate struct {
+	Indicator  IndicatorSpec       `json:"indicator"`
+	Parameters []map[string]string `json:"parameters"`
+}
+
+// ServiceLevelObjective describes an objective over the ratio of good events to total events.
+// Both queries must contain `$window`, which is replaced by each of the burn rate windows when
+// generating recording rules. The objective is a percentage, e.g. 99.9.
+type ServiceLevelObjective struct {
+	Name          string            `json:"name"`
+	GoodEvents    string            `json:"goodEvents"`
+	TotalEvents   string            `json:"totalEvents"`
+	Objective     float64           `json:"objective"`
+	Window        string            `json:"window,omitempty"`
+	Documentation map[string]string `json:"documentation,omitempty"`
+}
+
+func (ids IndicatorDocumentSpec) SLO(name string) *ServiceLevelObjective {
+	for _, slo := range ids.SLOs {
+		if slo.Name == name {
+			return &slo
+		}
+	}
+	return nil
 }
 
 type Product struct {
@@ -77,9 +129,29 @@ type IndicatorSpec struct {
 	Name          string            `json:"name"`
 	Type          IndicatorType     `json:"type"`
 	PromQL        string            `json:"promql"`
+	Composite     *Composite        `json:"composite,omitempty"`
 	Thresholds    []Threshold       `json:"thresholds,omitempty"`
+	Alert         AlertMetadata     `json:"alert,omitempty"`
 	Documentation map[string]string `json:"documentation,omitempty"`
 	Presentation  Presentation      `json:"presentation,omitempty"`
+	// Deprecated indicators are still evaluated, but are marked in docs and dashboards and reported as
+	// warnings when registered. ReplacedBy names the indicator of the same document to use instead.
+	Deprecated         bool   `json:"deprecated,omitempty"`
+	DeprecationMessage string `json:"deprecationMessage,omitempty"`
+	ReplacedBy         string `json:"replacedBy,omitempty"`
+}
+
+// DeprecationNotice describes what replaces a deprecated indicator and why it is deprecated, e.g.
+// "Use request_latency instead. Latency is measured per request now."
+func (is IndicatorSpec) DeprecationNotice() string {
+	var notice []string
+	if is.ReplacedBy != "" {
+		notice = append(notice, fmt.Sprintf("Use %s instead.", is.ReplacedBy))
+	}
+	if is.DeprecationMessage != "" {
+		notice = append(notice, is.DeprecationMessage)
+	}
+	return strings.Join(notice, " ")
 }
 
 type IndicatorType int
@@ -143,6 +215,8 @@ func IndicatorTypeFromString(s string) IndicatorType {
 type IndicatorStatus struct {
 	Phase     string      `json:"phase"`
 	UpdatedAt metav1.Time `json:"updatedAt"`
+	// Since is when the indicator last changed to its phase.
+	Since metav1.Time `json:"since"`
 }
 
 type Presentation struct {
@@ -216,17 +290,112 @@ func ChartTypeFromString(s string) ChartType {
 }
 
 type Alert struct {
-	For  string `json:"for,omitempty"`
-	Step string `json:"step,omitempty"`
+	For           string `json:"for,omitempty"`
+	Step          string `json:"step,omitempty"`
+	AlertMetadata `json:",inline"`
+}
+
+// AlertMetadata is added to the alerts generated for thresholds. Labels are added to the alert's
+// labels, and Owner as the `owner` label, so that alerts can be routed by them. Annotations are added
+// to the alert's annotations, after the indicator's documentation, and RunbookURL as the
+// `runbook_url` annotation. Annotations can use Prometheus alert templating, e.g. `{{ $value }}`.
+//
+// An indicator's alert metadata applies to all of its thresholds, and a threshold's alert metadata
+// overrides it.
+type AlertMetadata struct {
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	RunbookURL  string            `json:"runbookURL,omitempty"`
+	Owner       string            `json:"owner,omitempty"`
+}
+
+func (am AlertMetadata) IsEmpty() bool {
+	return len(am.Labels) == 0 && len(am.Annotations) == 0 && am.RunbookURL == "" && am.Owner == ""
+}
+
+// AlertMetadataFor merges the alert metadata of the indicator and the given threshold.
+func (is IndicatorSpec) AlertMetadataFor(t Threshold) AlertMetadata {
+	merged := AlertMetadata{
+		RunbookURL: is.Alert.RunbookURL,
+		Owner:      is.Alert.Owner,
+	}
+	if t.Alert.RunbookURL != "" {
+		merged.RunbookURL = t.Alert.RunbookURL
+	}
+	if t.Alert.Owner != "" {
+		merged.Owner = t.Alert.Owner
+	}
+	merged.Labels = mergeMaps(is.Alert.Labels, t.Alert.Labels)
+	merged.Annotations = mergeMaps(is.Alert.Annotations, t.Alert.Annotations)
+
+	return merged
+}
+
+func mergeMaps(maps ...map[string]string) map[string]string {
+	var merged map[string]string
+	for _, m := range maps {
+		for k, v := range m {
+			if merged == nil {
+				merged = make(map[string]string)
+			}
+			merged[k] = v
+		}
+	}
+	return merged
 }
 
+// Threshold is breached when the indicator's value satisfies the operator. Single bound operators
+// (lt, lte, eq, neq, gte, gt) compare against Value, while range operators (between, outside)
+// compare against the inclusive Lower and Upper bounds.
+//
+// An optional Recovery value adds hysteresis to single bound, non-equality operators: once breached,
+// the threshold stays breached until the value crosses Recovery rather than Value.
+//
+// Matchers scope the threshold to the series whose labels equal every given value. For the series
+// it matches, a scoped threshold overrides the unscoped thresholds of the same level.
 type Threshold struct {
 	Level    string            `json:"level"`
 	Operator ThresholdOperator `json:"operator"`
 	Value    float64           `json:"value"`
+	Lower    *float64          `json:"lower,omitempty"`
+	Upper    *float64          `json:"upper,omitempty"`
+	Recovery *float64          `json:"recovery,omitempty"`
+	Matchers map[string]string `json:"matchers,omitempty"`
 	Alert    Alert             `json:"alert,omitempty"`
 }
 
+func (t Threshold) IsRange() bool {
+	return t.Operator == Between || t.Operator == Outside
+}
+
+func (t Threshold) IsScoped() bool {
+	return len(t.Matchers) > 0
+}
+
+// Matches reports whether a series with the given labels satisfies all of the threshold's matchers.
+func (t Threshold) Matches(labels map[string]string) bool {
+	for k, v := range t.Matchers {
+		if labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// AppliesTo reports whether the threshold should be evaluated for a series with the given labels,
+// taking into account the scoped thresholds of the same level which override it.
+func (t Threshold) AppliesTo(labels map[string]string, thresholds []Threshold) bool {
+	if t.IsScoped() {
+		return t.Matches(labels)
+	}
+	for _, other := range thresholds {
+		if other.IsScoped() && other.Level == t.Level && other.Matches(labels) {
+			return false
+		}
+	}
+	return true
+}
+
 type ThresholdOperator int
 
 func (ot ThresholdOperator) MarshalJSON() ([]byte, error) {
@@ -255,6 +424,8 @@ const (
 	NotEqualTo
 	GreaterThanOrEqualTo
 	GreaterThan
+	Between
+	Outside
 )
 
 func unmarshalComparatorFromString(operator string) ThresholdOperator {
@@ -271,6 +442,10 @@ func unmarshalComparatorFromString(operator string) ThresholdOperator {
 		return GreaterThanOrEqualTo
 	case `"gt"`:
 		return GreaterThan
+	case `"between"`:
+		return Between
+	case `"outside"`:
+		return Outside
 	default:
 		return UndefinedOperator
 	}
@@ -290,6 +465,10 @@ func GetComparatorFromString(operator string) ThresholdOperator {
 		return GreaterThanOrEqualTo
 	case "gt":
 		return GreaterThan
+	case "between":
+		return Between
+	case "outside":
+		return Outside
 	default:
 		return UndefinedOperator
 	}
@@ -309,11 +488,16 @@ func GetComparatorAbbrev(op ThresholdOperator) string {
 		return "gte"
 	case GreaterThan:
 		return "gt"
+	case Between:
+		return "between"
+	case Outside:
+		return "outside"
 	default:
 		return ""
 	}
 }
 
+// Range operators have no symbol, an empty string is returned for them.
 func GetComparatorSymbol(op ThresholdOperator) string {
 	switch op {
 	case LessThan:
@@ -1,21 +1,34 @@
 package v1
 
 import (
-	"errors"
 	"fmt"
+	"net/url"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/ghodss/yaml"
+	openapierrors "github.com/go-openapi/errors"
 	"github.com/go-openapi/spec"
 	"github.com/go-openapi/strfmt"
 	"github.com/go-openapi/validate"
+	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/promql"
 
 	"github.com/pivotal/monitoring-indicator-protocol/pkg/asset"
+	"github.com/pivotal/monitoring-indicator-protocol/pkg/promql_lint"
 )
 
 func (doc *IndicatorDocument) Validate() []error {
+	es, _ := doc.ValidateWithWarnings()
+	return es
+}
+
+// ValidateWithWarnings also returns the problems found by the PromQL lint rules that don't make a
+// document invalid.
+func (doc *IndicatorDocument) ValidateWithWarnings() ([]error, []error) {
 	es := make([]error, 0)
+	var warnings []error
 
 	// Instead of duplicated validation code, we can just marshal the document and then validate its bytes
 	docBytes, err := yaml.Marshal(doc)
@@ -30,27 +43,60 @@ func (doc *IndicatorDocument) Validate() []error {
 
 	for k := range doc.ObjectMeta.Labels {
 		if strings.ToLower(k) == "step" {
-			es = append(es, errors.New("metadata cannot contain `step` key (see https://github.com/pivotal/monitoring-indicator-protocol/wiki#metadata)"))
+			es = append(es, NewValidationError("metadata.labels."+k, "metadata cannot contain `step` key (see https://github.com/pivotal/monitoring-indicator-protocol/wiki#metadata)"))
+		}
+	}
+
+	if doc.Spec.Defaults != nil {
+		for _, e := range doc.Spec.Defaults.Alert.validate() {
+			es = append(es, NewValidationError("spec.defaults.alert", "defaults.alert %s", e))
 		}
 	}
 
 	for idx, i := range doc.Spec.Indicators {
-		es = append(es, i.Validate(idx, doc.APIVersion)...)
+		errs, ws := i.ValidateWithWarnings(idx, doc.APIVersion)
+		es = append(es, errs...)
+		warnings = append(warnings, ws...)
+	}
+
+	es = append(es, doc.validateCompositeReferences()...)
+
+	errs, ws := doc.validateDeprecations()
+	es = append(es, errs...)
+	warnings = append(warnings, ws...)
+
+	sloNames := make(map[string]bool)
+	for idx, slo := range doc.Spec.SLOs {
+		es = append(es, slo.Validate(idx)...)
+		if sloNames[slo.Name] {
+			es = append(es, NewValidationError(fmt.Sprintf("spec.slos[%d].name", idx), "slos[%d].name must be unique, %s is already defined", idx, slo.Name))
+		}
+		sloNames[slo.Name] = true
 	}
 
 	for sectionIdx, section := range doc.Spec.Layout.Sections {
 		for idx, indicatorName := range section.Indicators {
 			if indicator := doc.Indicator(indicatorName); indicator == nil {
-				es = append(es, fmt.Errorf("layout sections[%d] indicators[%d] references a non-existent indicator", sectionIdx, idx))
+				es = append(es, NewValidationError(
+					fmt.Sprintf("spec.layout.sections[%d].indicators[%d]", sectionIdx, idx),
+					"layout sections[%d] indicators[%d] references a non-existent indicator", sectionIdx, idx,
+				))
 			}
 		}
 	}
 
-	return es
+	return es, warnings
 }
 
 func (is *IndicatorSpec) Validate(indicatorIndex int, apiVersion string) []error {
+	es, _ := is.ValidateWithWarnings(indicatorIndex, apiVersion)
+	return es
+}
+
+func (is *IndicatorSpec) ValidateWithWarnings(indicatorIndex int, apiVersion string) ([]error, []error) {
 	var es []error
+	var warnings []error
+	path := fmt.Sprintf("spec.indicators[%d]", indicatorIndex)
 
 	indicatorBytes, err := yaml.Marshal(is)
 	if err != nil {
@@ -59,22 +105,257 @@ func (is *IndicatorSpec) Validate(indicatorIndex int, apiVersion string) []error
 
 	errs, valid := ValidateBytesBySchema(indicatorBytes, "IndicatorSpec")
 	for i, err := range errs {
-		errs[i] = fmt.Errorf("indicators[%d] is invalid by schema: %s", indicatorIndex, err)
+		fieldPath := path
+		if e, ok := err.(*ValidationError); ok {
+			fieldPath = joinPath(path, e.Path)
+		}
+		errs[i] = NewValidationError(fieldPath, "indicators[%d] is invalid by schema: %s", indicatorIndex, err)
 	}
 
 	if !valid {
 		es = append(es, errs...)
 	}
 
+	for _, e := range is.Alert.validate() {
+		es = append(es, NewValidationError(path+".alert", "indicators[%d].alert %s", indicatorIndex, e))
+	}
+
+	if is.IsComposite() {
+		return append(es, is.validateComposite(indicatorIndex)...), warnings
+	}
+
 	_, err = promql.ParseExpr(is.PromQL)
 	if err != nil {
-		es = append(es, fmt.Errorf("indicators[%d].promql should be valid promql (see https://prometheus.io/docs/)", indicatorIndex))
+		es = append(es, NewValidationError(path+".promql", "indicators[%d].promql should be valid promql (see https://prometheus.io/docs/)", indicatorIndex))
+	}
+
+	for _, p := range promql_lint.Lint(is.lintQuery()) {
+		err := NewValidationError(path+".promql", "indicators[%d].promql %s", indicatorIndex, p)
+		if p.Severity == promql_lint.Error {
+			es = append(es, err)
+		} else {
+			warnings = append(warnings, err)
+		}
+	}
+
+	for thresholdIndex, t := range is.Thresholds {
+		for _, e := range t.validate() {
+			es = append(es, NewValidationError(
+				fmt.Sprintf("%s.thresholds[%d]", path, thresholdIndex),
+				"indicators[%d].thresholds[%d] %s", indicatorIndex, thresholdIndex, e,
+			))
+		}
+	}
+
+	return es, warnings
+}
+
+func (is *IndicatorSpec) validateComposite(indicatorIndex int) []error {
+	var es []error
+	path := fmt.Sprintf("spec.indicators[%d]", indicatorIndex)
+
+	if is.PromQL != "" {
+		es = append(es, NewValidationError(path+".promql", "indicators[%d] is composite and cannot have promql", indicatorIndex))
+	}
+	if len(is.Thresholds) > 0 {
+		es = append(es, NewValidationError(path+".thresholds", "indicators[%d] is composite and cannot have thresholds", indicatorIndex))
+	}
+	if (is.Composite.Rule == AnyRule || is.Composite.Rule == AllRule) && is.Composite.Level == "" {
+		es = append(es, NewValidationError(path+".composite.level", "indicators[%d].composite.level is required for the %s rule", indicatorIndex, is.Composite.Rule))
+	}
+
+	return es
+}
+
+// Composite indicators can only reference other, non-composite indicators of the same document.
+func (doc *IndicatorDocument) validateCompositeReferences() []error {
+	var es []error
+
+	for indicatorIdx, is := range doc.Spec.Indicators {
+		if !is.IsComposite() {
+			continue
+		}
+		for idx, name := range is.Composite.Indicators {
+			path := fmt.Sprintf("spec.indicators[%d].composite.indicators[%d]", indicatorIdx, idx)
+			member := doc.Indicator(name)
+			switch {
+			case member == nil:
+				es = append(es, NewValidationError(path, "indicators[%d].composite.indicators[%d] references a non-existent indicator %s", indicatorIdx, idx, name))
+			case member.IsComposite():
+				es = append(es, NewValidationError(path, "indicators[%d].composite.indicators[%d] references %s, which is composite itself", indicatorIdx, idx, name))
+			}
+		}
+	}
+
+	return es
+}
+
+// Deprecated indicators are reported as warnings, so that they are noticed when the document is
+// registered or linted. Replacements have to be other indicators of the same document.
+func (doc *IndicatorDocument) validateDeprecations() ([]error, []error) {
+	var es []error
+	var warnings []error
+
+	for idx, is := range doc.Spec.Indicators {
+		path := fmt.Sprintf("spec.indicators[%d]", idx)
+		if !is.Deprecated {
+			if is.ReplacedBy != "" {
+				es = append(es, NewValidationError(path+".replacedBy", "indicators[%d].replacedBy can only be set on deprecated indicators", idx))
+			}
+			if is.DeprecationMessage != "" {
+				es = append(es, NewValidationError(path+".deprecationMessage", "indicators[%d].deprecationMessage can only be set on deprecated indicators", idx))
+			}
+			continue
+		}
+
+		switch {
+		case is.ReplacedBy == is.Name:
+			es = append(es, NewValidationError(path+".replacedBy", "indicators[%d].replacedBy cannot reference the indicator itself", idx))
+		case is.ReplacedBy != "" && doc.Indicator(is.ReplacedBy) == nil:
+			es = append(es, NewValidationError(path+".replacedBy", "indicators[%d].replacedBy references a non-existent indicator %s", idx, is.ReplacedBy))
+		}
+
+		warning := fmt.Sprintf("indicators[%d] %s is deprecated", idx, is.Name)
+		if notice := is.DeprecationNotice(); notice != "" {
+			warning += ": " + notice
+		}
+		warnings = append(warnings, NewValidationError(path+".deprecated", "%s", warning))
+	}
+
+	return es, warnings
+}
+
+func (is *IndicatorSpec) lintQuery() promql_lint.Query {
+	q := promql_lint.Query{
+		PromQL:        is.PromQL,
+		Labels:        is.Presentation.Labels,
+		HasThresholds: len(is.Thresholds) > 0,
+	}
+
+	for _, t := range is.Thresholds {
+		step, err := model.ParseDuration(t.Alert.Step)
+		if err == nil && time.Duration(step) > q.Step {
+			q.Step = time.Duration(step)
+		}
+	}
+
+	return q
+}
+
+func (t Threshold) validate() []string {
+	var es []string
+
+	if t.IsRange() {
+		if t.Lower == nil || t.Upper == nil {
+			es = append(es, fmt.Sprintf("operator %s requires both lower and upper", GetComparatorAbbrev(t.Operator)))
+		} else if *t.Lower > *t.Upper {
+			es = append(es, "lower must not be greater than upper")
+		}
+	} else if t.Lower != nil || t.Upper != nil {
+		es = append(es, "lower and upper can only be used with the between and outside operators")
+	}
+
+	if t.Recovery != nil {
+		switch t.Operator {
+		case LessThan, LessThanOrEqualTo:
+			if *t.Recovery < t.Value {
+				es = append(es, "recovery must not be less than value for lt and lte operators")
+			}
+		case GreaterThan, GreaterThanOrEqualTo:
+			if *t.Recovery > t.Value {
+				es = append(es, "recovery must not be greater than value for gt and gte operators")
+			}
+		default:
+			es = append(es, "recovery can only be used with the lt, lte, gt and gte operators")
+		}
+	}
+
+	for _, k := range sortedKeys(t.Matchers) {
+		if !model.LabelName(k).IsValid() {
+			es = append(es, fmt.Sprintf("matcher %q is not a valid label name", k))
+		}
+	}
+
+	for _, e := range t.Alert.validate() {
+		es = append(es, "alert "+e)
+	}
+
+	return es
+}
+
+// Labels that the generated alerts already set.
+var reservedAlertLabels = map[string]bool{
+	"alertname": true,
+	"level":     true,
+	"product":   true,
+	"version":   true,
+}
+
+func (am AlertMetadata) validate() []string {
+	var es []string
+
+	for _, k := range sortedKeys(am.Labels) {
+		switch {
+		case !model.LabelName(k).IsValid():
+			es = append(es, fmt.Sprintf("label %q is not a valid label name", k))
+		case reservedAlertLabels[k]:
+			es = append(es, fmt.Sprintf("label %q is reserved, it is set on every generated alert", k))
+		}
+	}
+
+	if am.RunbookURL != "" {
+		u, err := url.Parse(am.RunbookURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			es = append(es, "runbookURL must be an absolute http or https URL")
+		}
+	}
+
+	return es
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (slo *ServiceLevelObjective) Validate(sloIndex int) []error {
+	var es []error
+	path := fmt.Sprintf("spec.slos[%d]", sloIndex)
+
+	queries := []struct {
+		field string
+		query string
+	}{
+		{"goodEvents", slo.GoodEvents},
+		{"totalEvents", slo.TotalEvents},
+	}
+	for _, q := range queries {
+		if !strings.Contains(q.query, "$window") {
+			es = append(es, NewValidationError(path+"."+q.field, "slos[%d].%s must contain $window so that it can be evaluated over each burn rate window", sloIndex, q.field))
+			continue
+		}
+		_, err := promql.ParseExpr(strings.Replace(q.query, "$window", "5m", -1))
+		if err != nil {
+			es = append(es, NewValidationError(path+"."+q.field, "slos[%d].%s should be valid promql (see https://prometheus.io/docs/)", sloIndex, q.field))
+		}
+	}
+
+	window, err := model.ParseDuration(slo.Window)
+	if err != nil {
+		es = append(es, NewValidationError(path+".window", "slos[%d].window should be a valid duration (e.g. 30d)", sloIndex))
+	} else if time.Duration(window) < time.Hour {
+		es = append(es, NewValidationError(path+".window", "slos[%d].window must be at least 1h", sloIndex))
 	}
 
 	return es
 }
 
-// Validates provided YAML is in correct v1 format by OpenAPI Schema
+// Validates provided YAML is in the correct format by OpenAPI Schema. Use the `IndicatorDocumentV2`
+// schema name to validate an indicatorprotocol.io/v2 document.
 func ValidateBytesBySchema(docBytes []byte, schemaName string) ([]error, bool) {
 	schemaBytes, err := asset.Asset("schemas.yml")
 	if err != nil {
@@ -82,8 +363,9 @@ func ValidateBytesBySchema(docBytes []byte, schemaName string) ([]error, bool) {
 	}
 
 	var schemaHolder struct {
-		IndicatorDocumentSchema spec.Schema `json:"IndicatorDocument"`
-		IndicatorSchema         spec.Schema `json:"IndicatorSpec"`
+		IndicatorDocumentSchema   spec.Schema `json:"IndicatorDocument"`
+		IndicatorSchema           spec.Schema `json:"IndicatorSpec"`
+		IndicatorDocumentV2Schema spec.Schema `json:"IndicatorDocumentV2"`
 	}
 	var rootSchema interface{}
 	err = yaml.Unmarshal(schemaBytes, &rootSchema)
@@ -102,6 +384,8 @@ func ValidateBytesBySchema(docBytes []byte, schemaName string) ([]error, bool) {
 		schema = schemaHolder.IndicatorDocumentSchema
 	case "IndicatorSpec":
 		schema = schemaHolder.IndicatorSchema
+	case "IndicatorDocumentV2":
+		schema = schemaHolder.IndicatorDocumentV2Schema
 	default:
 		return []error{fmt.Errorf("invalid schema name '%s'", schemaName)}, false
 	}
@@ -115,8 +399,19 @@ func ValidateBytesBySchema(docBytes []byte, schemaName string) ([]error, bool) {
 
 	errs := make([]error, 0)
 	for _, e := range validator.Validate(document).Errors {
-		errs = append(errs, errors.New(e.Error()))
+		errs = append(errs, schemaValidationError(e, schemaName))
 	}
 
 	return errs, validator.Validate(document).IsValid()
 }
+
+// The names of schema validation errors are the dotted path of the invalid field, starting with the
+// schema name. Array indices are not part of the name.
+func schemaValidationError(err error, schemaName string) error {
+	path := ""
+	if e, ok := err.(*openapierrors.Validation); ok {
+		path = strings.TrimPrefix(strings.TrimPrefix(e.Name, schemaName), ".")
+	}
+
+	return &ValidationError{Path: path, Message: err.Error()}
+}
@@ -1,7 +1,6 @@
 package v1_test
 
 import (
-	"errors"
 	"io/ioutil"
 	"testing"
 
@@ -94,7 +93,7 @@ func TestProduct(t *testing.T) {
 		es := document.Validate()
 
 		g.Expect(es).To(ContainElement(
-			errors.New("IndicatorDocument.spec.product.name in body should be at least 1 chars long"),
+			MatchError("IndicatorDocument.spec.product.name in body should be at least 1 chars long"),
 		))
 	})
 }
@@ -115,7 +114,7 @@ func TestVersion(t *testing.T) {
 		es := document.Validate()
 
 		g.Expect(es).To(ContainElement(
-			errors.New("IndicatorDocument.spec.product.version in body should be at least 1 chars long"),
+			MatchError("IndicatorDocument.spec.product.version in body should be at least 1 chars long"),
 		))
 	})
 }
@@ -132,7 +131,7 @@ func TestAPIVersion(t *testing.T) {
 
 		es := document.Validate()
 
-		g.Expect(es).To(ContainElement(errors.New("IndicatorDocument.apiVersion in body is required")))
+		g.Expect(es).To(ContainElement(MatchError("IndicatorDocument.apiVersion in body is required")))
 	})
 
 	t.Run("validation returns errors if APIVersion is not supported", func(t *testing.T) {
@@ -150,7 +149,7 @@ func TestAPIVersion(t *testing.T) {
 		es := document.Validate()
 
 		g.Expect(es).To(ContainElement(
-			errors.New("IndicatorDocument.apiVersion in body should be one of [indicatorprotocol.io/v1]"),
+			MatchError("IndicatorDocument.apiVersion in body should be one of [indicatorprotocol.io/v1]"),
 		))
 	})
 }
@@ -168,9 +167,9 @@ func TestSpec(t *testing.T) {
 		es := document.Validate()
 
 		g.Expect(es).To(ContainElement(
-			errors.New("IndicatorDocument.spec.product.name in body should be at least 1 chars long")))
+			MatchError("IndicatorDocument.spec.product.name in body should be at least 1 chars long")))
 		g.Expect(es).To(ContainElement(
-			errors.New("IndicatorDocument.spec.product.version in body should be at least 1 chars long")))
+			MatchError("IndicatorDocument.spec.product.version in body should be at least 1 chars long")))
 	})
 }
 
@@ -186,7 +185,7 @@ func TestKind(t *testing.T) {
 		es := document.Validate()
 
 		g.Expect(es).To(ContainElement(
-			errors.New("IndicatorDocument.kind in body should be one of [IndicatorDocument]"),
+			MatchError("IndicatorDocument.kind in body should be one of [IndicatorDocument]"),
 		))
 	})
 }
@@ -215,8 +214,8 @@ func TestIndicator(t *testing.T) {
 		es := document.Validate()
 
 		g.Expect(es).To(And(
-			ContainElement(errors.New("IndicatorDocument.spec.indicators.name in body should match '[a-zA-Z_:][a-zA-Z0-9_:]*'")),
-			ContainElement(errors.New("IndicatorDocument.spec.indicators.promql in body should be at least 1 chars long")),
+			ContainElement(MatchError("IndicatorDocument.spec.indicators.name in body should match '[a-zA-Z_:][a-zA-Z0-9_:]*'")),
+			ContainElement(MatchError("IndicatorDocument.spec.indicators.promql in body should be at least 1 chars long")),
 		))
 	})
 
@@ -241,7 +240,7 @@ func TestIndicator(t *testing.T) {
 		es := document.Validate()
 
 		g.Expect(es).To(
-			ContainElement(errors.New("IndicatorDocument.spec.indicators.promql in body should be at least 1 chars long")),
+			ContainElement(MatchError("IndicatorDocument.spec.indicators.promql in body should be at least 1 chars long")),
 		)
 	})
 
@@ -268,7 +267,7 @@ func TestIndicator(t *testing.T) {
 		es := document.Validate()
 
 		g.Expect(es).To(
-			ContainElement(errors.New("indicators[0].promql should be valid promql (see https://prometheus.io/docs/)")),
+			ContainElement(MatchError("indicators[0].promql should be valid promql (see https://prometheus.io/docs/)")),
 		)
 	})
 }
@@ -306,7 +305,7 @@ func TestLayout(t *testing.T) {
 		es := document.Validate()
 
 		g.Expect(es).To(ContainElement(
-			errors.New("layout sections[0] indicators[1] references a non-existent indicator"),
+			MatchError("layout sections[0] indicators[1] references a non-existent indicator"),
 		))
 	})
 }
@@ -330,7 +329,7 @@ func TestMetadata(t *testing.T) {
 		es := document.Validate()
 
 		g.Expect(es).To(ContainElement(
-			errors.New("metadata cannot contain `step` key (see https://github.com/pivotal/monitoring-indicator-protocol/wiki#metadata)"),
+			MatchError("metadata cannot contain `step` key (see https://github.com/pivotal/monitoring-indicator-protocol/wiki#metadata)"),
 		))
 	})
 
@@ -352,7 +351,7 @@ func TestMetadata(t *testing.T) {
 		es := document.Validate()
 
 		g.Expect(es).To(ContainElement(
-			errors.New("metadata cannot contain `step` key (see https://github.com/pivotal/monitoring-indicator-protocol/wiki#metadata)"),
+			MatchError("metadata cannot contain `step` key (see https://github.com/pivotal/monitoring-indicator-protocol/wiki#metadata)"),
 		))
 	})
 }
@@ -383,7 +382,233 @@ func TestThreshold(t *testing.T) {
 		es := document.Validate()
 
 		g.Expect(es).To(ContainElement(
-			errors.New("IndicatorDocument.spec.indicators.thresholds.operator in body should be one of [lt lte gt gte eq neq]"),
+			MatchError("IndicatorDocument.spec.indicators.thresholds.operator in body should be one of [lt lte gt gte eq neq between outside]"),
+		))
+	})
+}
+
+func TestRangeThreshold(t *testing.T) {
+	thresholdDocument := func(threshold v1.Threshold) v1.IndicatorDocument {
+		return v1.IndicatorDocument{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: api_versions.V1,
+				Kind:       "IndicatorDocument",
+			},
+			Spec: v1.IndicatorDocumentSpec{
+				Product: v1.Product{Name: "well-performing-component", Version: "0.0.1"},
+				Indicators: []v1.IndicatorSpec{{
+					Name:         "my_fair_indicator",
+					PromQL:       "rate(speech[5m])",
+					Thresholds:   []v1.Threshold{threshold},
+					Presentation: test_fixtures.DefaultPresentation(),
+				}},
+			},
+		}
+	}
+	float64Ptr := func(f float64) *float64 { return &f }
+
+	t.Run("validation returns no errors for valid range and recovery thresholds", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		document := thresholdDocument(v1.Threshold{
+			Level:    "warning",
+			Operator: v1.Between,
+			Lower:    float64Ptr(1),
+			Upper:    float64Ptr(2),
+		})
+		g.Expect(document.Validate()).To(BeEmpty())
+
+		document = thresholdDocument(v1.Threshold{
+			Level:    "warning",
+			Operator: v1.GreaterThan,
+			Value:    90,
+			Recovery: float64Ptr(80),
+		})
+		g.Expect(document.Validate()).To(BeEmpty())
+	})
+
+	t.Run("validation returns errors if a bound is missing", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		document := thresholdDocument(v1.Threshold{
+			Level:    "warning",
+			Operator: v1.Outside,
+			Lower:    float64Ptr(1),
+		})
+
+		es := document.Validate()
+
+		g.Expect(es).To(ConsistOf(MatchError("indicators[0].thresholds[0] operator outside requires both lower and upper")))
+	})
+
+	t.Run("validation returns errors if lower is greater than upper", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		document := thresholdDocument(v1.Threshold{
+			Level:    "warning",
+			Operator: v1.Between,
+			Lower:    float64Ptr(3),
+			Upper:    float64Ptr(2),
+		})
+
+		es := document.Validate()
+
+		g.Expect(es).To(ConsistOf(MatchError("indicators[0].thresholds[0] lower must not be greater than upper")))
+	})
+
+	t.Run("validation returns errors if bounds are used with a comparison operator", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		document := thresholdDocument(v1.Threshold{
+			Level:    "warning",
+			Operator: v1.LessThan,
+			Lower:    float64Ptr(1),
+		})
+
+		es := document.Validate()
+
+		g.Expect(es).To(ConsistOf(MatchError("indicators[0].thresholds[0] lower and upper can only be used with the between and outside operators")))
+	})
+
+	t.Run("validation returns errors if recovery is on the breached side of the value", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		document := thresholdDocument(v1.Threshold{
+			Level:    "warning",
+			Operator: v1.GreaterThan,
+			Value:    80,
+			Recovery: float64Ptr(90),
+		})
+
+		es := document.Validate()
+
+		g.Expect(es).To(ConsistOf(MatchError("indicators[0].thresholds[0] recovery must not be greater than value for gt and gte operators")))
+	})
+
+	t.Run("validation returns errors if a matcher is not a valid label name", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		document := thresholdDocument(v1.Threshold{
+			Level:    "warning",
+			Operator: v1.GreaterThan,
+			Matchers: map[string]string{"az": "z1", "not-a-label": "x"},
+		})
+
+		es := document.Validate()
+
+		g.Expect(es).To(ConsistOf(MatchError(`indicators[0].thresholds[0] matcher "not-a-label" is not a valid label name`)))
+	})
+
+	t.Run("validation returns no errors for valid alert metadata", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		document := thresholdDocument(v1.Threshold{
+			Level:    "warning",
+			Operator: v1.GreaterThan,
+			Alert: v1.Alert{
+				AlertMetadata: v1.AlertMetadata{
+					Labels:      map[string]string{"team": "speech"},
+					Annotations: map[string]string{"summary": "{{ $value }} words per minute"},
+					RunbookURL:  "https://example.com/runbooks/speech",
+					Owner:       "speech-team",
+				},
+			},
+		})
+		document.Spec.Indicators[0].Alert = v1.AlertMetadata{
+			Labels: map[string]string{"severity": "page"},
+		}
+
+		g.Expect(document.Validate()).To(BeEmpty())
+	})
+
+	t.Run("validation returns errors for invalid or reserved alert labels and invalid runbook URLs", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		document := thresholdDocument(v1.Threshold{
+			Level:    "warning",
+			Operator: v1.GreaterThan,
+			Alert: v1.Alert{
+				AlertMetadata: v1.AlertMetadata{
+					Labels:     map[string]string{"not-a-label": "x", "level": "critical"},
+					RunbookURL: "runbooks/speech",
+				},
+			},
+		})
+		document.Spec.Indicators[0].Alert = v1.AlertMetadata{
+			RunbookURL: "ftp://example.com/runbook",
+		}
+
+		g.Expect(document.Validate()).To(ConsistOf(
+			MatchError(`indicators[0].thresholds[0] alert label "level" is reserved, it is set on every generated alert`),
+			MatchError(`indicators[0].thresholds[0] alert label "not-a-label" is not a valid label name`),
+			MatchError(`indicators[0].thresholds[0] alert runbookURL must be an absolute http or https URL`),
+			MatchError(`indicators[0].alert runbookURL must be an absolute http or https URL`),
+		))
+	})
+
+	t.Run("validation returns errors if recovery is used with an unsupported operator", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		document := thresholdDocument(v1.Threshold{
+			Level:    "warning",
+			Operator: v1.EqualTo,
+			Recovery: float64Ptr(90),
+		})
+
+		es := document.Validate()
+
+		g.Expect(es).To(ConsistOf(MatchError("indicators[0].thresholds[0] recovery can only be used with the lt, lte, gt and gte operators")))
+	})
+}
+
+func TestPromQLLint(t *testing.T) {
+	lintDocument := func(promql string, labels []string) v1.IndicatorDocument {
+		return v1.IndicatorDocument{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: api_versions.V1,
+				Kind:       "IndicatorDocument",
+			},
+			Spec: v1.IndicatorDocumentSpec{
+				Product: v1.Product{Name: "well-performing-component", Version: "0.0.1"},
+				Indicators: []v1.IndicatorSpec{{
+					Name:   "my_fair_indicator",
+					PromQL: promql,
+					Thresholds: []v1.Threshold{{
+						Level:    "warning",
+						Operator: v1.GreaterThan,
+						Value:    1,
+						Alert:    test_fixtures.DefaultAlert(),
+					}},
+					Presentation: v1.Presentation{
+						ChartType: v1.StepChart,
+						Labels:    labels,
+					},
+				}},
+			},
+		}
+	}
+
+	t.Run("validation returns lint warnings separately from errors", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		document := lintDocument(`sum(rate(memory_bytes[5m])) by (job)`, []string{"instance"})
+
+		es, warnings := document.ValidateWithWarnings()
+		g.Expect(es).To(BeEmpty())
+		g.Expect(warnings).To(ConsistOf(
+			MatchError("indicators[0].promql [rate-over-gauge] rate() should only be applied to counters, but memory_bytes does not look like a counter (counter names end in _total, _count, _sum, _bucket)"),
+			MatchError("indicators[0].promql [dropped-label] presentation label instance is dropped by the query"),
+		))
+		g.Expect(document.Validate()).To(BeEmpty())
+	})
+
+	t.Run("validation returns errors for lint errors", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		document := lintDocument(`histogram_quantile(0.9, sum(rate(latency_bucket[5m])) by (job))`, nil)
+
+		g.Expect(document.Validate()).To(ConsistOf(
+			MatchError("indicators[0].promql [histogram-quantile-le] histogram_quantile() needs the le label of its buckets, but it is dropped by an aggregation; add le to the by clause"),
 		))
 	})
 }
@@ -411,7 +636,292 @@ func TestChartType(t *testing.T) {
 		es := document.Validate()
 
 		g.Expect(es).To(ContainElement(
-			errors.New("IndicatorDocument.spec.indicators.presentation.chartType in body should be one of [step bar status quota]"),
+			MatchError("IndicatorDocument.spec.indicators.presentation.chartType in body should be one of [step bar status quota]"),
+		))
+	})
+}
+
+func TestCompositeIndicators(t *testing.T) {
+	compositeDocument := func(composite v1.IndicatorSpec) v1.IndicatorDocument {
+		return v1.IndicatorDocument{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: api_versions.V1,
+				Kind:       "IndicatorDocument",
+			},
+			Spec: v1.IndicatorDocumentSpec{
+				Product: v1.Product{Name: "well-performing-component", Version: "0.0.1"},
+				Indicators: []v1.IndicatorSpec{{
+					Name:   "latency",
+					PromQL: "latency",
+				}, {
+					Name:   "errors",
+					PromQL: "errors",
+				}, composite},
+			},
+		}
+	}
+
+	t.Run("validation returns no errors if the composite is valid", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		document := compositeDocument(v1.IndicatorSpec{
+			Name: "health",
+			Composite: &v1.Composite{
+				Rule:       v1.AnyRule,
+				Level:      "critical",
+				Indicators: []string{"latency", "errors"},
+			},
+		})
+
+		g.Expect(document.Validate()).To(BeEmpty())
+	})
+
+	t.Run("validation returns errors if the composite has promql or thresholds", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		document := compositeDocument(v1.IndicatorSpec{
+			Name:       "health",
+			PromQL:     "up",
+			Thresholds: []v1.Threshold{{Level: "critical", Operator: v1.LessThan, Value: 1}},
+			Composite: &v1.Composite{
+				Rule:       v1.WorstRule,
+				Indicators: []string{"latency"},
+			},
+		})
+
+		g.Expect(document.Validate()).To(ConsistOf(
+			MatchError("indicators[2] is composite and cannot have promql"),
+			MatchError("indicators[2] is composite and cannot have thresholds"),
+		))
+	})
+
+	t.Run("validation returns errors if the level of an any or all rule is missing", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		document := compositeDocument(v1.IndicatorSpec{
+			Name: "health",
+			Composite: &v1.Composite{
+				Rule:       v1.AllRule,
+				Indicators: []string{"latency"},
+			},
+		})
+
+		g.Expect(document.Validate()).To(ConsistOf(
+			MatchError("indicators[2].composite.level is required for the all rule"),
+		))
+	})
+
+	t.Run("validation returns errors if the rule is invalid", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		document := compositeDocument(v1.IndicatorSpec{
+			Name: "health",
+			Composite: &v1.Composite{
+				Rule:       "most",
+				Indicators: []string{"latency"},
+			},
+		})
+
+		g.Expect(document.Validate()).To(ContainElement(
+			MatchError("IndicatorDocument.spec.indicators.composite.rule in body should be one of [any all worst best]"),
+		))
+	})
+
+	t.Run("validation returns errors if the composite references missing or composite indicators", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		document := compositeDocument(v1.IndicatorSpec{
+			Name: "health",
+			Composite: &v1.Composite{
+				Rule:       v1.WorstRule,
+				Indicators: []string{"latency", "missing", "health"},
+			},
+		})
+
+		g.Expect(document.Validate()).To(ConsistOf(
+			MatchError("indicators[2].composite.indicators[1] references a non-existent indicator missing"),
+			MatchError("indicators[2].composite.indicators[2] references health, which is composite itself"),
+		))
+	})
+
+	t.Run("validation still requires promql for other indicators", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		document := compositeDocument(v1.IndicatorSpec{Name: "health"})
+
+		g.Expect(document.Validate()).To(ContainElement(
+			MatchError("IndicatorDocument.spec.indicators.promql in body should be at least 1 chars long"),
+		))
+	})
+}
+
+func TestDeprecatedIndicators(t *testing.T) {
+	deprecatedDocument := func(deprecated v1.IndicatorSpec) v1.IndicatorDocument {
+		return v1.IndicatorDocument{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: api_versions.V1,
+				Kind:       "IndicatorDocument",
+			},
+			Spec: v1.IndicatorDocumentSpec{
+				Product: v1.Product{Name: "well-performing-component", Version: "0.0.1"},
+				Indicators: []v1.IndicatorSpec{{
+					Name:   "request_latency",
+					PromQL: "request_latency",
+				}, deprecated},
+			},
+		}
+	}
+
+	t.Run("validation returns a warning for deprecated indicators", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		document := deprecatedDocument(v1.IndicatorSpec{
+			Name:               "latency",
+			PromQL:             "latency",
+			Deprecated:         true,
+			DeprecationMessage: "Latency is measured per request now.",
+			ReplacedBy:         "request_latency",
+		})
+
+		es, warnings := document.ValidateWithWarnings()
+		g.Expect(es).To(BeEmpty())
+		g.Expect(warnings).To(ConsistOf(
+			MatchError("indicators[1] latency is deprecated: Use request_latency instead. Latency is measured per request now."),
+		))
+		g.Expect(warnings[0].(*v1.ValidationError).Path).To(Equal("spec.indicators[1].deprecated"))
+	})
+
+	t.Run("validation returns errors if replacedBy does not reference another indicator", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		document := deprecatedDocument(v1.IndicatorSpec{
+			Name:       "latency",
+			PromQL:     "latency",
+			Deprecated: true,
+			ReplacedBy: "response_latency",
+		})
+		g.Expect(document.Validate()).To(ConsistOf(
+			MatchError("indicators[1].replacedBy references a non-existent indicator response_latency"),
+		))
+
+		document = deprecatedDocument(v1.IndicatorSpec{
+			Name:       "latency",
+			PromQL:     "latency",
+			Deprecated: true,
+			ReplacedBy: "latency",
+		})
+		g.Expect(document.Validate()).To(ConsistOf(
+			MatchError("indicators[1].replacedBy cannot reference the indicator itself"),
+		))
+	})
+
+	t.Run("validation returns errors if indicators that are not deprecated have deprecation fields", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		document := deprecatedDocument(v1.IndicatorSpec{
+			Name:               "latency",
+			PromQL:             "latency",
+			DeprecationMessage: "Latency is measured per request now.",
+			ReplacedBy:         "request_latency",
+		})
+
+		es, warnings := document.ValidateWithWarnings()
+		g.Expect(warnings).To(BeEmpty())
+		g.Expect(es).To(ConsistOf(
+			MatchError("indicators[1].replacedBy can only be set on deprecated indicators"),
+			MatchError("indicators[1].deprecationMessage can only be set on deprecated indicators"),
+		))
+	})
+}
+
+func TestSLOs(t *testing.T) {
+	sloDocument := func(slos ...v1.ServiceLevelObjective) v1.IndicatorDocument {
+		return v1.IndicatorDocument{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: api_versions.V1,
+				Kind:       "IndicatorDocument",
+			},
+			Spec: v1.IndicatorDocumentSpec{
+				Product: v1.Product{Name: "well-performing-component", Version: "0.0.1"},
+				SLOs:    slos,
+			},
+		}
+	}
+	validSLO := func() v1.ServiceLevelObjective {
+		return v1.ServiceLevelObjective{
+			Name:        "availability",
+			GoodEvents:  `sum(rate(http_requests_total{code!~"5.."}[$window]))`,
+			TotalEvents: `sum(rate(http_requests_total[$window]))`,
+			Objective:   99.9,
+			Window:      "30d",
+		}
+	}
+
+	t.Run("validation returns no errors if the slos are valid", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		document := sloDocument(validSLO())
+
+		g.Expect(document.Validate()).To(BeEmpty())
+	})
+
+	t.Run("validation returns errors if the queries do not contain $window", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		slo := validSLO()
+		slo.GoodEvents = `sum(rate(http_requests_total{code!~"5.."}[5m]))`
+		document := sloDocument(slo)
+
+		g.Expect(document.Validate()).To(ConsistOf(
+			MatchError("slos[0].goodEvents must contain $window so that it can be evaluated over each burn rate window"),
+		))
+	})
+
+	t.Run("validation returns errors if the queries are invalid promql", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		slo := validSLO()
+		slo.TotalEvents = `sum(rate(http_requests_total[$window])`
+		document := sloDocument(slo)
+
+		g.Expect(document.Validate()).To(ConsistOf(
+			MatchError("slos[0].totalEvents should be valid promql (see https://prometheus.io/docs/)"),
+		))
+	})
+
+	t.Run("validation returns errors if the window is invalid", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		slo := validSLO()
+		slo.Window = "a month"
+		shortSLO := validSLO()
+		shortSLO.Name = "short"
+		shortSLO.Window = "30m"
+		document := sloDocument(slo, shortSLO)
+
+		g.Expect(document.Validate()).To(ConsistOf(
+			MatchError("slos[0].window should be a valid duration (e.g. 30d)"),
+			MatchError("slos[1].window must be at least 1h"),
+		))
+	})
+
+	t.Run("validation returns errors if the objective is out of range", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		slo := validSLO()
+		slo.Objective = 100
+		document := sloDocument(slo)
+
+		g.Expect(document.Validate()).ToNot(BeEmpty())
+	})
+
+	t.Run("validation returns errors if slo names are duplicated", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		document := sloDocument(validSLO(), validSLO())
+
+		g.Expect(document.Validate()).To(ConsistOf(
+			MatchError("slos[1].name must be unique, availability is already defined"),
 		))
 	})
 }
@@ -0,0 +1,50 @@
+package v1
+
+import (
+	"fmt"
+	"strings"
+)
+
+// +k8s:deepcopy-gen=false
+
+// ValidationError is a problem with the field at Path in a document, e.g. spec.indicators[1].promql.
+// File, Line and Column are only set once the error has been located in the document's YAML.
+type ValidationError struct {
+	Path    string
+	Message string
+	File    string
+	Line    int
+	Column  int
+}
+
+func NewValidationError(path string, format string, args ...interface{}) *ValidationError {
+	return &ValidationError{
+		Path:    path,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+// Position describes where the error is, e.g. indicators.yml:12:7 spec.indicators[1].promql
+func (e *ValidationError) Position() string {
+	location := e.File
+	if e.Line > 0 {
+		location = fmt.Sprintf("%s:%d:%d", location, e.Line, e.Column)
+		location = strings.TrimPrefix(location, ":")
+	}
+
+	return strings.TrimSpace(location + " " + e.Path)
+}
+
+func joinPath(parent string, field string) string {
+	if parent == "" {
+		return field
+	}
+	if field == "" || strings.HasPrefix(field, "[") {
+		return parent + field
+	}
+	return parent + "." + field
+}
@@ -11,6 +11,7 @@ import (
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Alert) DeepCopyInto(out *Alert) {
 	*out = *in
+	in.AlertMetadata.DeepCopyInto(&out.AlertMetadata)
 	return
 }
 
@@ -24,6 +25,115 @@ func (in *Alert) DeepCopy() *Alert {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AlertMetadata) DeepCopyInto(out *AlertMetadata) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AlertMetadata.
+func (in *AlertMetadata) DeepCopy() *AlertMetadata {
+	if in == nil {
+		return nil
+	}
+	out := new(AlertMetadata)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Composite) DeepCopyInto(out *Composite) {
+	*out = *in
+	if in.Indicators != nil {
+		in, out := &in.Indicators, &out.Indicators
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Composite.
+func (in *Composite) DeepCopy() *Composite {
+	if in == nil {
+		return nil
+	}
+	out := new(Composite)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DocumentDefaults) DeepCopyInto(out *DocumentDefaults) {
+	*out = *in
+	in.Alert.DeepCopyInto(&out.Alert)
+	in.Presentation.DeepCopyInto(&out.Presentation)
+	if in.Documentation != nil {
+		in, out := &in.Documentation, &out.Documentation
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DocumentDefaults.
+func (in *DocumentDefaults) DeepCopy() *DocumentDefaults {
+	if in == nil {
+		return nil
+	}
+	out := new(DocumentDefaults)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Import) DeepCopyInto(out *Import) {
+	*out = *in
+	if in.Thresholds != nil {
+		in, out := &in.Thresholds, &out.Thresholds
+		*out = make(map[string][]Threshold, len(*in))
+		for key, val := range *in {
+			var outVal []Threshold
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = make([]Threshold, len(*in))
+				for i := range *in {
+					(*in)[i].DeepCopyInto(&(*out)[i])
+				}
+			}
+			(*out)[key] = outVal
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Import.
+func (in *Import) DeepCopy() *Import {
+	if in == nil {
+		return nil
+	}
+	out := new(Import)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Indicator) DeepCopyInto(out *Indicator) {
 	*out = *in
@@ -123,6 +233,18 @@ func (in *IndicatorDocumentList) DeepCopyObject() runtime.Object {
 func (in *IndicatorDocumentSpec) DeepCopyInto(out *IndicatorDocumentSpec) {
 	*out = *in
 	out.Product = in.Product
+	if in.Defaults != nil {
+		in, out := &in.Defaults, &out.Defaults
+		*out = new(DocumentDefaults)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Imports != nil {
+		in, out := &in.Imports, &out.Imports
+		*out = make([]Import, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.Indicators != nil {
 		in, out := &in.Indicators, &out.Indicators
 		*out = make([]IndicatorSpec, len(*in))
@@ -130,6 +252,20 @@ func (in *IndicatorDocumentSpec) DeepCopyInto(out *IndicatorDocumentSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.IndicatorTemplates != nil {
+		in, out := &in.IndicatorTemplates, &out.IndicatorTemplates
+		*out = make([]IndicatorTemplate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SLOs != nil {
+		in, out := &in.SLOs, &out.SLOs
+		*out = make([]ServiceLevelObjective, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	in.Layout.DeepCopyInto(&out.Layout)
 	return
 }
@@ -202,11 +338,19 @@ func (in *IndicatorList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IndicatorSpec) DeepCopyInto(out *IndicatorSpec) {
 	*out = *in
+	if in.Composite != nil {
+		in, out := &in.Composite, &out.Composite
+		*out = new(Composite)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Thresholds != nil {
 		in, out := &in.Thresholds, &out.Thresholds
 		*out = make([]Threshold, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
+	in.Alert.DeepCopyInto(&out.Alert)
 	if in.Documentation != nil {
 		in, out := &in.Documentation, &out.Documentation
 		*out = make(map[string]string, len(*in))
@@ -232,6 +376,7 @@ func (in *IndicatorSpec) DeepCopy() *IndicatorSpec {
 func (in *IndicatorStatus) DeepCopyInto(out *IndicatorStatus) {
 	*out = *in
 	in.UpdatedAt.DeepCopyInto(&out.UpdatedAt)
+	in.Since.DeepCopyInto(&out.Since)
 	return
 }
 
@@ -245,6 +390,36 @@ func (in *IndicatorStatus) DeepCopy() *IndicatorStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IndicatorTemplate) DeepCopyInto(out *IndicatorTemplate) {
+	*out = *in
+	in.Indicator.DeepCopyInto(&out.Indicator)
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make([]map[string]string, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = make(map[string]string, len(*in))
+				for key, val := range *in {
+					(*out)[key] = val
+				}
+			}
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IndicatorTemplate.
+func (in *IndicatorTemplate) DeepCopy() *IndicatorTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(IndicatorTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Layout) DeepCopyInto(out *Layout) {
 	*out = *in
@@ -326,10 +501,55 @@ func (in *Section) DeepCopy() *Section {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceLevelObjective) DeepCopyInto(out *ServiceLevelObjective) {
+	*out = *in
+	if in.Documentation != nil {
+		in, out := &in.Documentation, &out.Documentation
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceLevelObjective.
+func (in *ServiceLevelObjective) DeepCopy() *ServiceLevelObjective {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceLevelObjective)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Threshold) DeepCopyInto(out *Threshold) {
 	*out = *in
-	out.Alert = in.Alert
+	if in.Lower != nil {
+		in, out := &in.Lower, &out.Lower
+		*out = new(float64)
+		**out = **in
+	}
+	if in.Upper != nil {
+		in, out := &in.Upper, &out.Upper
+		*out = new(float64)
+		**out = **in
+	}
+	if in.Recovery != nil {
+		in, out := &in.Recovery, &out.Recovery
+		*out = new(float64)
+		**out = **in
+	}
+	if in.Matchers != nil {
+		in, out := &in.Matchers, &out.Matchers
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.Alert.DeepCopyInto(&out.Alert)
 	return
 }
 
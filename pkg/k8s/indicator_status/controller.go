@@ -7,6 +7,7 @@ import (
 	"github.com/benbjohnson/clock"
 
 	"github.com/pivotal/monitoring-indicator-protocol/pkg/indicator_status"
+	"github.com/pivotal/monitoring-indicator-protocol/pkg/prometheus_client"
 
 	types "github.com/pivotal/monitoring-indicator-protocol/pkg/k8s/apis/indicatordocument/v1"
 	clientSetV1 "github.com/pivotal/monitoring-indicator-protocol/pkg/k8s/client/clientset/versioned/typed/indicatordocument/v1"
@@ -15,7 +16,7 @@ import (
 )
 
 type PromQLClient interface {
-	QueryVectorValues(query string) ([]float64, error)
+	QueryVectorSamples(query string) ([]prometheus_client.Sample, error)
 }
 
 type indicatorStore interface {
@@ -99,27 +100,56 @@ func (c *Controller) OnDelete(obj interface{}) {
 	c.indicatorStore.Delete(*indicator)
 }
 
+// Composite indicators are updated after all other indicators, from the statuses of the indicators
+// of the same document.
 func (c *Controller) updateStatuses() {
+	var composites []types.Indicator
+	statuses := make(map[string]map[string]string)
+
 	for _, indicator := range c.indicatorStore.GetIndicators() {
+		if indicator.Spec.IsComposite() {
+			composites = append(composites, indicator)
+			continue
+		}
+
 		status, err := c.getStatus(indicator)
 		if err != nil {
 			log.Print("Error getting status for indicator")
 			continue
 		}
 
-		if indicator.Status.Phase == status {
-			continue
+		if statuses[documentKey(indicator)] == nil {
+			statuses[documentKey(indicator)] = make(map[string]string)
 		}
+		statuses[documentKey(indicator)][indicator.Spec.Name] = status
 
-		indicator.Status = types.IndicatorStatus{
-			Phase: status,
-		}
-		_, err = c.indicatorClient.Indicators(indicator.Namespace).Update(&indicator)
+		c.updateStatus(indicator, status)
+	}
 
-		if err != nil {
-			log.Print("Error updating indicator")
-		}
+	for _, indicator := range composites {
+		c.updateStatus(indicator, indicator.Spec.Composite.Evaluate(statuses[documentKey(indicator)]))
+	}
+}
+
+func (c *Controller) updateStatus(indicator types.Indicator, status string) {
+	if indicator.Status.Phase == status {
+		return
+	}
+
+	indicator.Status = types.IndicatorStatus{
+		Phase: status,
+		Since: v1.Time{Time: c.clock.Now()},
 	}
+	_, err := c.indicatorClient.Indicators(indicator.Namespace).Update(&indicator)
+
+	if err != nil {
+		log.Print("Error updating indicator")
+	}
+}
+
+// Indicators of the same document are labeled with the same owner.
+func documentKey(indicator types.Indicator) string {
+	return indicator.Namespace + "/" + indicator.Labels["owner"]
 }
 
 func (c *Controller) getStatus(indicator types.Indicator) (string, error) {
@@ -127,12 +157,12 @@ func (c *Controller) getStatus(indicator types.Indicator) (string, error) {
 	if len(indicator.Spec.Thresholds) == 0 {
 		return status, nil
 	}
-	values, err := c.promqlClient.QueryVectorValues(indicator.Spec.PromQL)
+	samples, err := c.promqlClient.QueryVectorSamples(indicator.Spec.PromQL)
 	if err != nil {
 		log.Print("Error querying Prometheus")
 		return "", err
 	}
 
-	status = indicator_status.Match(indicator.Spec.Thresholds, values)
+	status = indicator_status.MatchSamples(indicator.Spec.Thresholds, samples, indicator.Status.Phase)
 	return status, nil
 }
@@ -14,6 +14,7 @@ import (
 	types "github.com/pivotal/monitoring-indicator-protocol/pkg/k8s/apis/indicatordocument/v1"
 	clientSetV1 "github.com/pivotal/monitoring-indicator-protocol/pkg/k8s/client/clientset/versioned/typed/indicatordocument/v1"
 	"github.com/pivotal/monitoring-indicator-protocol/pkg/k8s/indicator_status"
+	"github.com/pivotal/monitoring-indicator-protocol/pkg/prometheus_client"
 	"github.com/pivotal/monitoring-indicator-protocol/test_fixtures"
 )
 
@@ -108,6 +109,56 @@ func TestController(t *testing.T) {
 		})
 	})
 
+	t.Run("updates composite indicators from the indicators of the same document", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		owned := func(indicator types.Indicator, owner string) types.Indicator {
+			indicator.Labels = map[string]string{"owner": owner}
+			return indicator
+		}
+		latency := owned(test_fixtures.Indicator("doc-latency", "latency"), "doc-ns")
+		latency.Spec.Name = "latency"
+		otherLatency := owned(test_fixtures.Indicator("other-doc-latency", "latency"), "other-doc-ns")
+		otherLatency.Spec.Name = "latency"
+		otherHealth := owned(types.Indicator{
+			ObjectMeta: v1.ObjectMeta{Name: "other-doc-health"},
+			Spec: types.IndicatorSpec{
+				Name:      "health",
+				Composite: &types.Composite{Rule: types.WorstRule, Indicators: []string{"saturation"}},
+			},
+		}, "other-doc-ns")
+		health := owned(types.Indicator{
+			ObjectMeta: v1.ObjectMeta{Name: "doc-health"},
+			Spec: types.IndicatorSpec{
+				Name:      "health",
+				Composite: &types.Composite{Rule: types.AnyRule, Level: "critical", Indicators: []string{"latency"}},
+			},
+		}, "doc-ns")
+
+		store := indicator_status.NewIndicatorStore()
+		fakeIndicatorsGetter := &fakeIndicatorsGetter{
+			listedIndicators: []types.Indicator{health, latency, otherHealth, otherLatency},
+			store:            store,
+		}
+		fakePromqlClient := &fakePromqlClient{response: []float64{-1}}
+		mockClock := clock.NewMock()
+		c := indicator_status.NewController(fakeIndicatorsGetter, fakePromqlClient, time.Second, mockClock, "cool-namespace-name", store)
+
+		go c.Start()
+
+		g.Eventually(fakeIndicatorsGetter.getUpdateCalls).Should(HaveLen(4))
+		g.Expect(fakePromqlClient.getQueries()).To(HaveLen(2))
+		phases := make(map[string]string)
+		for _, indicator := range fakeIndicatorsGetter.getUpdateCalls() {
+			phases[indicator.Name] = indicator.Status.Phase
+		}
+		g.Expect(phases).To(Equal(map[string]string{
+			"doc-latency":       "critical",
+			"other-doc-latency": "critical",
+			"doc-health":        "critical",
+			"other-doc-health":  "UNKNOWN",
+		}))
+	})
+
 	t.Run("OnDelete", func(t *testing.T) {
 		t.Run("stops updating indicator status", func(t *testing.T) {
 			g := NewGomegaWithT(t)
@@ -289,12 +340,16 @@ type fakePromqlClient struct {
 	sync.Mutex
 }
 
-func (s *fakePromqlClient) QueryVectorValues(query string) ([]float64, error) {
+func (s *fakePromqlClient) QueryVectorSamples(query string) ([]prometheus_client.Sample, error) {
 	s.Lock()
 	defer s.Unlock()
 	s.queries = append(s.queries, query)
 
-	return s.response, nil
+	var samples []prometheus_client.Sample
+	for _, v := range s.response {
+		samples = append(samples, prometheus_client.Sample{Value: v})
+	}
+	return samples, nil
 }
 
 func (s *fakePromqlClient) getQueries() []string {
@@ -3,17 +3,20 @@ package prometheus_alerts
 import (
 	"crypto/sha1"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/pivotal/monitoring-indicator-protocol/pkg/k8s/apis/indicatordocument/v1"
 )
 
+// Rule is either an alerting rule or, when Record is set, a recording rule.
 type Rule struct {
-	Alert       string
-	Expr        string
-	For         string
-	Labels      map[string]string
-	Annotations map[string]string
+	Record      string            `yaml:"record,omitempty"`
+	Alert       string            `yaml:"alert,omitempty"`
+	Expr        string            `yaml:"expr"`
+	For         string            `yaml:"for,omitempty"`
+	Labels      map[string]string `yaml:"labels"`
+	Annotations map[string]string `yaml:"annotations,omitempty"`
 }
 
 type Document struct {
@@ -38,6 +41,10 @@ func AlertDocumentFrom(document v1.IndicatorDocument) Document {
 		}
 	}
 
+	for _, slo := range document.Spec.SLOs {
+		rules = append(rules, sloRulesFrom(document, slo)...)
+	}
+
 	return Document{
 		Groups: []Group{{
 			Name:  document.Spec.Product.Name,
@@ -47,23 +54,149 @@ func AlertDocumentFrom(document v1.IndicatorDocument) Document {
 }
 
 func ruleFrom(document v1.IndicatorDocument, i v1.IndicatorSpec, threshold v1.Threshold) Rule {
-	labels := map[string]string{
-		"product": document.Spec.Product.Name,
-		"version": document.Spec.Product.Version,
-		"level":   threshold.Level,
-	}
+	alert := i.AlertMetadataFor(threshold)
 
-	for k, v := range document.ObjectMeta.Labels {
+	labels := documentLabels(document)
+	for k, v := range alert.Labels {
 		labels[k] = v
 	}
+	if alert.Owner != "" {
+		labels["owner"] = alert.Owner
+	}
+	labels["level"] = threshold.Level
 
 	interpolatedPromQl := strings.Replace(i.PromQL, "$step", threshold.Alert.Step, -1)
 
+	expr := thresholdExpr(interpolatedPromQl, threshold)
+	if threshold.Recovery != nil {
+		expr = withRecovery(expr, interpolatedPromQl, i.Name, threshold, labels)
+	}
+	expr = withScope(expr, threshold, i.Thresholds)
+
 	return Rule{
 		Alert:       i.Name,
-		Expr:        fmt.Sprintf("%s %s %+v", interpolatedPromQl, v1.GetComparatorSymbol(threshold.Operator), threshold.Value),
+		Expr:        expr,
 		For:         threshold.Alert.For,
 		Labels:      labels,
-		Annotations: i.Documentation,
+		Annotations: annotationsFrom(i.Documentation, alert),
+	}
+}
+
+// The alert's annotations override the documentation of the indicator with the same key.
+func annotationsFrom(documentation map[string]string, alert v1.AlertMetadata) map[string]string {
+	if len(documentation) == 0 && len(alert.Annotations) == 0 && alert.RunbookURL == "" {
+		return documentation
+	}
+
+	annotations := make(map[string]string, len(documentation)+len(alert.Annotations)+1)
+	for k, v := range documentation {
+		annotations[k] = v
+	}
+	for k, v := range alert.Annotations {
+		annotations[k] = v
+	}
+	if alert.RunbookURL != "" {
+		annotations["runbook_url"] = alert.RunbookURL
+	}
+
+	return annotations
+}
+
+func thresholdExpr(promql string, threshold v1.Threshold) string {
+	switch threshold.Operator {
+	case v1.Between:
+		return fmt.Sprintf("(%s) >= %+v and (%s) <= %+v", promql, deref(threshold.Lower), promql, deref(threshold.Upper))
+	case v1.Outside:
+		return fmt.Sprintf("(%s) < %+v or (%s) > %+v", promql, deref(threshold.Lower), promql, deref(threshold.Upper))
+	default:
+		return fmt.Sprintf("%s %s %+v", promql, v1.GetComparatorSymbol(threshold.Operator), threshold.Value)
+	}
+}
+
+// Prometheus has no native hysteresis, so the alert keeps firing while it is already firing
+// (according to the ALERTS series) and the value has not crossed the recovery value yet.
+func withRecovery(expr string, promql string, name string, threshold v1.Threshold, labels map[string]string) string {
+	var recoveryComparator string
+	switch threshold.Operator {
+	case v1.LessThan, v1.LessThanOrEqualTo:
+		recoveryComparator = "<"
+	case v1.GreaterThan, v1.GreaterThanOrEqualTo:
+		recoveryComparator = ">"
+	default:
+		return expr
+	}
+
+	alertLabels := copyLabels(labels)
+	alertLabels["alertname"] = name
+	alertLabels["alertstate"] = "firing"
+
+	ignored := make([]string, 0, len(alertLabels))
+	for k := range alertLabels {
+		ignored = append(ignored, k)
+	}
+	sort.Strings(ignored)
+
+	return fmt.Sprintf("%s or (%s %s %+v and ignoring(%s) %s)",
+		expr,
+		promql, recoveryComparator, *threshold.Recovery,
+		strings.Join(ignored, ", "),
+		selector("ALERTS", alertLabels),
+	)
+}
+
+// A scoped threshold only alerts on the series its matchers select, and unscoped thresholds
+// leave those series to the scoped thresholds of the same level which override them.
+func withScope(expr string, threshold v1.Threshold, thresholds []v1.Threshold) string {
+	if threshold.IsScoped() {
+		return fmt.Sprintf("(%s) and %s", expr, scopeFilter(threshold.Matchers))
+	}
+
+	var overrides []string
+	for _, other := range thresholds {
+		if other.IsScoped() && other.Level == threshold.Level {
+			overrides = append(overrides, fmt.Sprintf(" unless %s", scopeFilter(other.Matchers)))
+		}
+	}
+	if len(overrides) == 0 {
+		return expr
+	}
+
+	return fmt.Sprintf("(%s)%s", expr, strings.Join(overrides, ""))
+}
+
+// scopeFilter builds a single series carrying the matcher labels, so that
+// `and`/`unless` can filter any expression by them.
+func scopeFilter(matchers map[string]string) string {
+	keys := make([]string, 0, len(matchers))
+	for k := range matchers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	filter := "vector(1)"
+	for _, k := range keys {
+		filter = fmt.Sprintf(`label_replace(%s, %q, %q, "", "")`, filter, k, strings.Replace(matchers[k], "$", "$$", -1))
+	}
+
+	return fmt.Sprintf("on(%s) %s", strings.Join(keys, ", "), filter)
+}
+
+func deref(f *float64) float64 {
+	if f == nil {
+		return 0
+	}
+	return *f
+}
+
+func documentLabels(document v1.IndicatorDocument) map[string]string {
+	labels := map[string]string{
+		"product": document.Spec.Product.Name,
+		"version": document.Spec.Product.Version,
 	}
+
+	for k, v := range document.ObjectMeta.Labels {
+		labels[k] = v
+	}
+
+	return labels
 }
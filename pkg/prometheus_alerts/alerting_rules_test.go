@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	. "github.com/onsi/gomega"
+	"github.com/prometheus/prometheus/promql"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/pivotal/monitoring-indicator-protocol/pkg/k8s/apis/indicatordocument/v1"
@@ -65,6 +66,96 @@ func TestAlertGeneration(t *testing.T) {
 		g.Expect(exprFor(v1.GreaterThanOrEqualTo)).To(Equal(`metric{source_id="fake-source"} >= 0.99999999999999`))
 	})
 
+	t.Run("it generates a promql statement for range operators", func(t *testing.T) {
+		g = NewGomegaWithT(t)
+
+		exprFor := func(op v1.ThresholdOperator) string {
+			lower, upper := 5.0, 10.0
+			doc := v1.IndicatorDocument{
+				Spec: v1.IndicatorDocumentSpec{
+					Indicators: []v1.IndicatorSpec{{
+						PromQL: `metric{source_id="fake-source"}`,
+						Thresholds: []v1.Threshold{{
+							Level:    "warning",
+							Operator: op,
+							Lower:    &lower,
+							Upper:    &upper,
+						}},
+					}},
+				},
+			}
+
+			return getFirstRule(doc).Expr
+		}
+
+		g.Expect(exprFor(v1.Between)).To(Equal(`(metric{source_id="fake-source"}) >= 5 and (metric{source_id="fake-source"}) <= 10`))
+		g.Expect(exprFor(v1.Outside)).To(Equal(`(metric{source_id="fake-source"}) < 5 or (metric{source_id="fake-source"}) > 10`))
+	})
+
+	t.Run("keeps firing until the recovery value is crossed", func(t *testing.T) {
+		g = NewGomegaWithT(t)
+
+		recovery := 80.0
+		doc := v1.IndicatorDocument{
+			Spec: v1.IndicatorDocumentSpec{
+				Product: v1.Product{Name: "product-lol", Version: "beta.9"},
+				Indicators: []v1.IndicatorSpec{{
+					Name:   "indicator_lol",
+					PromQL: `metric`,
+					Thresholds: []v1.Threshold{{
+						Level:    "critical",
+						Operator: v1.GreaterThan,
+						Value:    90,
+						Recovery: &recovery,
+					}},
+				}},
+			},
+		}
+
+		g.Expect(getFirstRule(doc).Expr).To(Equal(
+			`metric > 90 or (metric > 80 and ignoring(alertname, alertstate, level, product, version) ` +
+				`ALERTS{alertname="indicator_lol",alertstate="firing",level="critical",product="product-lol",version="beta.9"})`,
+		))
+	})
+
+	t.Run("filters the series of label-scoped thresholds", func(t *testing.T) {
+		g = NewGomegaWithT(t)
+
+		doc := v1.IndicatorDocument{
+			Spec: v1.IndicatorDocumentSpec{
+				Indicators: []v1.IndicatorSpec{{
+					Name:   "indicator_lol",
+					PromQL: `metric`,
+					Thresholds: []v1.Threshold{{
+						Level:    "critical",
+						Operator: v1.GreaterThan,
+						Value:    10,
+					}, {
+						Level:    "critical",
+						Operator: v1.GreaterThan,
+						Value:    5,
+						Matchers: map[string]string{"az": "z1", "job": "router"},
+					}},
+				}},
+			},
+		}
+
+		rules := prometheus_alerts.AlertDocumentFrom(doc).Groups[0].Rules
+		g.Expect(rules).To(HaveLen(2))
+
+		g.Expect(rules[0].Expr).To(Equal(
+			`(metric > 10) unless on(az, job) label_replace(label_replace(vector(1), "az", "z1", "", ""), "job", "router", "", "")`,
+		))
+		g.Expect(rules[1].Expr).To(Equal(
+			`(metric > 5) and on(az, job) label_replace(label_replace(vector(1), "az", "z1", "", ""), "job", "router", "", "")`,
+		))
+
+		for _, r := range rules {
+			_, err := promql.ParseExpr(r.Expr)
+			g.Expect(err).ToNot(HaveOccurred())
+		}
+	})
+
 	t.Run("sets the name to the indicator's name", func(t *testing.T) {
 		g = NewGomegaWithT(t)
 
@@ -123,6 +214,50 @@ func TestAlertGeneration(t *testing.T) {
 		}))
 	})
 
+	t.Run("adds the alert labels, annotations, owner and runbook", func(t *testing.T) {
+		g = NewGomegaWithT(t)
+
+		doc := v1.IndicatorDocument{
+			Spec: v1.IndicatorDocumentSpec{
+				Product: v1.Product{Name: "product-lol", Version: "beta.9"},
+				Indicators: []v1.IndicatorSpec{{
+					Name:          "indicator_lol",
+					Documentation: map[string]string{"title": "Indicator LOL", "summary": "documented summary"},
+					Alert: v1.AlertMetadata{
+						Labels:      map[string]string{"team": "lol", "severity": "page"},
+						Annotations: map[string]string{"summary": "Value is {{ $value }}"},
+						RunbookURL:  "https://example.com/runbooks/lol",
+						Owner:       "team-lol",
+					},
+					Thresholds: []v1.Threshold{{
+						Level: "warning",
+						Alert: v1.Alert{
+							AlertMetadata: v1.AlertMetadata{
+								Labels: map[string]string{"severity": "ticket"},
+								Owner:  "team-rofl",
+							},
+						},
+					}},
+				}},
+			},
+		}
+
+		rule := getFirstRule(doc)
+		g.Expect(rule.Labels).To(Equal(map[string]string{
+			"product":  "product-lol",
+			"version":  "beta.9",
+			"level":    "warning",
+			"team":     "lol",
+			"severity": "ticket",
+			"owner":    "team-rofl",
+		}))
+		g.Expect(rule.Annotations).To(Equal(map[string]string{
+			"title":       "Indicator LOL",
+			"summary":     "Value is {{ $value }}",
+			"runbook_url": "https://example.com/runbooks/lol",
+		}))
+	})
+
 	t.Run("sets the alert for", func(t *testing.T) {
 		g = NewGomegaWithT(t)
 
@@ -0,0 +1,140 @@
+package prometheus_alerts
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+
+	"github.com/pivotal/monitoring-indicator-protocol/pkg/k8s/apis/indicatordocument/v1"
+)
+
+const sloErrorRatioRecord = "slo:sli_error:ratio_rate"
+
+// burnRateAlert is one row of the multi-window, multi-burn-rate alerting table from the
+// Google SRE workbook (https://landing.google.com/sre/workbook/chapters/alerting-on-slos/).
+// The alert fires when both windows have burned through the given percentage of the error
+// budget at the rate that would exhaust it in the long window.
+type burnRateAlert struct {
+	level                 string
+	longWindow            string
+	shortWindow           string
+	budgetConsumedPercent float64
+}
+
+var burnRateAlerts = []burnRateAlert{
+	{level: "critical", longWindow: "1h", shortWindow: "5m", budgetConsumedPercent: 2},
+	{level: "critical", longWindow: "6h", shortWindow: "30m", budgetConsumedPercent: 5},
+	{level: "warning", longWindow: "1d", shortWindow: "2h", budgetConsumedPercent: 10},
+	{level: "warning", longWindow: "3d", shortWindow: "6h", budgetConsumedPercent: 10},
+}
+
+// sloRulesFrom generates recording rules for the error ratio of the given SLO over every
+// window used by its burn rate alerts, followed by the alerts themselves. Alerts whose long
+// window exceeds the SLO window are skipped.
+func sloRulesFrom(document v1.IndicatorDocument, slo v1.ServiceLevelObjective) []Rule {
+	sloWindow, err := model.ParseDuration(slo.Window)
+	if err != nil {
+		log.Printf("skipping slo %s, invalid window: %s", slo.Name, slo.Window)
+		return nil
+	}
+
+	labels := documentLabels(document)
+	labels["slo"] = slo.Name
+
+	var alerts []burnRateAlert
+	for _, a := range burnRateAlerts {
+		if duration(a.longWindow) <= time.Duration(sloWindow) {
+			alerts = append(alerts, a)
+		}
+	}
+
+	rules := make([]Rule, 0)
+	for _, window := range recordedWindows(alerts) {
+		rules = append(rules, Rule{
+			Record: sloErrorRatioRecord + window,
+			Expr: fmt.Sprintf("1 - ((%s) / (%s))",
+				strings.Replace(slo.GoodEvents, "$window", window, -1),
+				strings.Replace(slo.TotalEvents, "$window", window, -1),
+			),
+			Labels: copyLabels(labels),
+		})
+	}
+
+	errorBudget := 1 - slo.Objective/100
+	for _, a := range alerts {
+		burnRate := a.budgetConsumedPercent * float64(sloWindow) / float64(duration(a.longWindow)) / 100
+		threshold := fmt.Sprintf("(%s * %s)", formatFloat(burnRate), formatFloat(errorBudget))
+
+		alertLabels := copyLabels(labels)
+		alertLabels["level"] = a.level
+
+		rules = append(rules, Rule{
+			Alert: slo.Name + "_error_budget_burn",
+			Expr: fmt.Sprintf("%s > %s and %s > %s",
+				selector(sloErrorRatioRecord+a.longWindow, labels), threshold,
+				selector(sloErrorRatioRecord+a.shortWindow, labels), threshold,
+			),
+			Labels:      alertLabels,
+			Annotations: slo.Documentation,
+		})
+	}
+
+	return rules
+}
+
+func recordedWindows(alerts []burnRateAlert) []string {
+	seen := make(map[string]bool)
+	var windows []string
+	for _, a := range alerts {
+		for _, w := range []string{a.shortWindow, a.longWindow} {
+			if !seen[w] {
+				seen[w] = true
+				windows = append(windows, w)
+			}
+		}
+	}
+
+	sort.Slice(windows, func(i, j int) bool {
+		return duration(windows[i]) < duration(windows[j])
+	})
+	return windows
+}
+
+func selector(metric string, labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	matchers := make([]string, 0, len(keys))
+	for _, k := range keys {
+		matchers = append(matchers, fmt.Sprintf("%s=%q", k, labels[k]))
+	}
+
+	return fmt.Sprintf("%s{%s}", metric, strings.Join(matchers, ","))
+}
+
+func copyLabels(labels map[string]string) map[string]string {
+	c := make(map[string]string, len(labels))
+	for k, v := range labels {
+		c[k] = v
+	}
+	return c
+}
+
+// The windows above are all valid durations, so errors are not possible here.
+func duration(d string) time.Duration {
+	parsed, _ := model.ParseDuration(d)
+	return time.Duration(parsed)
+}
+
+// Rounds away floating point noise, e.g. 1 - 0.999 = 0.0010000000000000009
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', 12, 64)
+}
@@ -0,0 +1,114 @@
+package prometheus_alerts_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/pivotal/monitoring-indicator-protocol/pkg/k8s/apis/indicatordocument/v1"
+	"github.com/pivotal/monitoring-indicator-protocol/pkg/prometheus_alerts"
+)
+
+func TestSLOAlertGeneration(t *testing.T) {
+	sloDocument := func(window string) v1.IndicatorDocument {
+		return v1.IndicatorDocument{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{"deployment": "cf"},
+			},
+			Spec: v1.IndicatorDocumentSpec{
+				Product: v1.Product{Name: "uaa", Version: "1.0"},
+				SLOs: []v1.ServiceLevelObjective{{
+					Name:          "availability",
+					GoodEvents:    `sum(rate(requests_total{code!~"5.."}[$window]))`,
+					TotalEvents:   `sum(rate(requests_total[$window]))`,
+					Objective:     99.9,
+					Window:        window,
+					Documentation: map[string]string{"summary": "UAA availability"},
+				}},
+			},
+		}
+	}
+
+	t.Run("it records the error ratio for each window", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		rules := prometheus_alerts.AlertDocumentFrom(sloDocument("30d")).Groups[0].Rules
+
+		var records []string
+		for _, r := range rules {
+			if r.Record != "" {
+				records = append(records, r.Record)
+			}
+		}
+		g.Expect(records).To(Equal([]string{
+			"slo:sli_error:ratio_rate5m",
+			"slo:sli_error:ratio_rate30m",
+			"slo:sli_error:ratio_rate1h",
+			"slo:sli_error:ratio_rate2h",
+			"slo:sli_error:ratio_rate6h",
+			"slo:sli_error:ratio_rate1d",
+			"slo:sli_error:ratio_rate3d",
+		}))
+
+		g.Expect(rules[0]).To(Equal(prometheus_alerts.Rule{
+			Record: "slo:sli_error:ratio_rate5m",
+			Expr:   `1 - ((sum(rate(requests_total{code!~"5.."}[5m]))) / (sum(rate(requests_total[5m]))))`,
+			Labels: map[string]string{
+				"product":    "uaa",
+				"version":    "1.0",
+				"deployment": "cf",
+				"slo":        "availability",
+			},
+		}))
+	})
+
+	t.Run("it generates multi-window, multi-burn-rate alerts", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		rules := prometheus_alerts.AlertDocumentFrom(sloDocument("30d")).Groups[0].Rules
+
+		var alerts []prometheus_alerts.Rule
+		for _, r := range rules {
+			if r.Alert != "" {
+				alerts = append(alerts, r)
+			}
+		}
+		g.Expect(alerts).To(HaveLen(4))
+
+		selector := `{deployment="cf",product="uaa",slo="availability",version="1.0"}`
+		g.Expect(alerts[0]).To(Equal(prometheus_alerts.Rule{
+			Alert: "availability_error_budget_burn",
+			Expr: "slo:sli_error:ratio_rate1h" + selector + " > (14.4 * 0.001) and " +
+				"slo:sli_error:ratio_rate5m" + selector + " > (14.4 * 0.001)",
+			Labels: map[string]string{
+				"product":    "uaa",
+				"version":    "1.0",
+				"deployment": "cf",
+				"slo":        "availability",
+				"level":      "critical",
+			},
+			Annotations: map[string]string{"summary": "UAA availability"},
+		}))
+		g.Expect(alerts[1].Expr).To(ContainSubstring("ratio_rate6h" + selector + " > (6 * 0.001)"))
+		g.Expect(alerts[2].Expr).To(ContainSubstring("ratio_rate1d" + selector + " > (3 * 0.001)"))
+		g.Expect(alerts[2].Labels["level"]).To(Equal("warning"))
+		g.Expect(alerts[3].Expr).To(ContainSubstring("ratio_rate3d" + selector + " > (1 * 0.001)"))
+	})
+
+	t.Run("it scales burn rates to the slo window and skips longer alert windows", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		rules := prometheus_alerts.AlertDocumentFrom(sloDocument("1d")).Groups[0].Rules
+
+		var alerts []prometheus_alerts.Rule
+		for _, r := range rules {
+			if r.Alert != "" {
+				alerts = append(alerts, r)
+			}
+		}
+		g.Expect(alerts).To(HaveLen(3))
+		g.Expect(alerts[0].Expr).To(ContainSubstring("> (0.48 * 0.001)"))
+		g.Expect(alerts[2].Expr).To(ContainSubstring("> (0.1 * 0.001)"))
+	})
+}
@@ -16,7 +16,26 @@ type PrometheusClient struct {
 	Api PrometheusQueryAPI
 }
 
+// Sample is a single series of an instant vector.
+type Sample struct {
+	Labels map[string]string
+	Value  float64
+}
+
 func (p *PrometheusClient) QueryVectorValues(promql string) ([]float64, error) {
+	samples, err := p.QueryVectorSamples(promql)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]float64, 0, len(samples))
+	for _, s := range samples {
+		values = append(values, s.Value)
+	}
+	return values, nil
+}
+
+func (p *PrometheusClient) QueryVectorSamples(promql string) ([]Sample, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
 	defer cancel()
 
@@ -30,11 +49,18 @@ func (p *PrometheusClient) QueryVectorValues(promql string) ([]float64, error) {
 		return nil, fmt.Errorf("could not assert result from prometheus as Vector: %T", value)
 	}
 
-	values := make([]float64, 0, len(vector))
+	samples := make([]Sample, 0, len(vector))
 	for _, v := range vector {
-		values = append(values, float64(v.Value))
+		labels := make(map[string]string, len(v.Metric))
+		for k, l := range v.Metric {
+			labels[string(k)] = string(l)
+		}
+		samples = append(samples, Sample{
+			Labels: labels,
+			Value:  float64(v.Value),
+		})
 	}
-	return values, nil
+	return samples, nil
 }
 
 func (p *PrometheusClient) Query(ctx context.Context, query string, ts time.Time) (model.Value, error) {
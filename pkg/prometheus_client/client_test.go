@@ -48,6 +48,25 @@ func TestPrometheusClient(t *testing.T) {
 		g.Expect(values).To(Equal(expectedResponse))
 	})
 
+	t.Run("returns the labels of each sample", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		fakeQueryClient := spyPromqlClient{
+			response: vectorResponse([]float64{9, 10}),
+		}
+		client := prometheus_client.PrometheusClient{
+			Api: &fakeQueryClient,
+		}
+
+		samples, err := client.QueryVectorSamples("rate(errors[5m])")
+
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(samples).To(Equal([]prometheus_client.Sample{
+			{Labels: map[string]string{"deployment": "uaa123"}, Value: 9},
+			{Labels: map[string]string{"deployment": "uaa123"}, Value: 10},
+		}))
+	})
+
 	t.Run("when Prometheus returns an error, returns the error", func(t *testing.T) {
 		g := NewGomegaWithT(t)
 
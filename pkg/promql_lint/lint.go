@@ -0,0 +1,396 @@
+package promql_lint
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/prometheus/prometheus/promql"
+)
+
+type Severity string
+
+const (
+	Warning Severity = "warning"
+	Error   Severity = "error"
+)
+
+const (
+	RateOverGauge              = "rate-over-gauge"
+	HardcodedRange             = "hardcoded-range"
+	HistogramQuantileWithoutLe = "histogram-quantile-le"
+	DroppedLabel               = "dropped-label"
+	RangeVectorThreshold       = "range-vector-threshold"
+)
+
+type Problem struct {
+	Rule     string
+	Severity Severity
+	Message  string
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("[%s] %s", p.Rule, p.Message)
+}
+
+// Query describes an indicator's PromQL and how its results are used.
+type Query struct {
+	PromQL string
+	// Labels are expected on every result, e.g. to tell series apart in a chart.
+	Labels []string
+	// HasThresholds is set when the results are compared against thresholds.
+	HasThresholds bool
+	// Step is the longest alert step of the thresholds, if any.
+	Step time.Duration
+}
+
+var stepRegex = regexp.MustCompile(`(?i)\$step\b`)
+
+// `$step` is not a valid PromQL duration, so it is replaced by a duration that is unlikely to be written by
+// hand before parsing, which lets the rules tell interpolated ranges apart from hardcoded ones.
+const stepPlaceholder = "7777777s"
+const stepPlaceholderDuration = 7777777 * time.Second
+
+// Lint returns the problems found in the given query. Queries that don't parse have no problems, parse
+// errors are reported by validation.
+func Lint(q Query) []Problem {
+	expr, err := promql.ParseExpr(stepRegex.ReplaceAllString(q.PromQL, stepPlaceholder))
+	if err != nil {
+		return nil
+	}
+
+	var problems []Problem
+	for _, rule := range rules {
+		problems = append(problems, rule(expr, q)...)
+	}
+	return problems
+}
+
+type rule func(expr promql.Expr, q Query) []Problem
+
+var rules = []rule{
+	rateOverGauge,
+	hardcodedRange,
+	histogramQuantileWithoutLe,
+	droppedLabels,
+	rangeVectorThreshold,
+}
+
+var counterSuffixes = []string{"_total", "_count", "_sum", "_bucket"}
+
+func rateOverGauge(expr promql.Expr, _ Query) []Problem {
+	var problems []Problem
+	inspect(expr, func(node promql.Node) {
+		call, ok := node.(*promql.Call)
+		if !ok {
+			return
+		}
+		switch call.Func.Name {
+		case "rate", "irate", "increase":
+		default:
+			return
+		}
+
+		selector, ok := unwrap(call.Args[0]).(*promql.MatrixSelector)
+		if !ok {
+			return
+		}
+		name := metricName(selector.Name, selector.LabelMatchers)
+		if name == "" || hasSuffix(name, counterSuffixes) {
+			return
+		}
+
+		problems = append(problems, Problem{
+			Rule:     RateOverGauge,
+			Severity: Warning,
+			Message: fmt.Sprintf("%s() should only be applied to counters, but %s does not look like a counter (counter names end in %s)",
+				call.Func.Name, name, strings.Join(counterSuffixes, ", ")),
+		})
+	})
+	return problems
+}
+
+func hardcodedRange(expr promql.Expr, q Query) []Problem {
+	var hardcoded []time.Duration
+	usesStep := false
+	inspect(expr, func(node promql.Node) {
+		selector, ok := node.(*promql.MatrixSelector)
+		if !ok {
+			return
+		}
+		if selector.Range == stepPlaceholderDuration {
+			usesStep = true
+		} else {
+			hardcoded = append(hardcoded, selector.Range)
+		}
+	})
+
+	var problems []Problem
+	for _, r := range hardcoded {
+		duration := model.Duration(r).String()
+		if usesStep {
+			problems = append(problems, Problem{
+				Rule:     HardcodedRange,
+				Severity: Warning,
+				Message:  fmt.Sprintf("range [%s] is hardcoded while other ranges use [$step]", duration),
+			})
+		} else if q.Step > 0 && r < q.Step {
+			problems = append(problems, Problem{
+				Rule:     HardcodedRange,
+				Severity: Warning,
+				Message: fmt.Sprintf("range [%s] is shorter than the alert step %s, so some samples are never evaluated; use [$step] instead",
+					duration, model.Duration(q.Step)),
+			})
+		}
+	}
+	return problems
+}
+
+func histogramQuantileWithoutLe(expr promql.Expr, _ Query) []Problem {
+	var problems []Problem
+	inspect(expr, func(node promql.Node) {
+		call, ok := node.(*promql.Call)
+		if !ok || call.Func.Name != "histogram_quantile" || len(call.Args) < 2 {
+			return
+		}
+
+		if !outputLabels(call.Args[1]).has("le") {
+			problems = append(problems, Problem{
+				Rule:     HistogramQuantileWithoutLe,
+				Severity: Error,
+				Message:  "histogram_quantile() needs the le label of its buckets, but it is dropped by an aggregation; add le to the by clause",
+			})
+		}
+	})
+	return problems
+}
+
+func droppedLabels(expr promql.Expr, q Query) []Problem {
+	var problems []Problem
+	output := outputLabels(expr)
+	for _, label := range q.Labels {
+		if !output.has(label) {
+			problems = append(problems, Problem{
+				Rule:     DroppedLabel,
+				Severity: Warning,
+				Message:  fmt.Sprintf("presentation label %s is dropped by the query", label),
+			})
+		}
+	}
+	return problems
+}
+
+func rangeVectorThreshold(expr promql.Expr, q Query) []Problem {
+	if !q.HasThresholds || expr.Type() != promql.ValueTypeMatrix {
+		return nil
+	}
+
+	return []Problem{{
+		Rule:     RangeVectorThreshold,
+		Severity: Error,
+		Message:  "the query returns a range vector, which thresholds cannot be compared against; wrap it in a function such as avg_over_time()",
+	}}
+}
+
+// labelSet describes the labels an expression's results can have. When only is nil, results may have any
+// label except the dropped ones.
+type labelSet struct {
+	only    map[string]bool
+	dropped map[string]bool
+}
+
+func anyLabels() labelSet {
+	return labelSet{dropped: map[string]bool{}}
+}
+
+func onlyLabels(names ...string) labelSet {
+	s := labelSet{only: map[string]bool{}}
+	for _, n := range names {
+		s.only[n] = true
+	}
+	return s
+}
+
+func (s labelSet) has(name string) bool {
+	if s.only != nil {
+		return s.only[name]
+	}
+	return !s.dropped[name]
+}
+
+func (s labelSet) with(names ...string) labelSet {
+	if s.only == nil {
+		result := anyLabels()
+		for n := range s.dropped {
+			result.dropped[n] = true
+		}
+		for _, n := range names {
+			delete(result.dropped, n)
+		}
+		return result
+	}
+
+	return onlyLabels(append(s.names(), names...)...)
+}
+
+func (s labelSet) without(names ...string) labelSet {
+	if s.only == nil {
+		result := anyLabels()
+		for n := range s.dropped {
+			result.dropped[n] = true
+		}
+		for _, n := range names {
+			result.dropped[n] = true
+		}
+		return result
+	}
+
+	result := onlyLabels(s.names()...)
+	for _, n := range names {
+		delete(result.only, n)
+	}
+	return result
+}
+
+func (s labelSet) names() []string {
+	var names []string
+	for n := range s.only {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func outputLabels(expr promql.Expr) labelSet {
+	switch e := expr.(type) {
+	case *promql.ParenExpr:
+		return outputLabels(e.Expr)
+	case *promql.UnaryExpr:
+		return outputLabels(e.Expr)
+	case *promql.AggregateExpr:
+		switch e.Op.String() {
+		case "topk", "bottomk":
+			return outputLabels(e.Expr)
+		}
+
+		var result labelSet
+		if e.Without {
+			result = outputLabels(e.Expr).without(e.Grouping...)
+		} else {
+			result = onlyLabels(e.Grouping...)
+		}
+		if e.Op.String() == "count_values" {
+			if label, ok := unwrap(e.Param).(*promql.StringLiteral); ok {
+				result = result.with(label.Val)
+			}
+		}
+		return result
+	case *promql.Call:
+		switch e.Func.Name {
+		case "label_replace", "label_join":
+			if label, ok := unwrap(e.Args[1]).(*promql.StringLiteral); ok {
+				return outputLabels(e.Args[0]).with(label.Val)
+			}
+			return anyLabels()
+		case "histogram_quantile":
+			return outputLabels(e.Args[1]).without("le")
+		}
+
+		for _, arg := range e.Args {
+			switch arg.Type() {
+			case promql.ValueTypeVector, promql.ValueTypeMatrix:
+				return outputLabels(arg)
+			}
+		}
+		return onlyLabels()
+	case *promql.BinaryExpr:
+		return binaryOutputLabels(e)
+	case *promql.NumberLiteral, *promql.StringLiteral:
+		return onlyLabels()
+	}
+
+	return anyLabels()
+}
+
+func binaryOutputLabels(e *promql.BinaryExpr) labelSet {
+	if e.LHS.Type() == promql.ValueTypeScalar {
+		return outputLabels(e.RHS)
+	}
+	if e.RHS.Type() == promql.ValueTypeScalar {
+		return outputLabels(e.LHS)
+	}
+
+	lhs := outputLabels(e.LHS)
+	switch e.Op.String() {
+	case "and", "unless":
+		return lhs
+	case "or":
+		rhs := outputLabels(e.RHS)
+		if lhs.only == nil || rhs.only == nil {
+			return anyLabels()
+		}
+		return lhs.with(rhs.names()...)
+	}
+
+	matching := e.VectorMatching
+	if matching == nil {
+		return lhs
+	}
+
+	switch matching.Card {
+	case promql.CardOneToOne:
+		if matching.On {
+			result := onlyLabels()
+			for _, l := range matching.MatchingLabels {
+				if lhs.has(l) {
+					result = result.with(l)
+				}
+			}
+			return result
+		}
+		return lhs.without(matching.MatchingLabels...)
+	case promql.CardOneToMany:
+		return outputLabels(e.RHS).with(matching.Include...)
+	default:
+		return lhs.with(matching.Include...)
+	}
+}
+
+func inspect(expr promql.Expr, f func(node promql.Node)) {
+	promql.Inspect(expr, func(node promql.Node, _ []promql.Node) error {
+		f(node)
+		return nil
+	})
+}
+
+func unwrap(expr promql.Expr) promql.Expr {
+	if paren, ok := expr.(*promql.ParenExpr); ok {
+		return unwrap(paren.Expr)
+	}
+	return expr
+}
+
+func metricName(name string, matchers []*labels.Matcher) string {
+	if name != "" {
+		return name
+	}
+	for _, m := range matchers {
+		if m.Name == labels.MetricName && m.Type == labels.MatchEqual {
+			return m.Value
+		}
+	}
+	return ""
+}
+
+func hasSuffix(s string, suffixes []string) bool {
+	for _, suffix := range suffixes {
+		if strings.HasSuffix(s, suffix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,132 @@
+package promql_lint_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal/monitoring-indicator-protocol/pkg/promql_lint"
+)
+
+func rules(problems []promql_lint.Problem) []string {
+	var ids []string
+	for _, p := range problems {
+		ids = append(ids, p.Rule)
+	}
+	return ids
+}
+
+func TestLint(t *testing.T) {
+	t.Run("has no problems for queries that don't parse", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		g.Expect(promql_lint.Lint(promql_lint.Query{PromQL: "rate("})).To(BeEmpty())
+	})
+
+	t.Run("warns about rate over gauges", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		problems := promql_lint.Lint(promql_lint.Query{PromQL: `sum(rate(memory_bytes{job="api"}[5m]))`})
+		g.Expect(problems).To(ConsistOf(promql_lint.Problem{
+			Rule:     promql_lint.RateOverGauge,
+			Severity: promql_lint.Warning,
+			Message:  "rate() should only be applied to counters, but memory_bytes does not look like a counter (counter names end in _total, _count, _sum, _bucket)",
+		}))
+
+		g.Expect(rules(promql_lint.Lint(promql_lint.Query{PromQL: `irate({__name__="memory_bytes"}[5m])`}))).To(ConsistOf(promql_lint.RateOverGauge))
+		g.Expect(promql_lint.Lint(promql_lint.Query{PromQL: `increase(http_requests_total[5m])`})).To(BeEmpty())
+		g.Expect(promql_lint.Lint(promql_lint.Query{PromQL: `rate(latency_seconds_count[5m])`})).To(BeEmpty())
+	})
+
+	t.Run("warns about hardcoded ranges", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		problems := promql_lint.Lint(promql_lint.Query{PromQL: `rate(errors_total[$step]) / rate(requests_total[5m])`})
+		g.Expect(problems).To(ConsistOf(promql_lint.Problem{
+			Rule:     promql_lint.HardcodedRange,
+			Severity: promql_lint.Warning,
+			Message:  "range [5m] is hardcoded while other ranges use [$step]",
+		}))
+
+		problems = promql_lint.Lint(promql_lint.Query{
+			PromQL:        `avg_over_time(latency[1m])`,
+			HasThresholds: true,
+			Step:          5 * time.Minute,
+		})
+		g.Expect(problems).To(ConsistOf(promql_lint.Problem{
+			Rule:     promql_lint.HardcodedRange,
+			Severity: promql_lint.Warning,
+			Message:  "range [1m] is shorter than the alert step 5m, so some samples are never evaluated; use [$step] instead",
+		}))
+
+		g.Expect(promql_lint.Lint(promql_lint.Query{PromQL: `rate(errors_total[$step]) / rate(requests_total[$STEP])`})).To(BeEmpty())
+		g.Expect(promql_lint.Lint(promql_lint.Query{PromQL: `avg_over_time(latency[5m])`, Step: time.Minute})).To(BeEmpty())
+	})
+
+	t.Run("returns an error for histogram_quantile without le", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		problems := promql_lint.Lint(promql_lint.Query{PromQL: `histogram_quantile(0.99, sum(rate(latency_bucket[5m])) by (job))`})
+		g.Expect(problems).To(ConsistOf(promql_lint.Problem{
+			Rule:     promql_lint.HistogramQuantileWithoutLe,
+			Severity: promql_lint.Error,
+			Message:  "histogram_quantile() needs the le label of its buckets, but it is dropped by an aggregation; add le to the by clause",
+		}))
+
+		g.Expect(rules(promql_lint.Lint(promql_lint.Query{PromQL: `histogram_quantile(0.99, sum without (le) (rate(latency_bucket[5m])))`}))).
+			To(ConsistOf(promql_lint.HistogramQuantileWithoutLe))
+		g.Expect(promql_lint.Lint(promql_lint.Query{PromQL: `histogram_quantile(0.99, sum(rate(latency_bucket[5m])) by (job, le))`})).To(BeEmpty())
+		g.Expect(promql_lint.Lint(promql_lint.Query{PromQL: `histogram_quantile(0.99, rate(latency_bucket[5m]))`})).To(BeEmpty())
+	})
+
+	t.Run("warns about presentation labels dropped by the query", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		problems := promql_lint.Lint(promql_lint.Query{
+			PromQL: `sum(rate(requests_total[5m])) by (job)`,
+			Labels: []string{"job", "instance"},
+		})
+		g.Expect(problems).To(ConsistOf(promql_lint.Problem{
+			Rule:     promql_lint.DroppedLabel,
+			Severity: promql_lint.Warning,
+			Message:  "presentation label instance is dropped by the query",
+		}))
+
+		droppedQueries := []string{
+			`sum without (instance) (requests_total)`,
+			`histogram_quantile(0.9, rate(latency_bucket[5m])) / on(job) sum(rate(requests_total[5m])) by (job, instance)`,
+			`count(up) > 1`,
+			`vector(1)`,
+		}
+		for _, q := range droppedQueries {
+			g.Expect(rules(promql_lint.Lint(promql_lint.Query{PromQL: q, Labels: []string{"instance"}}))).
+				To(ConsistOf(promql_lint.DroppedLabel), q)
+		}
+
+		keptQueries := []string{
+			`requests_total`,
+			`topk(5, requests_total)`,
+			`sum without (job) (requests_total)`,
+			`label_replace(sum(requests_total), "instance", "$1", "host", "(.*)")`,
+			`sum(requests_total) by (instance) / on(instance) group_left(job) sum(up) by (instance)`,
+			`sum(requests_total) by (instance) > 5`,
+		}
+		for _, q := range keptQueries {
+			g.Expect(promql_lint.Lint(promql_lint.Query{PromQL: q, Labels: []string{"instance"}})).To(BeEmpty(), q)
+		}
+	})
+
+	t.Run("returns an error for thresholds on range vectors", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		problems := promql_lint.Lint(promql_lint.Query{PromQL: `latency[5m]`, HasThresholds: true})
+		g.Expect(problems).To(ConsistOf(promql_lint.Problem{
+			Rule:     promql_lint.RangeVectorThreshold,
+			Severity: promql_lint.Error,
+			Message:  "the query returns a range vector, which thresholds cannot be compared against; wrap it in a function such as avg_over_time()",
+		}))
+
+		g.Expect(promql_lint.Lint(promql_lint.Query{PromQL: `latency[5m]`})).To(BeEmpty())
+		g.Expect(promql_lint.Lint(promql_lint.Query{PromQL: `avg_over_time(latency[5m])`, HasThresholds: true})).To(BeEmpty())
+	})
+}
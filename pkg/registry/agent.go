@@ -2,6 +2,7 @@ package registry
 
 import (
 	"errors"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"log"
@@ -64,6 +65,31 @@ func (a Agent) registerIndicatorDocuments() {
 	}
 }
 
+// Deregister deletes the found documents from the registry, together with the statuses of their
+// indicators.
+func (a Agent) Deregister() error {
+	documents, err := a.DocumentFinder.FindAll()
+	if err != nil {
+		return err
+	}
+
+	apiClient := NewAPIClient(a.RegistryURI, a.Client)
+
+	failed := 0
+	for _, d := range documents {
+		err := apiClient.DeregisterIndicatorDocument(d)
+		if err != nil {
+			failed++
+			log.Print(err)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to deregister %d of %d documents", failed, len(documents))
+	}
+
+	return nil
+}
+
 func closeBodyAndReuseConnection(resp *http.Response) {
 	_, _ = io.Copy(ioutil.Discard, resp.Body)
 	resp.Body.Close()
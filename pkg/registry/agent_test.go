@@ -60,6 +60,46 @@ func TestRegistryAgent(t *testing.T) {
 	})
 }
 
+func TestRegistryAgentDeregister(t *testing.T) {
+	buffer := bytes.NewBuffer(nil)
+	log.SetOutput(buffer)
+
+	t.Run("it deregisters the documents from the registry", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		registryServer := ghttp.NewServer()
+		defer registryServer.Close()
+		registryServer.AppendHandlers(ghttp.RespondWith(http.StatusNoContent, nil))
+
+		agent := registry.Agent{
+			DocumentFinder: registry.DocumentFinder{Glob: "./test_fixtures/job-a/indicators.yml"},
+			RegistryURI:    registryServer.URL(),
+			Client:         &http.Client{},
+		}
+
+		g.Expect(agent.Deregister()).To(Succeed())
+		g.Expect(registryServer.ReceivedRequests()).To(HaveLen(1))
+		g.Expect(registryServer.ReceivedRequests()[0].Method).To(Equal(http.MethodPost))
+		g.Expect(registryServer.ReceivedRequests()[0].URL.Path).To(Equal("/v1/deregister"))
+	})
+
+	t.Run("it returns an error if a document is not deregistered", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		registryServer := ghttp.NewServer()
+		defer registryServer.Close()
+		registryServer.AppendHandlers(ghttp.RespondWith(http.StatusNotFound, nil))
+
+		agent := registry.Agent{
+			DocumentFinder: registry.DocumentFinder{Glob: "./test_fixtures/job-a/indicators.yml"},
+			RegistryURI:    registryServer.URL(),
+			Client:         &http.Client{},
+		}
+
+		g.Expect(agent.Deregister()).To(MatchError("failed to deregister 1 of 1 documents"))
+	})
+}
+
 func TestDocumentFinder(t *testing.T) {
 	df := &registry.DocumentFinder{
 		Glob: "./test_fixtures/job-a/indicators.yml",
@@ -6,15 +6,18 @@ import (
 	"io/ioutil"
 	"log"
 	"net/http"
+	"time"
 
 	"github.com/gorilla/mux"
 
 	"github.com/pivotal/monitoring-indicator-protocol/pkg/registry/status_store"
 
+	"github.com/pivotal/monitoring-indicator-protocol/pkg/document_diff"
 	"github.com/pivotal/monitoring-indicator-protocol/pkg/indicator"
+	v1 "github.com/pivotal/monitoring-indicator-protocol/pkg/k8s/apis/indicatordocument/v1"
 )
 
-func NewRegisterHandler(store *DocumentStore) http.HandlerFunc {
+func NewRegisterHandler(store *DocumentStore, opts ...indicator.ReadOpt) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 
@@ -25,14 +28,45 @@ func NewRegisterHandler(store *DocumentStore) http.HandlerFunc {
 			return
 		}
 
-		doc, errs := indicator.ProcessDocument(store.AllPatches(), documentBytes)
+		var warnings []error
+		readOpts := append(append([]indicator.ReadOpt{}, opts...), indicator.ReportWarnings(func(warning error) {
+			warnings = append(warnings, warning)
+		}))
+
+		doc, report, errs := indicator.ProcessDocumentWithReport(store.AllPatches(), documentBytes, readOpts...)
 		if errs != nil {
-			writeErrors(w, http.StatusBadRequest, errs...)
+			writeValidationErrors(w, errs)
 			return
 		}
 
-		store.UpsertDocument(doc)
+		store.UpsertPatchedDocument(doc, report)
 		w.WriteHeader(http.StatusOK)
+		if len(warnings) > 0 {
+			_ = json.NewEncoder(w).Encode(warningResponse{Warnings: errorStrings(warnings), Details: errorDetails(warnings)})
+		}
+	}
+}
+
+// NewDeregisterHandler deletes the registered document matching the posted document, together with
+// the statuses of its indicators. The posted document is read the same way as when it is registered.
+func NewDeregisterHandler(store *DocumentStore, statusStore *status_store.Store, opts ...indicator.ReadOpt) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		defer r.Body.Close()
+		documentBytes, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			writeErrors(w, http.StatusBadRequest, err)
+			return
+		}
+
+		doc, _, errs := indicator.ProcessDocumentWithReport(store.AllPatches(), documentBytes, opts...)
+		if errs != nil {
+			writeValidationErrors(w, errs)
+			return
+		}
+
+		deleteDocument(w, store, statusStore, doc.BoshUID())
 	}
 }
 
@@ -60,19 +94,205 @@ func NewIndicatorDocumentsHandler(store *DocumentStore, statusStore *status_stor
 	}
 }
 
+func NewIndicatorDocumentHandler(store *DocumentStore, statusStore *status_store.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		documentID := mux.Vars(r)["documentID"]
+		doc, ok := store.Document(documentID)
+		if !ok {
+			writeErrors(w, http.StatusNotFound, fmt.Errorf("indicator document %s not found", documentID))
+			return
+		}
+
+		statusStore.FillStatuses(&doc)
+		err := json.NewEncoder(w).Encode(ToAPIDocumentResponse(doc))
+		if err != nil {
+			log.Printf("error writing to `/indicator-documents/%s`", documentID)
+		}
+	}
+}
+
+// NewDeleteIndicatorDocumentHandler deletes a registered document, together with the statuses of its
+// indicators.
+func NewDeleteIndicatorDocumentHandler(store *DocumentStore, statusStore *status_store.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		deleteDocument(w, store, statusStore, mux.Vars(r)["documentID"])
+	}
+}
+
+func deleteDocument(w http.ResponseWriter, store *DocumentStore, statusStore *status_store.Store, documentID string) {
+	if !store.DeleteDocument(documentID) {
+		writeErrors(w, http.StatusNotFound, fmt.Errorf("indicator document %s not found", documentID))
+		return
+	}
+	statusStore.DeleteStatuses(documentID)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func NewPatchReportHandler(store *DocumentStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		documentID := mux.Vars(r)["documentID"]
+		report, ok := store.PatchReport(documentID)
+		if !ok {
+			writeErrors(w, http.StatusNotFound, fmt.Errorf("indicator document %s not found", documentID))
+			return
+		}
+
+		err := json.NewEncoder(w).Encode(report)
+		if err != nil {
+			log.Printf("error writing to `/indicator-documents/%s/patches`", documentID)
+		}
+	}
+}
+
+// NewDocumentDiffHandler compares two product versions of a registered document, by default the
+// previous version with the current one. The `from` and `to` query parameters select other versions,
+// and `format=text` returns the human-readable change set instead of JSON.
+func NewDocumentDiffHandler(store *DocumentStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		documentID := mux.Vars(r)["documentID"]
+		versions, ok := store.DocumentVersions(documentID)
+		if !ok {
+			writeErrors(w, http.StatusNotFound, fmt.Errorf("indicator document %s not found", documentID))
+			return
+		}
+
+		query := r.URL.Query()
+		toVersion := query.Get("to")
+		if toVersion == "" {
+			toVersion = versions[0].Spec.Product.Version
+		}
+		fromVersion := query.Get("from")
+		if fromVersion == "" {
+			if len(versions) < 2 {
+				writeErrors(w, http.StatusNotFound, fmt.Errorf("indicator document %s has no previous version", documentID))
+				return
+			}
+			fromVersion = versions[1].Spec.Product.Version
+		}
+
+		from, ok := documentVersion(versions, fromVersion)
+		if !ok {
+			writeErrors(w, http.StatusNotFound, fmt.Errorf("version %s of indicator document %s not found", fromVersion, documentID))
+			return
+		}
+		to, ok := documentVersion(versions, toVersion)
+		if !ok {
+			writeErrors(w, http.StatusNotFound, fmt.Errorf("version %s of indicator document %s not found", toVersion, documentID))
+			return
+		}
+
+		changes := document_diff.Diff(from, to)
+		var err error
+		if query.Get("format") == "text" {
+			w.Header().Set("Content-Type", "text/plain")
+			_, err = fmt.Fprint(w, changes.String())
+		} else {
+			err = json.NewEncoder(w).Encode(changes)
+		}
+		if err != nil {
+			log.Printf("error writing to `/indicator-documents/%s/diff`", documentID)
+		}
+	}
+}
+
+func documentVersion(versions []v1.IndicatorDocument, version string) (v1.IndicatorDocument, bool) {
+	for _, doc := range versions {
+		if doc.Spec.Product.Version == version {
+			return doc, true
+		}
+	}
+	return v1.IndicatorDocument{}, false
+}
+
+// NewStatusHistoryHandler lists the status periods of an indicator of a registered document, from the
+// oldest. The `from` and `to` query parameters, in RFC 3339, limit it to the periods overlapping that
+// time range.
+func NewStatusHistoryHandler(store *status_store.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		vars := mux.Vars(r)
+		documentID := vars["documentID"]
+		indicatorName := vars["indicatorName"]
+
+		query := r.URL.Query()
+		from, err := parseTimeParam(query.Get("from"))
+		if err != nil {
+			writeErrors(w, http.StatusBadRequest, fmt.Errorf("invalid from: %s", err))
+			return
+		}
+		to, err := parseTimeParam(query.Get("to"))
+		if err != nil {
+			writeErrors(w, http.StatusBadRequest, fmt.Errorf("invalid to: %s", err))
+			return
+		}
+
+		periods, err := store.History(documentID, indicatorName, from, to)
+		if err != nil {
+			writeErrors(w, http.StatusNotFound, err)
+			return
+		}
+
+		err = json.NewEncoder(w).Encode(toAPIStatusHistory(indicatorName, periods))
+		if err != nil {
+			log.Printf("error writing to `/indicator-documents/%s/indicators/%s/history`", documentID, indicatorName)
+		}
+	}
+}
+
+func parseTimeParam(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
 func writeErrors(w http.ResponseWriter, statusCode int, errors ...error) {
-	errorStrings := make([]string, 0)
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(errorResponse{Errors: errorStrings(errors)})
+}
+
+func writeValidationErrors(w http.ResponseWriter, errors []error) {
+	w.WriteHeader(http.StatusBadRequest)
+	_ = json.NewEncoder(w).Encode(errorResponse{Errors: errorStrings(errors), Details: errorDetails(errors)})
+}
+
+func errorStrings(errors []error) []string {
+	messages := make([]string, 0, len(errors))
 	for _, e := range errors {
-		errorStrings = append(errorStrings, e.Error())
+		messages = append(messages, e.Error())
 	}
+	return messages
+}
 
-	w.WriteHeader(statusCode)
-	_ = json.NewEncoder(w).Encode(errorResponse{Errors: errorStrings})
+func errorDetails(errors []error) []errorDetail {
+	details := make([]errorDetail, 0, len(errors))
+	for _, e := range errors {
+		detail := errorDetail{Message: e.Error()}
+		if validationError, ok := e.(*v1.ValidationError); ok {
+			detail.Path = validationError.Path
+			detail.File = validationError.File
+			detail.Line = validationError.Line
+			detail.Column = validationError.Column
+		}
+		details = append(details, detail)
+	}
+	return details
 }
 
 type ApiV1UpdateIndicatorStatus struct {
-	Name   string  `json:"name"`
-	Status *string `json:"status"`
+	Name   string   `json:"name"`
+	Status *string  `json:"status"`
+	Value  *float64 `json:"value,omitempty"`
 }
 
 func NewIndicatorStatusBulkUpdateHandler(store *status_store.Store) http.HandlerFunc {
@@ -95,11 +315,30 @@ func NewIndicatorStatusBulkUpdateHandler(store *status_store.Store) http.Handler
 				Status:        indicatorStatus.Status,
 				IndicatorName: indicatorStatus.Name,
 				DocumentUID:   documentID,
+				Value:         indicatorStatus.Value,
 			})
 		}
 	}
 }
 
 type errorResponse struct {
-	Errors []string `json:"errors"`
+	Errors  []string      `json:"errors"`
+	Details []errorDetail `json:"details,omitempty"`
+}
+
+// warningResponse lists the problems of a registered document that don't prevent registering it, such
+// as deprecated indicators and PromQL lint warnings.
+type warningResponse struct {
+	Warnings []string      `json:"warnings"`
+	Details  []errorDetail `json:"details"`
+}
+
+// errorDetail describes where a validation error is in the registered document. The file is only set
+// for errors in imported documents.
+type errorDetail struct {
+	Message string `json:"message"`
+	Path    string `json:"path,omitempty"`
+	File    string `json:"file,omitempty"`
+	Line    int    `json:"line,omitempty"`
+	Column  int    `json:"column,omitempty"`
 }
@@ -1,13 +1,18 @@
 package registry
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
+	"time"
 )
 
 type RegistryApiClient struct {
@@ -82,3 +87,151 @@ func (c *RegistryApiClient) AddIndicatorDocument(document []byte) error {
 	closeBodyAndReuseConnection(resp)
 	return nil
 }
+
+// IndicatorDocument returns the registered document with the given UID, with the statuses of its
+// indicators.
+func (c *RegistryApiClient) IndicatorDocument(uid string) (APIDocumentResponse, error) {
+	resp, err := c.client.Get(fmt.Sprintf("%s/v1/indicator-documents/%s", c.serverURL, url.PathEscape(uid)))
+	if err != nil {
+		return APIDocumentResponse{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return APIDocumentResponse{}, fmt.Errorf("received non-successful response from registry: %d", resp.StatusCode)
+	}
+
+	var d APIDocumentResponse
+	err = json.NewDecoder(resp.Body).Decode(&d)
+
+	return d, err
+}
+
+// DeleteIndicatorDocument deletes the registered document with the given UID, and the statuses of its
+// indicators.
+func (c *RegistryApiClient) DeleteIndicatorDocument(uid string) error {
+	req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("%s/v1/indicator-documents/%s", c.serverURL, url.PathEscape(uid)), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	closeBodyAndReuseConnection(resp)
+
+	if resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("received non-successful response from registry: %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// DeregisterIndicatorDocument deletes the registered document that the document would be registered
+// as, and the statuses of its indicators.
+func (c *RegistryApiClient) DeregisterIndicatorDocument(document []byte) error {
+	resp, err := c.client.Post(c.serverURL+"/v1/deregister", "text/plain", bytes.NewBuffer(document))
+	if err != nil {
+		return err
+	}
+	closeBodyAndReuseConnection(resp)
+
+	if resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("received non-successful response from registry: %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// WatchStream reads the events of a watch. Next returns io.EOF when the registry ends the watch, after
+// which a new watch can resume from ResourceVersion.
+type WatchStream interface {
+	Next() (WatchEvent, error)
+	// ResourceVersion is the version of the last event read, or the version the watch started
+	// after if none was read.
+	ResourceVersion() string
+	Close() error
+}
+
+// The longest a watch is read without an event or a heartbeat before it is considered broken.
+const watchIdleTimeout = time.Minute
+
+// Watch streams the changes to the registry after the resource version, or after the current version
+// if it is empty. It returns ErrResourceVersionGone if the registry can no longer resume from the
+// resource version, in which case the documents should be listed again.
+func (c *RegistryApiClient) Watch(resourceVersion string) (WatchStream, error) {
+	watchURL := c.serverURL + "/v1/watch"
+	if resourceVersion != "" {
+		watchURL += "?resourceVersion=" + url.QueryEscape(resourceVersion)
+	}
+
+	// The client timeout would end the stream, so the idle timeout of the stream is used instead.
+	client := *c.client
+	client.Timeout = 0
+	resp, err := client.Get(watchURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusGone {
+		closeBodyAndReuseConnection(resp)
+		return nil, ErrResourceVersionGone
+	}
+	if resp.StatusCode != http.StatusOK {
+		closeBodyAndReuseConnection(resp)
+		return nil, fmt.Errorf("received non-successful response from registry: %d", resp.StatusCode)
+	}
+
+	return newEventStream(resp.Body, resp.Header.Get(ResourceVersionHeader)), nil
+}
+
+type eventStream struct {
+	body            io.ReadCloser
+	reader          *bufio.Reader
+	idle            *time.Timer
+	resourceVersion string
+}
+
+func newEventStream(body io.ReadCloser, resourceVersion string) *eventStream {
+	return &eventStream{
+		body:            body,
+		reader:          bufio.NewReader(body),
+		idle:            time.AfterFunc(watchIdleTimeout, func() { _ = body.Close() }),
+		resourceVersion: resourceVersion,
+	}
+}
+
+func (s *eventStream) Next() (WatchEvent, error) {
+	var data []byte
+	for {
+		line, err := s.reader.ReadString('\n')
+		if err != nil {
+			return WatchEvent{}, err
+		}
+		s.idle.Reset(watchIdleTimeout)
+
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "" && data != nil:
+			var event WatchEvent
+			err := json.Unmarshal(data, &event)
+			if err != nil {
+				return WatchEvent{}, fmt.Errorf("failed to decode watch event: %s", err)
+			}
+			s.resourceVersion = event.ResourceVersion
+			return event, nil
+		case strings.HasPrefix(line, "data: "):
+			data = append(data, strings.TrimPrefix(line, "data: ")...)
+		}
+	}
+}
+
+func (s *eventStream) ResourceVersion() string {
+	return s.resourceVersion
+}
+
+func (s *eventStream) Close() error {
+	s.idle.Stop()
+	return s.body.Close()
+}
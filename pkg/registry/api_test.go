@@ -10,10 +10,12 @@ import (
 	"time"
 
 	"github.com/benjamintf1/unmarshalledmatchers"
+	"github.com/cppforlife/go-patch/patch"
 	"github.com/gorilla/mux"
 	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/pivotal/monitoring-indicator-protocol/pkg/api_versions"
+	"github.com/pivotal/monitoring-indicator-protocol/pkg/indicator"
 
 	"github.com/pivotal/monitoring-indicator-protocol/pkg/k8s/apis/indicatordocument/v1"
 	"github.com/pivotal/monitoring-indicator-protocol/pkg/registry/status_store"
@@ -108,6 +110,43 @@ spec:
 		})
 	})
 
+	t.Run("it returns warnings for deprecated indicators", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		body := bytes.NewBuffer([]byte(`---
+apiVersion: indicatorprotocol.io/v1
+kind: IndicatorDocument
+spec:
+  product:
+    name: my-product
+    version: 1.0.0
+  indicators:
+  - name: request_latency
+    promql: request_latency
+  - name: latency
+    promql: latency
+    deprecated: true
+    replacedBy: request_latency`))
+
+		req := httptest.NewRequest("POST", "/register", body)
+		resp := httptest.NewRecorder()
+
+		docStore := registry.NewDocumentStore(1*time.Minute, time.Now)
+		registry.NewRegisterHandler(docStore)(resp, req)
+
+		g.Expect(resp.Code).To(Equal(http.StatusOK))
+		g.Expect(docStore.AllDocuments()).To(HaveLen(1))
+		g.Expect(resp.Body.String()).To(MatchJSON(`{
+			"warnings": ["indicators[1] latency is deprecated: Use request_latency instead."],
+			"details": [{
+				"message": "indicators[1] latency is deprecated: Use request_latency instead.",
+				"path": "spec.indicators[1].deprecated",
+				"line": 13,
+				"column": 5
+			}]
+		}`))
+	})
+
 	t.Run("it returns 400 if there are validation errors", func(t *testing.T) {
 		body := bytes.NewBuffer([]byte(`---
 apiVersion: indicatorprotocol.io/v1
@@ -139,9 +178,87 @@ spec:
 	"IndicatorDocument.spec.indicators.promql in body should be at least 1 chars long",
     "indicators[0] is invalid by schema: IndicatorSpec.promql in body should be at least 1 chars long",
 	"indicators[0].promql should be valid promql (see https://prometheus.io/docs/)"
+], "details": [
+	{
+		"message": "IndicatorDocument.spec.product.name in body should be at least 1 chars long",
+		"path": "spec.product.name",
+		"line": 5,
+		"column": 1
+	},
+	{
+		"message": "IndicatorDocument.spec.product.version in body should be at least 1 chars long",
+		"path": "spec.product.version",
+		"line": 5,
+		"column": 1
+	},
+	{
+		"message": "IndicatorDocument.spec.indicators.promql in body should be at least 1 chars long",
+		"path": "spec.indicators.promql",
+		"line": 6,
+		"column": 3
+	},
+	{
+		"message": "indicators[0] is invalid by schema: IndicatorSpec.promql in body should be at least 1 chars long",
+		"path": "spec.indicators[0].promql",
+		"line": 7,
+		"column": 5
+	},
+	{
+		"message": "indicators[0].promql should be valid promql (see https://prometheus.io/docs/)",
+		"path": "spec.indicators[0].promql",
+		"line": 7,
+		"column": 5
+	}
 ]}`))
 	})
 
+	t.Run("it resolves imports with the given resolver", func(t *testing.T) {
+		importingDocument := `---
+apiVersion: indicatorprotocol.io/v1
+kind: IndicatorDocument
+
+spec:
+  product:
+    name: redis-tile
+    version: v0.11
+  imports:
+  - path: library.yml
+`
+		resolver := func(location string, path string) (string, []byte, error) {
+			return path, []byte(`---
+apiVersion: indicatorprotocol.io/v1
+kind: IndicatorDocument
+
+spec:
+  product:
+    name: library
+    version: v1
+  indicators:
+  - name: imported_indicator
+    promql: prom
+`), nil
+		}
+
+		req := httptest.NewRequest("POST", "/register", bytes.NewBufferString(importingDocument))
+		resp := httptest.NewRecorder()
+
+		docStore := registry.NewDocumentStore(1*time.Minute, time.Now)
+		handle := registry.NewRegisterHandler(docStore, indicator.ResolveImports(resolver, ""))
+		handle(resp, req)
+
+		g.Expect(resp.Code).To(Equal(http.StatusOK))
+		g.Expect(docStore.AllDocuments()).To(HaveLen(1))
+		g.Expect(docStore.AllDocuments()[0].Spec.Indicators[0].Name).To(Equal("imported_indicator"))
+
+		req = httptest.NewRequest("POST", "/register", bytes.NewBufferString(importingDocument))
+		resp = httptest.NewRecorder()
+
+		registry.NewRegisterHandler(registry.NewDocumentStore(1*time.Minute, time.Now))(resp, req)
+
+		g.Expect(resp.Code).To(Equal(http.StatusBadRequest))
+		g.Expect(resp.Body.String()).To(ContainSubstring("imports[0]: imports cannot be resolved for this document"))
+	})
+
 	t.Run("it returns 400 if the yml is invalid", func(t *testing.T) {
 		body := bytes.NewBuffer([]byte(`---
 apiVersion: {
@@ -194,6 +311,7 @@ func TestBulkStatusUpdateHandler(t *testing.T) {
 			IndicatorName: "latency",
 			Status:        test_fixtures.StrPtr("critical"),
 			UpdatedAt:     now,
+			Since:         now,
 		}))
 
 		g.Expect(store.StatusFor("my-component-1234234234", "error_rate")).To(Equal(status_store.IndicatorStatus{
@@ -201,6 +319,7 @@ func TestBulkStatusUpdateHandler(t *testing.T) {
 			IndicatorName: "error_rate",
 			Status:        test_fixtures.StrPtr("warning"),
 			UpdatedAt:     now,
+			Since:         now,
 		}))
 	})
 
@@ -223,6 +342,235 @@ func TestBulkStatusUpdateHandler(t *testing.T) {
 	})
 }
 
+func TestPatchReportHandler(t *testing.T) {
+	t.Run("it returns the report of the patches applied to the registered document", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		var val interface{} = "patched_promql"
+		docStore := registry.NewDocumentStore(1*time.Minute, time.Now)
+		docStore.UpsertPatches(registry.PatchList{
+			Source: "local",
+			Patches: []indicator.Patch{{
+				APIVersion: api_versions.V1,
+				Match:      indicator.Match{Name: test_fixtures.StrPtr("my-product")},
+				Origin:     "patch.yml",
+				Operations: []patch.OpDefinition{{
+					Type:  "replace",
+					Path:  test_fixtures.StrPtr("/spec/indicators/0/promql"),
+					Value: &val,
+				}},
+			}},
+		})
+
+		body := bytes.NewBuffer([]byte(`---
+apiVersion: indicatorprotocol.io/v1
+kind: IndicatorDocument
+spec:
+  product:
+    name: my-product
+    version: 1.0.0
+  indicators:
+  - name: latency
+    promql: latency`))
+		registry.NewRegisterHandler(docStore)(httptest.NewRecorder(), httptest.NewRequest("POST", "/register", body))
+
+		req := httptest.NewRequest("GET", "/patches", nil)
+		req = mux.SetURLVars(req, map[string]string{
+			"documentID": docStore.AllDocuments()[0].BoshUID(),
+		})
+		resp := httptest.NewRecorder()
+		registry.NewPatchReportHandler(docStore)(resp, req)
+
+		g.Expect(resp.Header().Get("Content-Type")).To(Equal("application/json"))
+		g.Expect(resp.Code).To(Equal(http.StatusOK))
+		g.Expect(resp.Body.String()).To(MatchJSON(`{
+			"patches": [{
+				"origin": "patch.yml",
+				"source": "local",
+				"priority": 0,
+				"matched": true,
+				"operations": [{"type": "replace", "path": "/spec/indicators/0/promql", "applied": true}]
+			}]
+		}`))
+	})
+
+	t.Run("it returns 404 for unknown documents", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		req := httptest.NewRequest("GET", "/patches", nil)
+		req = mux.SetURLVars(req, map[string]string{
+			"documentID": "my-product-unknown",
+		})
+		resp := httptest.NewRecorder()
+		registry.NewPatchReportHandler(registry.NewDocumentStore(1*time.Minute, time.Now))(resp, req)
+
+		g.Expect(resp.Code).To(Equal(http.StatusNotFound))
+		g.Expect(resp.Body.String()).To(MatchJSON(`{"errors": ["indicator document my-product-unknown not found"]}`))
+	})
+}
+
+func TestDocumentDiffHandler(t *testing.T) {
+	register := func(store *registry.DocumentStore, version string, promql string) {
+		body := bytes.NewBuffer([]byte(`---
+apiVersion: indicatorprotocol.io/v1
+kind: IndicatorDocument
+spec:
+  product:
+    name: my-product
+    version: ` + version + `
+  indicators:
+  - name: latency
+    promql: ` + promql))
+		resp := httptest.NewRecorder()
+		registry.NewRegisterHandler(store)(resp, httptest.NewRequest("POST", "/register", body))
+		if resp.Code != http.StatusOK {
+			t.Fatalf("could not register document: %s", resp.Body.String())
+		}
+	}
+	diff := func(store *registry.DocumentStore, documentID string, query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/diff"+query, nil)
+		req = mux.SetURLVars(req, map[string]string{"documentID": documentID})
+		resp := httptest.NewRecorder()
+		registry.NewDocumentDiffHandler(store)(resp, req)
+		return resp
+	}
+
+	t.Run("it compares the previous version of a document with the current one", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		docStore := registry.NewDocumentStore(1*time.Minute, time.Now)
+		register(docStore, "1.0.0", "latency")
+		register(docStore, "1.1.0", "rate(latency[1m])")
+
+		resp := diff(docStore, docStore.AllDocuments()[0].BoshUID(), "")
+
+		g.Expect(resp.Code).To(Equal(http.StatusOK))
+		g.Expect(resp.Header().Get("Content-Type")).To(Equal("application/json"))
+		g.Expect(resp.Body.String()).To(MatchJSON(`{
+			"from": {"name": "my-product", "version": "1.0.0"},
+			"to": {"name": "my-product", "version": "1.1.0"},
+			"changes": [
+				{"type": "changed", "path": "product.version", "before": "1.0.0", "after": "1.1.0"},
+				{"type": "changed", "path": "indicators[latency].promql", "before": "latency", "after": "rate(latency[1m])"},
+				{"type": "changed", "path": "layout.title", "before": "my-product - 1.0.0", "after": "my-product - 1.1.0"}
+			]
+		}`))
+	})
+
+	t.Run("it compares the given versions as text", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		docStore := registry.NewDocumentStore(1*time.Minute, time.Now)
+		register(docStore, "1.0.0", "latency")
+		register(docStore, "1.1.0", "rate(latency[1m])")
+		register(docStore, "1.2.0", "latency")
+
+		resp := diff(docStore, docStore.AllDocuments()[0].BoshUID(), "?from=1.2.0&to=1.0.0&format=text")
+
+		g.Expect(resp.Code).To(Equal(http.StatusOK))
+		g.Expect(resp.Header().Get("Content-Type")).To(Equal("text/plain"))
+		g.Expect(resp.Body.String()).To(Equal(`my-product 1.2.0 -> my-product 1.0.0
+changed product.version: "1.2.0" -> "1.0.0"
+changed layout.title: "my-product - 1.2.0" -> "my-product - 1.0.0"
+`))
+	})
+
+	t.Run("it returns 404 for unknown documents and versions", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		docStore := registry.NewDocumentStore(1*time.Minute, time.Now)
+		register(docStore, "1.0.0", "latency")
+		documentID := docStore.AllDocuments()[0].BoshUID()
+
+		resp := diff(docStore, "my-product-unknown", "")
+		g.Expect(resp.Code).To(Equal(http.StatusNotFound))
+		g.Expect(resp.Body.String()).To(MatchJSON(`{"errors": ["indicator document my-product-unknown not found"]}`))
+
+		resp = diff(docStore, documentID, "")
+		g.Expect(resp.Code).To(Equal(http.StatusNotFound))
+		g.Expect(resp.Body.String()).To(MatchJSON(`{"errors": ["indicator document ` + documentID + ` has no previous version"]}`))
+
+		resp = diff(docStore, documentID, "?from=0.9.0")
+		g.Expect(resp.Code).To(Equal(http.StatusNotFound))
+		g.Expect(resp.Body.String()).To(MatchJSON(`{"errors": ["version 0.9.0 of indicator document ` + documentID + ` not found"]}`))
+	})
+}
+
+func TestStatusHistoryHandler(t *testing.T) {
+	start := time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	history := func(store *status_store.Store, indicatorName string, query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/history"+query, nil)
+		req = mux.SetURLVars(req, map[string]string{
+			"documentID":    "my-component-1234234234",
+			"indicatorName": indicatorName,
+		})
+		resp := httptest.NewRecorder()
+		registry.NewStatusHistoryHandler(store)(resp, req)
+		return resp
+	}
+
+	clock := start
+	store := status_store.New(func() time.Time { return clock })
+	for i, status := range []string{"healthy", "critical", "healthy"} {
+		clock = start.Add(time.Duration(i) * time.Hour)
+		store.UpdateStatus(status_store.UpdateRequest{
+			DocumentUID:   "my-component-1234234234",
+			IndicatorName: "latency",
+			Status:        test_fixtures.StrPtr(status),
+		})
+	}
+
+	t.Run("it returns the status periods of an indicator", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		resp := history(store, "latency", "")
+
+		g.Expect(resp.Header().Get("Content-Type")).To(Equal("application/json"))
+		g.Expect(resp.Code).To(Equal(http.StatusOK))
+		g.Expect(resp.Body.String()).To(MatchJSON(`{
+			"indicator": "latency",
+			"history": [
+				{"value": "healthy", "since": "2019-06-01T12:00:00Z", "until": "2019-06-01T13:00:00Z"},
+				{"value": "critical", "since": "2019-06-01T13:00:00Z", "until": "2019-06-01T14:00:00Z"},
+				{"value": "healthy", "since": "2019-06-01T14:00:00Z"}
+			]
+		}`))
+	})
+
+	t.Run("it filters the periods by time range", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		resp := history(store, "latency", "?from=2019-06-01T13:30:00Z&to=2019-06-01T13:45:00Z")
+
+		g.Expect(resp.Code).To(Equal(http.StatusOK))
+		g.Expect(resp.Body.String()).To(MatchJSON(`{
+			"indicator": "latency",
+			"history": [
+				{"value": "critical", "since": "2019-06-01T13:00:00Z", "until": "2019-06-01T14:00:00Z"}
+			]
+		}`))
+	})
+
+	t.Run("it returns 400 for invalid times", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		resp := history(store, "latency", "?from=yesterday")
+
+		g.Expect(resp.Code).To(Equal(http.StatusBadRequest))
+		g.Expect(resp.Body.String()).To(ContainSubstring("invalid from"))
+	})
+
+	t.Run("it returns 404 for unknown indicators", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		resp := history(store, "saturation", "")
+
+		g.Expect(resp.Code).To(Equal(http.StatusNotFound))
+		g.Expect(resp.Body.String()).To(MatchJSON(`{"errors": ["indicator status for document my-component-1234234234 with name saturation could not be found"]}`))
+	})
+}
+
 func TestIndicatorDocumentsHandler(t *testing.T) {
 	t.Run("it returns 200", func(t *testing.T) {
 		g := NewGomegaWithT(t)
@@ -366,6 +714,139 @@ func TestIndicatorDocumentsHandler(t *testing.T) {
 	})
 }
 
+func TestIndicatorDocumentHandler(t *testing.T) {
+	t.Run("it returns the document with its statuses", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		docStore := registry.NewDocumentStore(1*time.Minute, time.Now)
+		docStore.UpsertDocument(makeIndicatorDocument(map[string]string{
+			"deployment": "abc-123",
+		}))
+
+		statusStore := status_store.New(func() time.Time { return time.Date(2012, 12, 1, 16, 45, 19, 0, time.UTC) })
+		statusStore.UpdateStatus(status_store.UpdateRequest{
+			Status:        test_fixtures.StrPtr("critical"),
+			IndicatorName: "indie2",
+			DocumentUID:   "my-product-a-a902332065d69c1787f419e235a1f1843d98c884",
+		})
+
+		req := httptest.NewRequest("GET", "/indicator-documents/my-product-a-a902332065d69c1787f419e235a1f1843d98c884", nil)
+		req = mux.SetURLVars(req, map[string]string{
+			"documentID": "my-product-a-a902332065d69c1787f419e235a1f1843d98c884",
+		})
+		resp := httptest.NewRecorder()
+		registry.NewIndicatorDocumentHandler(docStore, statusStore)(resp, req)
+
+		g.Expect(resp.Header().Get("Content-Type")).To(Equal("application/json"))
+		g.Expect(resp.Code).To(Equal(http.StatusOK))
+
+		var expected []json.RawMessage
+		expectedJSON, err := ioutil.ReadFile("test_fixtures/example_response2.json")
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(json.Unmarshal(expectedJSON, &expected)).To(Succeed())
+		g.Expect(resp.Body.String()).To(MatchJSON(expected[0]))
+	})
+
+	t.Run("it returns 404 for unknown documents", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		req := httptest.NewRequest("GET", "/indicator-documents/my-product-unknown", nil)
+		req = mux.SetURLVars(req, map[string]string{
+			"documentID": "my-product-unknown",
+		})
+		resp := httptest.NewRecorder()
+		registry.NewIndicatorDocumentHandler(registry.NewDocumentStore(1*time.Minute, time.Now), status_store.New(time.Now))(resp, req)
+
+		g.Expect(resp.Code).To(Equal(http.StatusNotFound))
+	})
+}
+
+func TestDeleteIndicatorDocumentHandler(t *testing.T) {
+	t.Run("it deletes the document and its statuses", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		doc := makeIndicatorDocument(map[string]string{"deployment": "abc-123"})
+		docStore := registry.NewDocumentStore(1*time.Minute, time.Now)
+		docStore.UpsertDocument(doc)
+		statusStore := status_store.New(time.Now)
+		statusStore.UpdateStatus(status_store.UpdateRequest{
+			Status:        test_fixtures.StrPtr("critical"),
+			IndicatorName: "indie2",
+			DocumentUID:   doc.BoshUID(),
+		})
+
+		deleteDocument := func() *httptest.ResponseRecorder {
+			req := httptest.NewRequest("DELETE", "/indicator-documents/"+doc.BoshUID(), nil)
+			req = mux.SetURLVars(req, map[string]string{
+				"documentID": doc.BoshUID(),
+			})
+			resp := httptest.NewRecorder()
+			registry.NewDeleteIndicatorDocumentHandler(docStore, statusStore)(resp, req)
+			return resp
+		}
+
+		g.Expect(deleteDocument().Code).To(Equal(http.StatusNoContent))
+		g.Expect(docStore.AllDocuments()).To(BeEmpty())
+		_, err := statusStore.StatusFor(doc.BoshUID(), "indie2")
+		g.Expect(err).To(HaveOccurred())
+
+		g.Expect(deleteDocument().Code).To(Equal(http.StatusNotFound))
+	})
+}
+
+func TestDeregisterHandler(t *testing.T) {
+	document := `---
+apiVersion: indicatorprotocol.io/v1
+kind: IndicatorDocument
+metadata:
+  labels:
+    deployment: redis-abc-123
+spec:
+  product:
+    name: redis-tile
+    version: v0.11
+  indicators:
+  - name: test_performance_indicator
+    promql: prom`
+
+	t.Run("it deletes the registered document matching the posted one", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		docStore := registry.NewDocumentStore(1*time.Minute, time.Now)
+		statusStore := status_store.New(time.Now)
+		registry.NewRegisterHandler(docStore)(httptest.NewRecorder(), httptest.NewRequest("POST", "/register", bytes.NewBufferString(document)))
+		uid := docStore.AllDocuments()[0].BoshUID()
+		statusStore.UpdateStatus(status_store.UpdateRequest{
+			Status:        test_fixtures.StrPtr("critical"),
+			IndicatorName: "test_performance_indicator",
+			DocumentUID:   uid,
+		})
+
+		deregister := func() *httptest.ResponseRecorder {
+			resp := httptest.NewRecorder()
+			registry.NewDeregisterHandler(docStore, statusStore)(resp, httptest.NewRequest("POST", "/deregister", bytes.NewBufferString(document)))
+			return resp
+		}
+
+		g.Expect(deregister().Code).To(Equal(http.StatusNoContent))
+		g.Expect(docStore.AllDocuments()).To(BeEmpty())
+		_, err := statusStore.StatusFor(uid, "test_performance_indicator")
+		g.Expect(err).To(HaveOccurred())
+
+		g.Expect(deregister().Code).To(Equal(http.StatusNotFound))
+	})
+
+	t.Run("it returns 400 if the document is invalid", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		resp := httptest.NewRecorder()
+		registry.NewDeregisterHandler(registry.NewDocumentStore(1*time.Minute, time.Now), status_store.New(time.Now))(resp,
+			httptest.NewRequest("POST", "/deregister", bytes.NewBufferString("spec: [")))
+
+		g.Expect(resp.Code).To(Equal(http.StatusBadRequest))
+	})
+}
+
 func makeIndicatorDocument(labels map[string]string) v1.IndicatorDocument {
 	return v1.IndicatorDocument{
 		TypeMeta: metaV1.TypeMeta{
@@ -8,6 +8,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	v1 "github.com/pivotal/monitoring-indicator-protocol/pkg/k8s/apis/indicatordocument/v1"
+	"github.com/pivotal/monitoring-indicator-protocol/pkg/registry/status_store"
 )
 
 type APIDocumentResponse struct {
@@ -23,9 +24,19 @@ type APIMetadataResponse struct {
 }
 
 type APIDocumentSpecResponse struct {
-	Product    APIProductResponse     `json:"product"`
-	Indicators []APIIndicatorResponse `json:"indicators"`
-	Layout     APILayoutResponse      `json:"layout"`
+	Product    APIProductResponse                 `json:"product"`
+	Indicators []APIIndicatorResponse             `json:"indicators"`
+	SLOs       []APIServiceLevelObjectiveResponse `json:"slos,omitempty"`
+	Layout     APILayoutResponse                  `json:"layout"`
+}
+
+type APIServiceLevelObjectiveResponse struct {
+	Name          string            `json:"name"`
+	GoodEvents    string            `json:"goodEvents"`
+	TotalEvents   string            `json:"totalEvents"`
+	Objective     float64           `json:"objective"`
+	Window        string            `json:"window"`
+	Documentation map[string]string `json:"documentation,omitempty"`
 }
 
 type APIProductResponse struct {
@@ -34,10 +45,14 @@ type APIProductResponse struct {
 }
 
 type APIThresholdResponse struct {
-	Level    string           `json:"level"`
-	Operator string           `json:"operator"`
-	Value    float64          `json:"value"`
-	Alert    APIAlertResponse `json:"alert"`
+	Level    string            `json:"level"`
+	Operator string            `json:"operator"`
+	Value    float64           `json:"value"`
+	Lower    *float64          `json:"lower,omitempty"`
+	Upper    *float64          `json:"upper,omitempty"`
+	Recovery *float64          `json:"recovery,omitempty"`
+	Matchers map[string]string `json:"matchers,omitempty"`
+	Alert    APIAlertResponse  `json:"alert"`
 }
 
 type APIPresentationResponse struct {
@@ -52,20 +67,55 @@ type APIIndicatorResponse struct {
 	Name          string                      `json:"name"`
 	Type          string                      `json:"type"`
 	PromQL        string                      `json:"promql"`
+	Composite     *APICompositeResponse       `json:"composite,omitempty"`
 	Thresholds    []APIThresholdResponse      `json:"thresholds"`
+	Alert         *APIAlertMetadataResponse   `json:"alert,omitempty"`
 	Documentation map[string]string           `json:"documentation,omitempty"`
 	Presentation  APIPresentationResponse     `json:"presentation"`
 	Status        *APIIndicatorStatusResponse `json:"status"`
+
+	Deprecated         bool   `json:"deprecated,omitempty"`
+	DeprecationMessage string `json:"deprecationMessage,omitempty"`
+	ReplacedBy         string `json:"replacedBy,omitempty"`
+}
+
+type APICompositeResponse struct {
+	Rule       string   `json:"rule"`
+	Level      string   `json:"level,omitempty"`
+	Indicators []string `json:"indicators"`
 }
 
 type APIIndicatorStatusResponse struct {
 	Value     *string   `json:"value"`
 	UpdatedAt time.Time `json:"updatedAt"`
+	Since     time.Time `json:"since"`
+}
+
+type APIStatusHistoryResponse struct {
+	Indicator string                    `json:"indicator"`
+	History   []APIStatusPeriodResponse `json:"history"`
+}
+
+type APIStatusPeriodResponse struct {
+	Value *string    `json:"value"`
+	Since time.Time  `json:"since"`
+	Until *time.Time `json:"until,omitempty"`
 }
 
 type APIAlertResponse struct {
-	For  string `json:"for"`
-	Step string `json:"step"`
+	For         string            `json:"for"`
+	Step        string            `json:"step"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	RunbookURL  string            `json:"runbookURL,omitempty"`
+	Owner       string            `json:"owner,omitempty"`
+}
+
+type APIAlertMetadataResponse struct {
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	RunbookURL  string            `json:"runbookURL,omitempty"`
+	Owner       string            `json:"owner,omitempty"`
 }
 
 type APILayoutResponse struct {
@@ -103,6 +153,7 @@ func ToIndicatorDocument(d APIDocumentResponse) v1.IndicatorDocument {
 				Version: d.Spec.Product.Version,
 			},
 			Indicators: indicators,
+			SLOs:       convertSLOs(d.Spec.SLOs),
 			Layout:     convertLayout(d.Spec.Layout),
 		},
 	}
@@ -116,7 +167,9 @@ func convertIndicator(i APIIndicatorResponse) v1.IndicatorSpec {
 		Name:          i.Name,
 		Type:          v1.IndicatorTypeFromString(i.Type),
 		PromQL:        i.PromQL,
+		Composite:     ConvertComposite(i.Composite),
 		Thresholds:    thresholds,
+		Alert:         convertAlertMetadata(i.Alert),
 		Documentation: i.Documentation,
 		Presentation: v1.Presentation{
 			ChartType:    v1.ChartTypeFromString(i.Presentation.ChartType),
@@ -125,6 +178,32 @@ func convertIndicator(i APIIndicatorResponse) v1.IndicatorSpec {
 			Labels:       i.Presentation.Labels,
 			Units:        i.Presentation.Units,
 		},
+		Deprecated:         i.Deprecated,
+		DeprecationMessage: i.DeprecationMessage,
+		ReplacedBy:         i.ReplacedBy,
+	}
+}
+
+func ConvertComposite(c *APICompositeResponse) *v1.Composite {
+	if c == nil {
+		return nil
+	}
+	return &v1.Composite{
+		Rule:       v1.CompositeRule(c.Rule),
+		Level:      c.Level,
+		Indicators: c.Indicators,
+	}
+}
+
+func convertAlertMetadata(a *APIAlertMetadataResponse) v1.AlertMetadata {
+	if a == nil {
+		return v1.AlertMetadata{}
+	}
+	return v1.AlertMetadata{
+		Labels:      a.Labels,
+		Annotations: a.Annotations,
+		RunbookURL:  a.RunbookURL,
+		Owner:       a.Owner,
 	}
 }
 
@@ -141,13 +220,42 @@ func convertThreshold(t APIThresholdResponse) v1.Threshold {
 		Level:    t.Level,
 		Operator: v1.GetComparatorFromString(t.Operator),
 		Value:    t.Value,
+		Lower:    t.Lower,
+		Upper:    t.Upper,
+		Recovery: t.Recovery,
+		Matchers: t.Matchers,
 		Alert: v1.Alert{
 			For:  t.Alert.For,
 			Step: t.Alert.Step,
+			AlertMetadata: v1.AlertMetadata{
+				Labels:      t.Alert.Labels,
+				Annotations: t.Alert.Annotations,
+				RunbookURL:  t.Alert.RunbookURL,
+				Owner:       t.Alert.Owner,
+			},
 		},
 	}
 }
 
+func convertSLOs(apiSLOs []APIServiceLevelObjectiveResponse) []v1.ServiceLevelObjective {
+	if len(apiSLOs) == 0 {
+		return nil
+	}
+
+	slos := make([]v1.ServiceLevelObjective, 0, len(apiSLOs))
+	for _, s := range apiSLOs {
+		slos = append(slos, v1.ServiceLevelObjective{
+			Name:          s.Name,
+			GoodEvents:    s.GoodEvents,
+			TotalEvents:   s.TotalEvents,
+			Objective:     s.Objective,
+			Window:        s.Window,
+			Documentation: s.Documentation,
+		})
+	}
+	return slos
+}
+
 func convertLayout(l APILayoutResponse) v1.Layout {
 	return v1.Layout{
 		Title:       l.Title,
@@ -185,9 +293,17 @@ func ToAPIDocumentResponse(doc v1.IndicatorDocument) APIDocumentResponse {
 				Level:    t.Level,
 				Operator: v1.GetComparatorAbbrev(t.Operator),
 				Value:    t.Value,
+				Lower:    t.Lower,
+				Upper:    t.Upper,
+				Recovery: t.Recovery,
+				Matchers: t.Matchers,
 				Alert: APIAlertResponse{
-					For:  t.Alert.For,
-					Step: t.Alert.Step,
+					For:         t.Alert.For,
+					Step:        t.Alert.Step,
+					Labels:      t.Alert.Labels,
+					Annotations: t.Alert.Annotations,
+					RunbookURL:  t.Alert.RunbookURL,
+					Owner:       t.Alert.Owner,
 				},
 			})
 		}
@@ -207,10 +323,28 @@ func ToAPIDocumentResponse(doc v1.IndicatorDocument) APIDocumentResponse {
 			Name:          i.Name,
 			Type:          v1.IndicatorTypeToString(i.Type),
 			PromQL:        i.PromQL,
+			Composite:     toAPIComposite(i.Composite),
 			Thresholds:    thresholds,
+			Alert:         toAPIAlertMetadata(i.Alert),
 			Documentation: i.Documentation,
 			Presentation:  presentation,
 			Status:        getStatus(doc, i),
+
+			Deprecated:         i.Deprecated,
+			DeprecationMessage: i.DeprecationMessage,
+			ReplacedBy:         i.ReplacedBy,
+		})
+	}
+
+	var slos []APIServiceLevelObjectiveResponse
+	for _, s := range doc.Spec.SLOs {
+		slos = append(slos, APIServiceLevelObjectiveResponse{
+			Name:          s.Name,
+			GoodEvents:    s.GoodEvents,
+			TotalEvents:   s.TotalEvents,
+			Objective:     s.Objective,
+			Window:        s.Window,
+			Documentation: s.Documentation,
 		})
 	}
 
@@ -237,6 +371,7 @@ func ToAPIDocumentResponse(doc v1.IndicatorDocument) APIDocumentResponse {
 				Version: doc.Spec.Product.Version,
 			},
 			Indicators: indicators,
+			SLOs:       slos,
 			Layout: APILayoutResponse{
 				Title:       doc.Spec.Layout.Title,
 				Description: doc.Spec.Layout.Description,
@@ -247,10 +382,49 @@ func ToAPIDocumentResponse(doc v1.IndicatorDocument) APIDocumentResponse {
 	}
 }
 
+func toAPIComposite(c *v1.Composite) *APICompositeResponse {
+	if c == nil {
+		return nil
+	}
+	return &APICompositeResponse{
+		Rule:       string(c.Rule),
+		Level:      c.Level,
+		Indicators: c.Indicators,
+	}
+}
+
+func toAPIAlertMetadata(a v1.AlertMetadata) *APIAlertMetadataResponse {
+	if a.IsEmpty() {
+		return nil
+	}
+	return &APIAlertMetadataResponse{
+		Labels:      a.Labels,
+		Annotations: a.Annotations,
+		RunbookURL:  a.RunbookURL,
+		Owner:       a.Owner,
+	}
+}
+
 func getStatus(doc v1.IndicatorDocument, i v1.IndicatorSpec) *APIIndicatorStatusResponse {
 	status, ok := doc.Status[i.Name]
 	if !ok {
 		return nil
 	}
-	return &APIIndicatorStatusResponse{Value: &status.Phase}
+	return &APIIndicatorStatusResponse{
+		Value:     &status.Phase,
+		UpdatedAt: status.UpdatedAt.Time,
+		Since:     status.Since.Time,
+	}
+}
+
+func toAPIStatusHistory(indicatorName string, periods []status_store.StatusPeriod) APIStatusHistoryResponse {
+	history := make([]APIStatusPeriodResponse, 0, len(periods))
+	for _, p := range periods {
+		history = append(history, APIStatusPeriodResponse{
+			Value: p.Status,
+			Since: p.Since,
+			Until: p.Until,
+		})
+	}
+	return APIStatusHistoryResponse{Indicator: indicatorName, History: history}
 }
@@ -122,4 +122,102 @@ func TestDocumentTranslation(t *testing.T) {
 			},
 		}))
 	})
+	t.Run("it translates composite indicators both ways", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		doc := v1.IndicatorDocument{
+			Spec: v1.IndicatorDocumentSpec{
+				Product: v1.Product{Name: "important-application", Version: "1.0"},
+				Indicators: []v1.IndicatorSpec{{
+					Name: "health",
+					Composite: &v1.Composite{
+						Rule:       v1.AllRule,
+						Level:      "warning",
+						Indicators: []string{"latency", "errors"},
+					},
+				}},
+			},
+		}
+
+		response := registry.ToAPIDocumentResponse(doc)
+		g.Expect(response.Spec.Indicators[0].Composite).To(Equal(&registry.APICompositeResponse{
+			Rule:       "all",
+			Level:      "warning",
+			Indicators: []string{"latency", "errors"},
+		}))
+		g.Expect(registry.ToIndicatorDocument(response).Spec.Indicators[0].Composite).To(Equal(doc.Spec.Indicators[0].Composite))
+	})
+	t.Run("it translates alert metadata both ways", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		indicator := v1.IndicatorSpec{
+			Name:   "latency",
+			PromQL: "latency",
+			Alert: v1.AlertMetadata{
+				Labels:     map[string]string{"team": "a-team"},
+				RunbookURL: "https://example.com/runbook",
+			},
+			Thresholds: []v1.Threshold{{
+				Level:    "critical",
+				Operator: v1.GreaterThan,
+				Value:    100,
+				Alert: v1.Alert{
+					For:  "1m",
+					Step: "1m",
+					AlertMetadata: v1.AlertMetadata{
+						Annotations: map[string]string{"summary": "{{ $value }}ms"},
+						Owner:       "b-team",
+					},
+				},
+			}},
+		}
+		doc := v1.IndicatorDocument{
+			Spec: v1.IndicatorDocumentSpec{
+				Product:    v1.Product{Name: "important-application", Version: "1.0"},
+				Indicators: []v1.IndicatorSpec{indicator},
+			},
+		}
+
+		response := registry.ToAPIDocumentResponse(doc)
+		g.Expect(response.Spec.Indicators[0].Alert).To(Equal(&registry.APIAlertMetadataResponse{
+			Labels:     map[string]string{"team": "a-team"},
+			RunbookURL: "https://example.com/runbook",
+		}))
+		g.Expect(response.Spec.Indicators[0].Thresholds[0].Alert).To(Equal(registry.APIAlertResponse{
+			For:         "1m",
+			Step:        "1m",
+			Annotations: map[string]string{"summary": "{{ $value }}ms"},
+			Owner:       "b-team",
+		}))
+
+		converted := registry.ToIndicatorDocument(response).Spec.Indicators[0]
+		g.Expect(converted.Alert).To(Equal(indicator.Alert))
+		g.Expect(converted.Thresholds).To(Equal(indicator.Thresholds))
+	})
+	t.Run("it translates deprecation both ways", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		doc := v1.IndicatorDocument{
+			Spec: v1.IndicatorDocumentSpec{
+				Product: v1.Product{Name: "important-application", Version: "1.0"},
+				Indicators: []v1.IndicatorSpec{{
+					Name:               "latency",
+					PromQL:             "latency",
+					Deprecated:         true,
+					DeprecationMessage: "Latency is measured per request now.",
+					ReplacedBy:         "request_latency",
+				}},
+			},
+		}
+
+		response := registry.ToAPIDocumentResponse(doc)
+		g.Expect(response.Spec.Indicators[0].Deprecated).To(BeTrue())
+		g.Expect(response.Spec.Indicators[0].DeprecationMessage).To(Equal("Latency is measured per request now."))
+		g.Expect(response.Spec.Indicators[0].ReplacedBy).To(Equal("request_latency"))
+
+		converted := registry.ToIndicatorDocument(response).Spec.Indicators[0]
+		g.Expect(converted.Deprecated).To(BeTrue())
+		g.Expect(converted.DeprecationMessage).To(Equal("Latency is measured per request now."))
+		g.Expect(converted.ReplacedBy).To(Equal("request_latency"))
+	})
 }
@@ -1,11 +1,15 @@
 package status_store
 
 import (
+	"encoding/json"
 	"fmt"
+	"log"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/pivotal/monitoring-indicator-protocol/pkg/k8s/apis/indicatordocument/v1"
+	"github.com/pivotal/monitoring-indicator-protocol/pkg/registry/storage"
 	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -15,57 +19,347 @@ type UpdateRequest struct {
 	Status        *string
 	IndicatorName string
 	DocumentUID   string
+	// Value is the value of the indicator the status was evaluated from, if it is known.
+	Value *float64
 }
 
 type IndicatorStatus struct {
 	DocumentUID   string
 	IndicatorName string
 	Status        *string
+	Value         *float64
 	UpdatedAt     time.Time
+	// Since is when the indicator last changed to its status.
+	Since time.Time
 }
 
-func New(clock Clock) *Store {
-	return &Store{clock: clock}
+// StatusTransition is a change of an indicator's status.
+type StatusTransition struct {
+	Status *string
+	At     time.Time
+}
+
+// StatusPeriod is a period during which an indicator had a status. Until is nil for the current
+// status.
+type StatusPeriod struct {
+	Status *string
+	Since  time.Time
+	Until  *time.Time
+}
+
+// StatusChange is reported to the transition handler when an indicator changes from one status to
+// another.
+type StatusChange struct {
+	DocumentUID   string
+	IndicatorName string
+	OldStatus     *string
+	NewStatus     *string
+	Value         *float64
+	At            time.Time
+}
+
+// The number of transitions kept for each indicator by default.
+const defaultHistoryRetention = 100
+
+type Opt func(*Store)
+
+// WithHistoryRetention sets the number of status transitions kept for each indicator. Older
+// transitions are dropped.
+func WithHistoryRetention(transitions int) Opt {
+	return func(store *Store) {
+		store.historyRetention = transitions
+	}
+}
+
+// WithTransitionHandler calls the handler after each change of an indicator's status. The first
+// status reported for an indicator is not a change. The handler is called synchronously, so it
+// should not block.
+func WithTransitionHandler(handler func(StatusChange)) Opt {
+	return func(store *Store) {
+		store.transitionHandlers = append(store.transitionHandlers, handler)
+	}
+}
+
+// WithStorage persists the statuses in the storage, and restores them from it when the store is
+// created.
+func WithStorage(s storage.Storage) Opt {
+	return func(store *Store) {
+		store.storage = s
+	}
+}
+
+// WithDocumentLookup makes the store evaluate composite indicators. When the status of an indicator
+// changes, the composite indicators of its document that include it are updated as well.
+func WithDocumentLookup(lookup func(uid string) (v1.IndicatorDocument, bool)) Opt {
+	return func(store *Store) {
+		store.lookupDocument = lookup
+	}
+}
+
+func New(clock Clock, opts ...Opt) *Store {
+	s := &Store{
+		clock:            clock,
+		storage:          storage.NewMemory(),
+		historyRetention: defaultHistoryRetention,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.restoreStatuses()
+
+	return s
 }
 
 type Store struct {
 	sync.Mutex
-	statuses []IndicatorStatus
-	clock    Clock
+	statuses         []indicatorHistory
+	clock            Clock
+	storage          storage.Storage
+	historyRetention int
+	lookupDocument   func(uid string) (v1.IndicatorDocument, bool)
+
+	transitionHandlers []func(StatusChange)
+}
+
+// indicatorHistory is the current status of an indicator together with its transitions, from the
+// oldest. It is also how statuses are kept in the storage.
+type indicatorHistory struct {
+	IndicatorStatus
+	Transitions []StatusTransition `json:",omitempty"`
 }
 
+const statusKeyPrefix = "statuses/"
+
 func (s *Store) UpdateStatus(request UpdateRequest) {
+	change, changed, transitioned := s.updateStatus(request)
+	if changed {
+		for _, handler := range s.transitionHandlers {
+			handler(change)
+		}
+	}
+	if transitioned {
+		s.updateComposites(request.DocumentUID, request.IndicatorName)
+	}
+}
+
+// The status of a composite indicator is evaluated from the current statuses of its indicators each
+// time one of them changes, and recorded like any other status.
+func (s *Store) updateComposites(documentUID string, indicatorName string) {
+	if s.lookupDocument == nil {
+		return
+	}
+	doc, ok := s.lookupDocument(documentUID)
+	if !ok {
+		return
+	}
+
+	for _, indicator := range doc.Spec.Indicators {
+		if !indicator.IsComposite() || !containsString(indicator.Composite.Indicators, indicatorName) {
+			continue
+		}
+
+		status := indicator.Composite.Evaluate(s.phases(documentUID))
+		s.UpdateStatus(UpdateRequest{
+			DocumentUID:   documentUID,
+			IndicatorName: indicator.Name,
+			Status:        &status,
+		})
+	}
+}
+
+func (s *Store) phases(documentUID string) map[string]string {
+	s.Lock()
+	defer s.Unlock()
+
+	phases := make(map[string]string)
+	for _, status := range s.statuses {
+		if status.DocumentUID == documentUID && status.Status != nil {
+			phases[status.IndicatorName] = *status.Status
+		}
+	}
+	return phases
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Store) updateStatus(request UpdateRequest) (StatusChange, bool, bool) {
 	s.Lock()
 	defer s.Unlock()
 
-	newStatus := IndicatorStatus{
+	now := s.clock()
+
+	idx := s.getPosition(request.DocumentUID, request.IndicatorName)
+	known := idx != -1
+	if !known {
+		s.statuses = append(s.statuses, indicatorHistory{})
+		idx = len(s.statuses) - 1
+	}
+	history := &s.statuses[idx]
+
+	change := StatusChange{
 		DocumentUID:   request.DocumentUID,
 		IndicatorName: request.IndicatorName,
-		Status:        request.Status,
-		UpdatedAt:     s.clock(),
+		OldStatus:     history.Status,
+		NewStatus:     request.Status,
+		Value:         request.Value,
+		At:            now,
+	}
+	changed := known && !equalStatus(history.Status, request.Status)
+
+	transitioned := len(history.Transitions) == 0 || !equalStatus(history.Status, request.Status)
+	if transitioned {
+		history.Since = now
+		history.Transitions = append(history.Transitions, StatusTransition{Status: request.Status, At: now})
+		if len(history.Transitions) > s.historyRetention {
+			history.Transitions = history.Transitions[len(history.Transitions)-s.historyRetention:]
+		}
+	}
+	history.DocumentUID = request.DocumentUID
+	history.IndicatorName = request.IndicatorName
+	history.Status = request.Status
+	history.Value = request.Value
+	history.UpdatedAt = now
+
+	// Statuses are reported again every interval, mostly unchanged. Only transitions are persisted,
+	// so a restored status has the value and update time of its last transition until it is
+	// reported again.
+	if transitioned {
+		s.persistStatus(*history)
 	}
 
+	return change, changed, transitioned
+}
+
+func equalStatus(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func (s *Store) getPosition(documentUID string, indicatorName string) int {
 	for idx, status := range s.statuses {
-		if status.DocumentUID == request.DocumentUID && status.IndicatorName == request.IndicatorName {
-			s.statuses[idx] = newStatus
-			return
+		if status.DocumentUID == documentUID && status.IndicatorName == indicatorName {
+			return idx
 		}
 	}
 
-	s.statuses = append(s.statuses, newStatus)
+	return -1
 }
 
-func (s *Store) StatusFor(documentUID string, indicatorName string) (IndicatorStatus, error) {
+func (s *Store) persistStatus(status indicatorHistory) {
+	value, err := json.Marshal(status)
+	if err == nil {
+		err = s.storage.Put(statusKey(status.DocumentUID, status.IndicatorName), value)
+	}
+	if err != nil {
+		log.Printf("failed to persist status of %s in document %s: %s", status.IndicatorName, status.DocumentUID, err)
+	}
+}
+
+// restoreStatuses loads the statuses persisted by an earlier registry, in the order they were
+// updated.
+func (s *Store) restoreStatuses() {
+	values, err := s.storage.All(statusKeyPrefix)
+	if err != nil {
+		log.Printf("failed to restore persisted statuses: %s", err)
+		return
+	}
+
+	for key, value := range values {
+		var status indicatorHistory
+		err := json.Unmarshal(value, &status)
+		if err != nil {
+			log.Printf("failed to restore persisted status %s: %s", key, err)
+			continue
+		}
+		s.statuses = append(s.statuses, status)
+	}
+
+	sort.SliceStable(s.statuses, func(i, j int) bool {
+		return s.statuses[i].UpdatedAt.Before(s.statuses[j].UpdatedAt)
+	})
+}
+
+// DeleteStatuses removes the statuses of the indicators of a document, together with their history.
+func (s *Store) DeleteStatuses(documentUID string) {
 	s.Lock()
 	defer s.Unlock()
 
+	statuses := make([]indicatorHistory, 0, len(s.statuses))
 	for _, status := range s.statuses {
-		if status.DocumentUID == documentUID && status.IndicatorName == indicatorName {
-			return status, nil
+		if status.DocumentUID != documentUID {
+			statuses = append(statuses, status)
+			continue
 		}
+
+		err := s.storage.Delete(statusKey(status.DocumentUID, status.IndicatorName))
+		if err != nil {
+			log.Printf("failed to delete persisted status of %s in document %s: %s", status.IndicatorName, status.DocumentUID, err)
+		}
+	}
+
+	s.statuses = statuses
+}
+
+func statusKey(documentUID string, indicatorName string) string {
+	return fmt.Sprintf("%s%s/%s", statusKeyPrefix, documentUID, indicatorName)
+}
+
+func (s *Store) StatusFor(documentUID string, indicatorName string) (IndicatorStatus, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	idx := s.getPosition(documentUID, indicatorName)
+	if idx == -1 {
+		return IndicatorStatus{}, notFoundError(documentUID, indicatorName)
 	}
 
-	return IndicatorStatus{}, fmt.Errorf("indicator status for document %s with name %s could not be found", documentUID, indicatorName)
+	return s.statuses[idx].IndicatorStatus, nil
+}
+
+// History returns the periods of the indicator's statuses that overlap the time range, from the
+// oldest. A zero from or to leaves the range open on that side. Only the periods of the retained
+// transitions are known.
+func (s *Store) History(documentUID string, indicatorName string, from time.Time, to time.Time) ([]StatusPeriod, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	idx := s.getPosition(documentUID, indicatorName)
+	if idx == -1 {
+		return nil, notFoundError(documentUID, indicatorName)
+	}
+
+	transitions := s.statuses[idx].Transitions
+	periods := make([]StatusPeriod, 0, len(transitions))
+	for i, transition := range transitions {
+		period := StatusPeriod{Status: transition.Status, Since: transition.At}
+		if i+1 < len(transitions) {
+			until := transitions[i+1].At
+			period.Until = &until
+		}
+
+		if !to.IsZero() && period.Since.After(to) {
+			continue
+		}
+		if !from.IsZero() && period.Until != nil && !period.Until.After(from) {
+			continue
+		}
+		periods = append(periods, period)
+	}
+
+	return periods, nil
+}
+
+func notFoundError(documentUID string, indicatorName string) error {
+	return fmt.Errorf("indicator status for document %s with name %s could not be found", documentUID, indicatorName)
 }
 
 func (s *Store) FillStatuses(doc *v1.IndicatorDocument) {
@@ -80,6 +374,7 @@ func (s *Store) FillStatuses(doc *v1.IndicatorDocument) {
 			if status.Status != nil {
 				newStatus.Phase = *status.Status
 				newStatus.UpdatedAt = metaV1.Time{Time: status.UpdatedAt}
+				newStatus.Since = metaV1.Time{Time: status.Since}
 			}
 			docStatus[status.IndicatorName] = newStatus
 		}
@@ -5,6 +5,7 @@ import (
 	"github.com/pivotal/monitoring-indicator-protocol/pkg/api_versions"
 	"github.com/pivotal/monitoring-indicator-protocol/pkg/k8s/apis/indicatordocument/v1"
 	"github.com/pivotal/monitoring-indicator-protocol/pkg/registry/status_store"
+	"github.com/pivotal/monitoring-indicator-protocol/pkg/registry/storage"
 	"github.com/pivotal/monitoring-indicator-protocol/test_fixtures"
 	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
@@ -39,9 +40,84 @@ func TestUpdatingStatus(t *testing.T) {
 			IndicatorName: "latency",
 			Status:        test_fixtures.StrPtr("critical"),
 			UpdatedAt:     now,
+			Since:         now,
 		}))
 	})
 
+	t.Run("it restores the statuses in its storage", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		s := storage.NewMemory()
+		store := status_store.New(fakeClock, status_store.WithStorage(s))
+		store.UpdateStatus(status_store.UpdateRequest{
+			DocumentUID:   "abc-123",
+			IndicatorName: "latency",
+			Status:        test_fixtures.StrPtr("warning"),
+		})
+		store.UpdateStatus(status_store.UpdateRequest{
+			DocumentUID:   "abc-123",
+			IndicatorName: "latency",
+			Status:        test_fixtures.StrPtr("critical"),
+		})
+
+		restored := status_store.New(fakeClock, status_store.WithStorage(s))
+
+		status, err := restored.StatusFor("abc-123", "latency")
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(*status.Status).To(Equal("critical"))
+		g.Expect(status.UpdatedAt.Equal(now)).To(BeTrue())
+	})
+
+	t.Run("it only persists statuses that changed", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		s := &countingStorage{Storage: storage.NewMemory()}
+		store := status_store.New(fakeClock, status_store.WithStorage(s))
+		for _, status := range []string{"healthy", "healthy", "healthy", "critical", "critical"} {
+			store.UpdateStatus(status_store.UpdateRequest{
+				DocumentUID:   "abc-123",
+				IndicatorName: "latency",
+				Status:        test_fixtures.StrPtr(status),
+			})
+		}
+
+		g.Expect(s.puts).To(Equal(2))
+
+		restored := status_store.New(fakeClock, status_store.WithStorage(s))
+		status, err := restored.StatusFor("abc-123", "latency")
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(*status.Status).To(Equal("critical"))
+	})
+
+	t.Run("it deletes the statuses of a document", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		s := storage.NewMemory()
+		store := status_store.New(fakeClock, status_store.WithStorage(s))
+		for _, update := range []status_store.UpdateRequest{
+			{DocumentUID: "abc-123", IndicatorName: "latency", Status: test_fixtures.StrPtr("critical")},
+			{DocumentUID: "abc-123", IndicatorName: "error_rate", Status: test_fixtures.StrPtr("healthy")},
+			{DocumentUID: "def-456", IndicatorName: "latency", Status: test_fixtures.StrPtr("warning")},
+		} {
+			store.UpdateStatus(update)
+		}
+
+		store.DeleteStatuses("abc-123")
+
+		_, err := store.StatusFor("abc-123", "latency")
+		g.Expect(err).To(HaveOccurred())
+		_, err = store.StatusFor("abc-123", "error_rate")
+		g.Expect(err).To(HaveOccurred())
+		_, err = store.StatusFor("def-456", "latency")
+		g.Expect(err).ToNot(HaveOccurred())
+
+		restored := status_store.New(fakeClock, status_store.WithStorage(s))
+		_, err = restored.StatusFor("abc-123", "latency")
+		g.Expect(err).To(HaveOccurred())
+		_, err = restored.StatusFor("def-456", "latency")
+		g.Expect(err).ToNot(HaveOccurred())
+	})
+
 	t.Run("it returns an error if the status was never updated", func(t *testing.T) {
 		g := NewGomegaWithT(t)
 
@@ -73,6 +149,7 @@ func TestUpdatingStatus(t *testing.T) {
 			IndicatorName: "latency",
 			Status:        test_fixtures.StrPtr("critical"),
 			UpdatedAt:     now,
+			Since:         now,
 		}))
 	})
 }
@@ -135,11 +212,270 @@ func TestFillingStatuses(t *testing.T) {
 			"error_rate": {
 				Phase:     "critical",
 				UpdatedAt: metaV1.Time{Time: fixedTime},
+				Since:     metaV1.Time{Time: fixedTime},
 			},
 			"latency": {
 				Phase:     "warning",
 				UpdatedAt: metaV1.Time{Time: fixedTime},
+				Since:     metaV1.Time{Time: fixedTime},
+			},
+		}))
+	})
+	compositeDocument := v1.IndicatorDocument{
+		ObjectMeta: metaV1.ObjectMeta{
+			Labels: map[string]string{
+				"source_id": "bar",
 			},
+		},
+		Spec: v1.IndicatorDocumentSpec{
+			Product: v1.Product{
+				Name:    "abc",
+				Version: "1.2.3",
+			},
+			Indicators: []v1.IndicatorSpec{{
+				Name:   "error_rate",
+				PromQL: "error_rate",
+			}, {
+				Name:   "latency",
+				PromQL: "latency",
+			}, {
+				Name: "any_warning",
+				Composite: &v1.Composite{
+					Rule:       v1.AnyRule,
+					Level:      "warning",
+					Indicators: []string{"error_rate", "latency"},
+				},
+			}, {
+				Name: "without_statuses",
+				Composite: &v1.Composite{
+					Rule:       v1.WorstRule,
+					Indicators: []string{"saturation"},
+				},
+			}},
+		},
+	}
+	lookup := func(uid string) (v1.IndicatorDocument, bool) {
+		return compositeDocument, uid == compositeDocument.BoshUID()
+	}
+
+	t.Run("evaluates composite indicators from the statuses of their indicators", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		clock := fixedTime
+		store := status_store.New(func() time.Time { return clock }, status_store.WithDocumentLookup(lookup))
+
+		store.UpdateStatus(status_store.UpdateRequest{
+			DocumentUID:   compositeDocument.BoshUID(),
+			IndicatorName: "error_rate",
+			Status:        test_fixtures.StrPtr("HEALTHY"),
+		})
+
+		clock = fixedTime.Add(time.Minute)
+		store.UpdateStatus(status_store.UpdateRequest{
+			DocumentUID:   compositeDocument.BoshUID(),
+			IndicatorName: "latency",
+			Status:        test_fixtures.StrPtr("warning"),
+		})
+
+		document := compositeDocument
+		store.FillStatuses(&document)
+
+		g.Expect(document.Status).To(HaveKeyWithValue("any_warning", v1.IndicatorStatus{
+			Phase:     "warning",
+			UpdatedAt: metaV1.Time{Time: fixedTime.Add(time.Minute)},
+			Since:     metaV1.Time{Time: fixedTime.Add(time.Minute)},
 		}))
+		g.Expect(document.Status).ToNot(HaveKey("without_statuses"))
 	})
+
+	t.Run("records the transitions of composite indicators", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		clock := fixedTime
+		var changes []status_store.StatusChange
+		store := status_store.New(func() time.Time { return clock },
+			status_store.WithDocumentLookup(lookup),
+			status_store.WithTransitionHandler(func(c status_store.StatusChange) {
+				if c.IndicatorName == "any_warning" {
+					changes = append(changes, c)
+				}
+			}),
+		)
+		update := func(name string, status string) {
+			store.UpdateStatus(status_store.UpdateRequest{
+				DocumentUID:   compositeDocument.BoshUID(),
+				IndicatorName: name,
+				Status:        test_fixtures.StrPtr(status),
+			})
+		}
+
+		update("error_rate", "HEALTHY")
+		clock = fixedTime.Add(time.Minute)
+		update("latency", "HEALTHY")
+		clock = fixedTime.Add(2 * time.Minute)
+		update("latency", "critical")
+		clock = fixedTime.Add(3 * time.Minute)
+		update("error_rate", "warning")
+
+		g.Expect(changes).To(HaveLen(1))
+		g.Expect(*changes[0].OldStatus).To(Equal("HEALTHY"))
+		g.Expect(*changes[0].NewStatus).To(Equal("warning"))
+		g.Expect(changes[0].At).To(Equal(fixedTime.Add(2 * time.Minute)))
+
+		history, err := store.History(compositeDocument.BoshUID(), "any_warning", time.Time{}, time.Time{})
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(history).To(HaveLen(2))
+		g.Expect(history[1].Since).To(Equal(fixedTime.Add(2 * time.Minute)))
+
+		status, err := store.StatusFor(compositeDocument.BoshUID(), "any_warning")
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(status.Since).To(Equal(fixedTime.Add(2 * time.Minute)))
+	})
+}
+
+func TestStatusHistory(t *testing.T) {
+	start := time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	update := func(store *status_store.Store, status string) {
+		store.UpdateStatus(status_store.UpdateRequest{
+			DocumentUID:   "abc-123",
+			IndicatorName: "latency",
+			Status:        test_fixtures.StrPtr(status),
+		})
+	}
+
+	t.Run("it records the transitions of a status", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		clock := start
+		store := status_store.New(func() time.Time { return clock })
+
+		update(store, "healthy")
+		clock = start.Add(time.Minute)
+		update(store, "healthy")
+		clock = start.Add(2 * time.Minute)
+		update(store, "critical")
+		clock = start.Add(3 * time.Minute)
+		update(store, "critical")
+
+		status, err := store.StatusFor("abc-123", "latency")
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(status.UpdatedAt).To(Equal(start.Add(3 * time.Minute)))
+		g.Expect(status.Since).To(Equal(start.Add(2 * time.Minute)))
+
+		until := start.Add(2 * time.Minute)
+		g.Expect(store.History("abc-123", "latency", time.Time{}, time.Time{})).To(Equal([]status_store.StatusPeriod{{
+			Status: test_fixtures.StrPtr("healthy"),
+			Since:  start,
+			Until:  &until,
+		}, {
+			Status: test_fixtures.StrPtr("critical"),
+			Since:  start.Add(2 * time.Minute),
+		}}))
+	})
+
+	t.Run("it returns the periods overlapping a time range", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		clock := start
+		store := status_store.New(func() time.Time { return clock })
+		for i, status := range []string{"healthy", "warning", "critical", "healthy"} {
+			clock = start.Add(time.Duration(i) * time.Hour)
+			update(store, status)
+		}
+
+		history, err := store.History("abc-123", "latency", start.Add(90*time.Minute), start.Add(2*time.Hour))
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(history).To(HaveLen(2))
+		g.Expect(*history[0].Status).To(Equal("warning"))
+		g.Expect(*history[1].Status).To(Equal("critical"))
+
+		history, err = store.History("abc-123", "latency", start.Add(4*time.Hour), time.Time{})
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(history).To(HaveLen(1))
+		g.Expect(*history[0].Status).To(Equal("healthy"))
+		g.Expect(history[0].Until).To(BeNil())
+	})
+
+	t.Run("it keeps a bounded number of transitions", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		clock := start
+		store := status_store.New(func() time.Time { return clock }, status_store.WithHistoryRetention(2))
+		for i, status := range []string{"healthy", "warning", "critical"} {
+			clock = start.Add(time.Duration(i) * time.Hour)
+			update(store, status)
+		}
+
+		history, err := store.History("abc-123", "latency", time.Time{}, time.Time{})
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(history).To(HaveLen(2))
+		g.Expect(*history[0].Status).To(Equal("warning"))
+		g.Expect(history[0].Since).To(Equal(start.Add(time.Hour)))
+	})
+
+	t.Run("it restores the transitions from its storage", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		clock := start
+		s := storage.NewMemory()
+		store := status_store.New(func() time.Time { return clock }, status_store.WithStorage(s))
+		update(store, "healthy")
+		clock = start.Add(time.Hour)
+		update(store, "critical")
+
+		restored := status_store.New(func() time.Time { return clock }, status_store.WithStorage(s))
+
+		history, err := restored.History("abc-123", "latency", time.Time{}, time.Time{})
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(history).To(HaveLen(2))
+		g.Expect(history[0].Since.Equal(start)).To(BeTrue())
+	})
+
+	t.Run("it reports changes of a status to the transition handler", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		var changes []status_store.StatusChange
+		clock := start
+		store := status_store.New(func() time.Time { return clock }, status_store.WithTransitionHandler(func(c status_store.StatusChange) {
+			changes = append(changes, c)
+		}))
+
+		update(store, "healthy")
+		clock = start.Add(time.Minute)
+		update(store, "healthy")
+		clock = start.Add(2 * time.Minute)
+		store.UpdateStatus(status_store.UpdateRequest{
+			DocumentUID:   "abc-123",
+			IndicatorName: "latency",
+			Status:        test_fixtures.StrPtr("critical"),
+			Value:         test_fixtures.FloatPtr(250),
+		})
+
+		g.Expect(changes).To(Equal([]status_store.StatusChange{{
+			DocumentUID:   "abc-123",
+			IndicatorName: "latency",
+			OldStatus:     test_fixtures.StrPtr("healthy"),
+			NewStatus:     test_fixtures.StrPtr("critical"),
+			Value:         test_fixtures.FloatPtr(250),
+			At:            start.Add(2 * time.Minute),
+		}}))
+	})
+
+	t.Run("it returns an error for unknown indicators", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		store := status_store.New(time.Now)
+
+		_, err := store.History("abc-123", "latency", time.Time{}, time.Time{})
+		g.Expect(err).To(MatchError("indicator status for document abc-123 with name latency could not be found"))
+	})
+}
+
+type countingStorage struct {
+	storage.Storage
+	puts int
+}
+
+func (s *countingStorage) Put(key string, value []byte) error {
+	s.puts++
+	return s.Storage.Put(key, value)
 }
@@ -0,0 +1,148 @@
+package storage
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Storage persists the registry's documents and statuses as opaque values by key, so that they
+// survive restarts of the registry.
+type Storage interface {
+	Put(key string, value []byte) error
+	Delete(key string) error
+	// All returns the values of all keys starting with the prefix.
+	All(prefix string) (map[string][]byte, error)
+}
+
+// NewMemory returns a storage that only lives as long as the process does.
+func NewMemory() *Memory {
+	return &Memory{values: make(map[string][]byte)}
+}
+
+type Memory struct {
+	sync.Mutex
+	values map[string][]byte
+}
+
+func (m *Memory) Put(key string, value []byte) error {
+	m.Lock()
+	defer m.Unlock()
+
+	m.values[key] = append([]byte(nil), value...)
+	return nil
+}
+
+func (m *Memory) Delete(key string) error {
+	m.Lock()
+	defer m.Unlock()
+
+	delete(m.values, key)
+	return nil
+}
+
+func (m *Memory) All(prefix string) (map[string][]byte, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	values := make(map[string][]byte)
+	for key, value := range m.values {
+		if strings.HasPrefix(key, prefix) {
+			values[key] = append([]byte(nil), value...)
+		}
+	}
+	return values, nil
+}
+
+// NewDirectory returns a storage that keeps each key in its own file in the directory, creating the
+// directory if it does not exist yet. Values are written to a temporary file first and renamed, so a
+// crash never leaves a partially written value behind.
+func NewDirectory(dir string) (*Directory, error) {
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		return nil, fmt.Errorf("could not create storage directory: %s", err)
+	}
+	return &Directory{dir: dir}, nil
+}
+
+type Directory struct {
+	sync.Mutex
+	dir string
+}
+
+func (d *Directory) Put(key string, value []byte) error {
+	d.Lock()
+	defer d.Unlock()
+
+	tmp, err := ioutil.TempFile(d.dir, ".tmp-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(value)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	closeErr := tmp.Close()
+	if err != nil {
+		return err
+	}
+	if closeErr != nil {
+		return closeErr
+	}
+
+	return os.Rename(tmp.Name(), d.path(key))
+}
+
+func (d *Directory) Delete(key string) error {
+	d.Lock()
+	defer d.Unlock()
+
+	err := os.Remove(d.path(key))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+func (d *Directory) All(prefix string) (map[string][]byte, error) {
+	d.Lock()
+	defer d.Unlock()
+
+	files, err := ioutil.ReadDir(d.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string][]byte)
+	for _, f := range files {
+		if f.IsDir() || strings.HasPrefix(f.Name(), ".") {
+			continue
+		}
+		key, err := url.PathUnescape(f.Name())
+		if err != nil || !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		value, err := ioutil.ReadFile(filepath.Join(d.dir, f.Name()))
+		if err != nil {
+			return nil, err
+		}
+		values[key] = value
+	}
+	return values, nil
+}
+
+// Keys are escaped so that any key maps to a single file name. A leading dot is escaped as well, as
+// those names are reserved for temporary files.
+func (d *Directory) path(key string) string {
+	name := url.PathEscape(key)
+	if strings.HasPrefix(name, ".") {
+		name = "%2E" + name[1:]
+	}
+	return filepath.Join(d.dir, name)
+}
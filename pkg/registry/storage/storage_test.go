@@ -0,0 +1,89 @@
+package storage_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal/monitoring-indicator-protocol/pkg/registry/storage"
+)
+
+func TestStorage(t *testing.T) {
+	implementations := map[string]func(t *testing.T) storage.Storage{
+		"memory": func(t *testing.T) storage.Storage {
+			return storage.NewMemory()
+		},
+		"directory": func(t *testing.T) storage.Storage {
+			s, err := storage.NewDirectory(tempDir(t))
+			if err != nil {
+				t.Fatal(err)
+			}
+			return s
+		},
+	}
+
+	for name, newStorage := range implementations {
+		t.Run(name, func(t *testing.T) {
+			t.Run("it returns the values with a prefix", func(t *testing.T) {
+				g := NewGomegaWithT(t)
+				s := newStorage(t)
+
+				g.Expect(s.Put("documents/abc", []byte("a"))).To(Succeed())
+				g.Expect(s.Put("documents/def", []byte("b"))).To(Succeed())
+				g.Expect(s.Put("statuses/abc/latency", []byte("c"))).To(Succeed())
+
+				g.Expect(s.All("documents/")).To(Equal(map[string][]byte{
+					"documents/abc": []byte("a"),
+					"documents/def": []byte("b"),
+				}))
+				g.Expect(s.All("")).To(HaveLen(3))
+			})
+
+			t.Run("it overwrites and deletes values", func(t *testing.T) {
+				g := NewGomegaWithT(t)
+				s := newStorage(t)
+
+				g.Expect(s.Put("documents/abc", []byte("a"))).To(Succeed())
+				g.Expect(s.Put("documents/abc", []byte("b"))).To(Succeed())
+				g.Expect(s.All("documents/")).To(Equal(map[string][]byte{
+					"documents/abc": []byte("b"),
+				}))
+
+				g.Expect(s.Delete("documents/abc")).To(Succeed())
+				g.Expect(s.Delete("documents/unknown")).To(Succeed())
+				g.Expect(s.All("documents/")).To(BeEmpty())
+			})
+		})
+	}
+
+	t.Run("directory", func(t *testing.T) {
+		t.Run("it restores values written by an earlier storage", func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			dir := filepath.Join(tempDir(t), "not-created-yet")
+
+			s, err := storage.NewDirectory(dir)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(s.Put("documents/../abc", []byte("a"))).To(Succeed())
+			g.Expect(s.Put(".hidden", []byte("b"))).To(Succeed())
+
+			restored, err := storage.NewDirectory(dir)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(restored.All("")).To(Equal(map[string][]byte{
+				"documents/../abc": []byte("a"),
+				".hidden":          []byte("b"),
+			}))
+		})
+	})
+}
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "registry-storage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return dir
+}
@@ -1,36 +1,116 @@
 package registry
 
 import (
+	"encoding/json"
 	"log"
+	"reflect"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/pivotal/monitoring-indicator-protocol/pkg/indicator"
 	"github.com/pivotal/monitoring-indicator-protocol/pkg/k8s/apis/indicatordocument/v1"
+	"github.com/pivotal/monitoring-indicator-protocol/pkg/registry/status_store"
+	"github.com/pivotal/monitoring-indicator-protocol/pkg/registry/storage"
 )
 
 type clock func() time.Time
 
-func NewDocumentStore(timeout time.Duration, c clock) *DocumentStore {
-	return &DocumentStore{
+type DocumentStoreOpt func(*DocumentStore)
+
+// WithStorage persists the registered documents in the storage, and restores the unexpired ones from
+// it when the store is created. Patches are not persisted, as they are read from the configured
+// sources again on startup.
+func WithStorage(s storage.Storage) DocumentStoreOpt {
+	return func(d *DocumentStore) {
+		d.storage = s
+	}
+}
+
+// WithChangeHandler calls the handler after a document is added, updated, expired or deleted. Registering a
+// document again without changes does not update it. The handler is called synchronously, so it
+// should not block.
+func WithChangeHandler(handler func(DocumentChange)) DocumentStoreOpt {
+	return func(d *DocumentStore) {
+		d.changeHandlers = append(d.changeHandlers, handler)
+	}
+}
+
+// StatusCleanup is a change handler that deletes the statuses of documents from the status store once
+// the documents expire or are deleted.
+func StatusCleanup(statusStore *status_store.Store) func(DocumentChange) {
+	return func(change DocumentChange) {
+		if change.Type == DocumentExpired || change.Type == DocumentDeleted {
+			statusStore.DeleteStatuses(change.Document.BoshUID())
+		}
+	}
+}
+
+type DocumentChangeType string
+
+const (
+	DocumentAdded   DocumentChangeType = "added"
+	DocumentUpdated DocumentChangeType = "updated"
+	DocumentExpired DocumentChangeType = "expired"
+	DocumentDeleted DocumentChangeType = "deleted"
+)
+
+// DocumentChange is reported to the change handlers with the document as it is after the change, or
+// as it was before it expired or was deleted.
+type DocumentChange struct {
+	Type     DocumentChangeType
+	Document v1.IndicatorDocument
+}
+
+func NewDocumentStore(timeout time.Duration, c clock, opts ...DocumentStoreOpt) *DocumentStore {
+	d := &DocumentStore{
 		documents:       make([]registeredDocument, 0),
 		patchesBySource: make(map[string][]indicator.Patch),
 		timeout:         timeout,
 		getTime:         c,
+		storage:         storage.NewMemory(),
+	}
+	for _, opt := range opts {
+		opt(d)
 	}
+	d.restoreDocuments()
+
+	return d
 }
 
 type registeredDocument struct {
 	indicatorDocument v1.IndicatorDocument
+	patchReport       indicator.PatchReport
 	registeredAt      time.Time
+	// The last registered document of each earlier product version, from the oldest.
+	previousVersions []v1.IndicatorDocument
+	// The registration time of the document in the storage.
+	persistedAt time.Time
 }
 
+// storedDocument is how a registered document is kept in the storage.
+type storedDocument struct {
+	Document         v1.IndicatorDocument   `json:"document"`
+	PatchReport      indicator.PatchReport  `json:"patchReport"`
+	RegisteredAt     time.Time              `json:"registeredAt"`
+	PreviousVersions []v1.IndicatorDocument `json:"previousVersions,omitempty"`
+}
+
+const documentKeyPrefix = "documents/"
+
+// The number of earlier product versions kept for each document, so that they can be compared.
+const maxPreviousVersions = 5
+
 type DocumentStore struct {
 	sync.RWMutex
 	documents       []registeredDocument
 	patchesBySource map[string][]indicator.Patch
 	timeout         time.Duration
 	getTime         clock
+	storage         storage.Storage
+
+	changeHandlers []func(DocumentChange)
 }
 
 type PatchList struct {
@@ -39,6 +119,18 @@ type PatchList struct {
 }
 
 func (d *DocumentStore) UpsertDocument(doc v1.IndicatorDocument) {
+	d.UpsertPatchedDocument(doc, indicator.PatchReport{Patches: []indicator.PatchResult{}})
+}
+
+// UpsertPatchedDocument stores the document together with the report of the patches applied to it.
+func (d *DocumentStore) UpsertPatchedDocument(doc v1.IndicatorDocument, report indicator.PatchReport) {
+	change, changed := d.upsertPatchedDocument(doc, report)
+	if changed {
+		d.notify(change)
+	}
+}
+
+func (d *DocumentStore) upsertPatchedDocument(doc v1.IndicatorDocument, report indicator.PatchReport) (DocumentChange, bool) {
 	d.Lock()
 	defer d.Unlock()
 
@@ -46,14 +138,62 @@ func (d *DocumentStore) UpsertDocument(doc v1.IndicatorDocument) {
 
 	rd := registeredDocument{
 		indicatorDocument: doc,
+		patchReport:       report,
 		registeredAt:      d.getTime(),
 	}
 
+	change := DocumentChange{Type: DocumentAdded, Document: doc}
+	changed := true
+	persist := true
 	if pos == -1 {
+		pos = len(d.documents)
 		d.documents = append(d.documents, rd)
 	} else {
+		registered := d.documents[pos]
+		change.Type = DocumentUpdated
+		changed = !reflect.DeepEqual(registered.indicatorDocument, doc)
+		rd.previousVersions = withPreviousVersion(registered, doc.Spec.Product.Version)
+		rd.persistedAt = registered.persistedAt
+		persist = changed || !reflect.DeepEqual(registered.patchReport, report) || d.persistedLongAgo(rd)
 		d.documents[pos] = rd
 	}
+
+	if persist {
+		d.persistDocument(&d.documents[pos])
+	}
+	return change, changed
+}
+
+// Documents are registered again every interval, mostly unchanged. An unchanged document is only
+// persisted again once half of its timeout has passed since it was, so that a restarting registry
+// does not expire documents that are still being registered.
+func (d *DocumentStore) persistedLongAgo(doc registeredDocument) bool {
+	return doc.registeredAt.Sub(doc.persistedAt) >= d.timeout/2
+}
+
+func (d *DocumentStore) notify(change DocumentChange) {
+	for _, handler := range d.changeHandlers {
+		handler(change)
+	}
+}
+
+func withPreviousVersion(registered registeredDocument, newVersion string) []v1.IndicatorDocument {
+	previous := registered.indicatorDocument
+	if previous.Spec.Product.Version == newVersion {
+		return registered.previousVersions
+	}
+
+	versions := make([]v1.IndicatorDocument, 0, len(registered.previousVersions)+1)
+	for _, doc := range registered.previousVersions {
+		if doc.Spec.Product.Version != previous.Spec.Product.Version && doc.Spec.Product.Version != newVersion {
+			versions = append(versions, doc)
+		}
+	}
+	versions = append(versions, previous)
+	if len(versions) > maxPreviousVersions {
+		versions = versions[len(versions)-maxPreviousVersions:]
+	}
+	return versions
 }
 
 func (d *DocumentStore) UpsertPatches(patchList PatchList) {
@@ -66,7 +206,7 @@ func (d *DocumentStore) UpsertPatches(patchList PatchList) {
 }
 
 func (d *DocumentStore) AllDocuments() []v1.IndicatorDocument {
-	d.expireDocuments()
+	d.ExpireDocuments()
 
 	d.RLock()
 	defer d.RUnlock()
@@ -81,7 +221,7 @@ func (d *DocumentStore) AllDocuments() []v1.IndicatorDocument {
 }
 
 func (d *DocumentStore) FilteredDocuments(filterKeys map[string][]string) []v1.IndicatorDocument {
-	d.expireDocuments()
+	d.ExpireDocuments()
 
 	d.RLock()
 	defer d.RUnlock()
@@ -116,31 +256,213 @@ func filterDocument(documents []v1.IndicatorDocument, doc registeredDocument, fi
 	return append(documents, doc.indicatorDocument)
 }
 
+// Document returns the registered document with the given UID.
+func (d *DocumentStore) Document(uid string) (v1.IndicatorDocument, bool) {
+	d.ExpireDocuments()
+
+	d.RLock()
+	defer d.RUnlock()
+
+	for _, doc := range d.documents {
+		if doc.indicatorDocument.BoshUID() == uid {
+			return doc.indicatorDocument, true
+		}
+	}
+
+	return v1.IndicatorDocument{}, false
+}
+
+// DeleteDocument removes the document with the given UID, and reports whether it was registered.
+// Agents that keep registering the document add it again.
+func (d *DocumentStore) DeleteDocument(uid string) bool {
+	doc, ok := d.removeDocument(uid)
+	if ok {
+		d.notify(DocumentChange{Type: DocumentDeleted, Document: doc})
+	}
+	return ok
+}
+
+func (d *DocumentStore) removeDocument(uid string) (v1.IndicatorDocument, bool) {
+	d.Lock()
+	defer d.Unlock()
+
+	for i, doc := range d.documents {
+		if doc.indicatorDocument.BoshUID() == uid {
+			d.documents = append(d.documents[:i], d.documents[i+1:]...)
+			d.deleteDocument(uid)
+			return doc.indicatorDocument, true
+		}
+	}
+
+	return v1.IndicatorDocument{}, false
+}
+
+// PatchReport returns the report of the patches applied to the document with the given UID when it was
+// last registered.
+func (d *DocumentStore) PatchReport(uid string) (indicator.PatchReport, bool) {
+	d.ExpireDocuments()
+
+	d.RLock()
+	defer d.RUnlock()
+
+	for _, doc := range d.documents {
+		if doc.indicatorDocument.BoshUID() == uid {
+			return doc.patchReport, true
+		}
+	}
+
+	return indicator.PatchReport{}, false
+}
+
+// DocumentVersions returns the document with the given UID, followed by the documents of the earlier
+// product versions it was registered with, from the most recent.
+func (d *DocumentStore) DocumentVersions(uid string) ([]v1.IndicatorDocument, bool) {
+	d.ExpireDocuments()
+
+	d.RLock()
+	defer d.RUnlock()
+
+	for _, doc := range d.documents {
+		if doc.indicatorDocument.BoshUID() == uid {
+			versions := []v1.IndicatorDocument{doc.indicatorDocument}
+			for i := len(doc.previousVersions) - 1; i >= 0; i-- {
+				versions = append(versions, doc.previousVersions[i])
+			}
+			return versions, true
+		}
+	}
+
+	return nil, false
+}
+
+// AllPatches returns the patches of all sources in the order they are applied: by priority, then by
+// source and by their order within the source.
 func (d *DocumentStore) AllPatches() []indicator.Patch {
 	d.RLock()
 	defer d.RUnlock()
 
-	allPatches := make([]indicator.Patch, 0)
+	sources := make([]string, 0, len(d.patchesBySource))
+	for source := range d.patchesBySource {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
 
-	for _, patches := range d.patchesBySource {
-		allPatches = append(allPatches, patches...)
+	allPatches := make([]indicator.Patch, 0)
+	for _, source := range sources {
+		for _, p := range d.patchesBySource[source] {
+			p.Source = source
+			allPatches = append(allPatches, p)
+		}
 	}
+	indicator.SortPatches(allPatches)
 
 	return allPatches
 }
 
-func (d *DocumentStore) expireDocuments() {
+// ExpireDocuments removes the documents that have not been registered again within the timeout.
+// Reading documents expires them as well, so this only needs to be called for the change handlers to
+// learn of expiries while nothing is read.
+func (d *DocumentStore) ExpireDocuments() {
+	for _, doc := range d.removeExpiredDocuments() {
+		d.notify(DocumentChange{Type: DocumentExpired, Document: doc})
+	}
+}
+
+func (d *DocumentStore) removeExpiredDocuments() []v1.IndicatorDocument {
 	d.Lock()
 	defer d.Unlock()
 
 	var unexpiredDocuments []registeredDocument
+	var expiredDocuments []v1.IndicatorDocument
 	for _, doc := range d.documents {
-		if !doc.registeredAt.Add(d.timeout).Before(d.getTime()) {
+		if d.expired(doc) {
+			d.deleteDocument(doc.indicatorDocument.BoshUID())
+			expiredDocuments = append(expiredDocuments, doc.indicatorDocument)
+		} else {
 			unexpiredDocuments = append(unexpiredDocuments, doc)
 		}
 	}
 
 	d.documents = unexpiredDocuments
+	return expiredDocuments
+}
+
+func (d *DocumentStore) expired(doc registeredDocument) bool {
+	return doc.registeredAt.Add(d.timeout).Before(d.getTime())
+}
+
+func (d *DocumentStore) persistDocument(doc *registeredDocument) {
+	value, err := json.Marshal(storedDocument{
+		Document:         doc.indicatorDocument,
+		PatchReport:      doc.patchReport,
+		RegisteredAt:     doc.registeredAt,
+		PreviousVersions: doc.previousVersions,
+	})
+	if err == nil {
+		err = d.storage.Put(documentKeyPrefix+doc.indicatorDocument.BoshUID(), value)
+	}
+	if err != nil {
+		log.Printf("failed to persist document %s: %s", doc.indicatorDocument.BoshUID(), err)
+		return
+	}
+	doc.persistedAt = doc.registeredAt
+}
+
+func (d *DocumentStore) deleteDocument(uid string) {
+	err := d.storage.Delete(documentKeyPrefix + uid)
+	if err != nil {
+		log.Printf("failed to delete persisted document %s: %s", uid, err)
+	}
+}
+
+// restoreDocuments loads the documents persisted by an earlier registry, in the order they were
+// registered. Documents that have expired in the meantime are deleted instead, and reported to the
+// change handlers.
+func (d *DocumentStore) restoreDocuments() {
+	values, err := d.storage.All(documentKeyPrefix)
+	if err != nil {
+		log.Printf("failed to restore persisted documents: %s", err)
+		return
+	}
+
+	for key, value := range values {
+		uid := strings.TrimPrefix(key, documentKeyPrefix)
+
+		var stored storedDocument
+		err := json.Unmarshal(value, &stored)
+		if err != nil {
+			log.Printf("failed to restore persisted document %s: %s", uid, err)
+			continue
+		}
+		// Registered documents have their defaults populated already. This restores the empty
+		// values that are left out of the stored JSON.
+		v1.PopulateDefaults(&stored.Document)
+		for i := range stored.PreviousVersions {
+			v1.PopulateDefaults(&stored.PreviousVersions[i])
+		}
+
+		doc := registeredDocument{
+			indicatorDocument: stored.Document,
+			patchReport:       stored.PatchReport,
+			registeredAt:      stored.RegisteredAt,
+			previousVersions:  stored.PreviousVersions,
+			persistedAt:       stored.RegisteredAt,
+		}
+		if d.expired(doc) {
+			d.deleteDocument(uid)
+			d.notify(DocumentChange{Type: DocumentExpired, Document: doc.indicatorDocument})
+			continue
+		}
+		d.documents = append(d.documents, doc)
+	}
+
+	sort.SliceStable(d.documents, func(i, j int) bool {
+		return d.documents[i].registeredAt.Before(d.documents[j].registeredAt)
+	})
+
+	if len(d.documents) > 0 {
+		log.Printf("restored %d documents", len(d.documents))
+	}
 }
 
 func (d *DocumentStore) getPosition(indicatorDocument v1.IndicatorDocument) int {
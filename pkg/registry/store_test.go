@@ -1,6 +1,8 @@
 package registry_test
 
 import (
+	"io/ioutil"
+	"os"
 	"testing"
 	"time"
 
@@ -11,6 +13,8 @@ import (
 	"github.com/pivotal/monitoring-indicator-protocol/pkg/indicator"
 	"github.com/pivotal/monitoring-indicator-protocol/pkg/k8s/apis/indicatordocument/v1"
 	"github.com/pivotal/monitoring-indicator-protocol/pkg/registry"
+	"github.com/pivotal/monitoring-indicator-protocol/pkg/registry/status_store"
+	"github.com/pivotal/monitoring-indicator-protocol/pkg/registry/storage"
 )
 
 func TestStore(t *testing.T) {
@@ -137,19 +141,54 @@ func TestStore(t *testing.T) {
 			Source:  "git:other-repo",
 			Patches: []indicator.Patch{patchB, patchC},
 		})
-		g.Expect(store.AllPatches()).To(ConsistOf(patchB, patchC))
+		g.Expect(store.AllPatches()).To(Equal([]indicator.Patch{
+			fromSource(patchB, "git:other-repo"),
+			fromSource(patchC, "git:other-repo"),
+		}))
 
 		store.UpsertPatches(registry.PatchList{
 			Source:  "git:other-repo",
 			Patches: []indicator.Patch{patchB},
 		})
-		g.Expect(store.AllPatches()).To(ConsistOf(patchB))
+		g.Expect(store.AllPatches()).To(Equal([]indicator.Patch{fromSource(patchB, "git:other-repo")}))
 
 		store.UpsertPatches(registry.PatchList{
 			Source:  "git:repo",
 			Patches: []indicator.Patch{patchA},
 		})
-		g.Expect(store.AllPatches()).To(ConsistOf(patchB, patchA))
+		g.Expect(store.AllPatches()).To(Equal([]indicator.Patch{
+			fromSource(patchB, "git:other-repo"),
+			fromSource(patchA, "git:repo"),
+		}))
+	})
+
+	t.Run("it orders patches by priority, then by source and their order within the source", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		store := registry.NewDocumentStore(time.Hour, time.Now)
+
+		prioritizedA := patchA
+		prioritizedA.Priority = 10
+		deprioritizedC := patchC
+		deprioritizedC.Priority = -1
+
+		store.UpsertPatches(registry.PatchList{
+			Source:  "z-source",
+			Patches: []indicator.Patch{prioritizedA, patchB},
+		})
+		store.UpsertPatches(registry.PatchList{
+			Source:  "a-source",
+			Patches: []indicator.Patch{patchB, deprioritizedC},
+		})
+
+		for i := 0; i < 10; i++ {
+			g.Expect(store.AllPatches()).To(Equal([]indicator.Patch{
+				fromSource(deprioritizedC, "a-source"),
+				fromSource(patchB, "a-source"),
+				fromSource(patchB, "z-source"),
+				fromSource(prioritizedA, "z-source"),
+			}))
+		}
 	})
 
 	t.Run("it saves documents sent to it", func(t *testing.T) {
@@ -186,6 +225,26 @@ func TestStore(t *testing.T) {
 		g.Expect(store.AllDocuments()).To(ConsistOf(productAVersion1Document, productADeployment2Document))
 	})
 
+	t.Run("it keeps the earlier product versions of documents", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		store := registry.NewDocumentStore(time.Hour, time.Now)
+
+		store.UpsertDocument(productAVersion1Document)
+		store.UpsertDocument(productAVersion2Document)
+		store.UpsertDocument(productAVersion2Document)
+
+		versions, ok := store.DocumentVersions(productAVersion1Document.BoshUID())
+		g.Expect(ok).To(BeTrue())
+		g.Expect(versions).To(Equal([]v1.IndicatorDocument{productAVersion2Document, productAVersion1Document}))
+
+		store.UpsertDocument(productAVersion1Document)
+		versions, _ = store.DocumentVersions(productAVersion1Document.BoshUID())
+		g.Expect(versions).To(Equal([]v1.IndicatorDocument{productAVersion1Document, productAVersion2Document}))
+
+		_, ok = store.DocumentVersions("unknown")
+		g.Expect(ok).To(BeFalse())
+	})
+
 	t.Run("documents expire after an interval", func(t *testing.T) {
 		g := NewGomegaWithT(t)
 
@@ -197,6 +256,179 @@ func TestStore(t *testing.T) {
 
 		g.Expect(store.AllDocuments()).To(HaveLen(0))
 	})
+
+	t.Run("it reports the documents added, changed and expired", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		var changes []registry.DocumentChange
+		theTime := time.Now()
+		store := registry.NewDocumentStore(time.Hour, func() time.Time { return theTime },
+			registry.WithChangeHandler(func(change registry.DocumentChange) {
+				changes = append(changes, change)
+			}))
+
+		store.UpsertDocument(productAVersion1Document)
+		store.UpsertDocument(productAVersion1Document)
+		store.UpsertDocument(productAVersion2Document)
+		theTime = theTime.Add(time.Hour).Add(time.Millisecond)
+		store.ExpireDocuments()
+
+		g.Expect(changes).To(Equal([]registry.DocumentChange{
+			{Type: registry.DocumentAdded, Document: productAVersion1Document},
+			{Type: registry.DocumentUpdated, Document: productAVersion2Document},
+			{Type: registry.DocumentExpired, Document: productAVersion2Document},
+		}))
+	})
+
+	t.Run("it deletes documents", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		var changes []registry.DocumentChange
+		s := storage.NewMemory()
+		store := registry.NewDocumentStore(time.Hour, time.Now, registry.WithStorage(s),
+			registry.WithChangeHandler(func(change registry.DocumentChange) {
+				changes = append(changes, change)
+			}))
+
+		store.UpsertDocument(productAVersion1Document)
+		store.UpsertDocument(productBDocument)
+
+		g.Expect(store.DeleteDocument(productAVersion1Document.BoshUID())).To(BeTrue())
+		g.Expect(store.DeleteDocument(productAVersion1Document.BoshUID())).To(BeFalse())
+
+		g.Expect(store.AllDocuments()).To(ConsistOf(productBDocument))
+		g.Expect(changes).To(ContainElement(registry.DocumentChange{Type: registry.DocumentDeleted, Document: productAVersion1Document}))
+		stored, err := s.All("documents/")
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(stored).To(HaveLen(1))
+	})
+
+	t.Run("it restores the documents in its storage", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		document := productAVersion2Document
+		v1.PopulateDefaults(&document)
+		report := indicator.PatchReport{Patches: []indicator.PatchResult{{
+			Origin:  "git:github.com/cf/indicators",
+			Matched: true,
+		}}}
+
+		theTime := time.Now()
+		s := storage.NewMemory()
+		store := registry.NewDocumentStore(time.Hour, func() time.Time { return theTime }, registry.WithStorage(s))
+		store.UpsertDocument(productAVersion1Document)
+		store.UpsertPatchedDocument(document, report)
+		store.UpsertDocument(productBDocument)
+
+		restored := registry.NewDocumentStore(time.Hour, func() time.Time { return theTime }, registry.WithStorage(s))
+
+		g.Expect(restored.AllDocuments()).To(HaveLen(2))
+
+		versions, ok := restored.DocumentVersions(document.BoshUID())
+		g.Expect(ok).To(BeTrue())
+		g.Expect(versions).To(HaveLen(2))
+		g.Expect(versions[0]).To(Equal(document))
+
+		restoredReport, ok := restored.PatchReport(document.BoshUID())
+		g.Expect(ok).To(BeTrue())
+		g.Expect(restoredReport).To(Equal(report))
+	})
+
+	t.Run("it only persists documents registered again without changes once half their timeout passed", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		theTime := time.Now()
+		s := &countingStorage{Storage: storage.NewMemory()}
+		store := registry.NewDocumentStore(time.Hour, func() time.Time { return theTime }, registry.WithStorage(s))
+		store.UpsertDocument(productAVersion1Document)
+		theTime = theTime.Add(time.Minute)
+		store.UpsertDocument(productAVersion1Document)
+		g.Expect(s.puts).To(Equal(1))
+
+		store.UpsertDocument(productAVersion2Document)
+		g.Expect(s.puts).To(Equal(2))
+
+		theTime = theTime.Add(30 * time.Minute)
+		store.UpsertDocument(productAVersion2Document)
+		g.Expect(s.puts).To(Equal(3))
+
+		theTime = theTime.Add(45 * time.Minute)
+		restored := registry.NewDocumentStore(time.Hour, func() time.Time { return theTime }, registry.WithStorage(s))
+		g.Expect(restored.AllDocuments()).To(HaveLen(1))
+	})
+
+	t.Run("it deletes the statuses of documents that expire", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		dir, err := ioutil.TempDir("", "registry-storage")
+		g.Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(dir)
+		s, err := storage.NewDirectory(dir)
+		g.Expect(err).ToNot(HaveOccurred())
+
+		theTime := time.Now()
+		clock := func() time.Time { return theTime }
+		newStores := func() (*registry.DocumentStore, *status_store.Store) {
+			statusStore := status_store.New(clock, status_store.WithStorage(s))
+			store := registry.NewDocumentStore(time.Hour, clock,
+				registry.WithStorage(s),
+				registry.WithChangeHandler(registry.StatusCleanup(statusStore)),
+			)
+			return store, statusStore
+		}
+		updateStatus := func(statusStore *status_store.Store, doc v1.IndicatorDocument) {
+			statusStore.UpdateStatus(status_store.UpdateRequest{
+				DocumentUID:   doc.BoshUID(),
+				IndicatorName: doc.Spec.Indicators[0].Name,
+				Status:        strPtr("critical"),
+			})
+		}
+
+		store, statusStore := newStores()
+		store.UpsertDocument(productAVersion1Document)
+		updateStatus(statusStore, productAVersion1Document)
+		theTime = theTime.Add(30 * time.Minute)
+		store.UpsertDocument(productBDocument)
+		updateStatus(statusStore, productBDocument)
+
+		theTime = theTime.Add(45 * time.Minute)
+		store.ExpireDocuments()
+		_, err = statusStore.StatusFor(productAVersion1Document.BoshUID(), "test_errors")
+		g.Expect(err).To(HaveOccurred())
+
+		theTime = theTime.Add(time.Hour)
+		_, statusStore = newStores()
+		_, err = statusStore.StatusFor(productBDocument.BoshUID(), "test_latency")
+		g.Expect(err).To(HaveOccurred())
+
+		files, err := ioutil.ReadDir(dir)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(files).To(BeEmpty())
+	})
+
+	t.Run("it does not restore expired documents and removes them from its storage", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		document := productBDocument
+		v1.PopulateDefaults(&document)
+
+		theTime := time.Now()
+		s := storage.NewMemory()
+		store := registry.NewDocumentStore(time.Hour, func() time.Time { return theTime }, registry.WithStorage(s))
+		store.UpsertDocument(productAVersion1Document)
+		theTime = theTime.Add(30 * time.Minute)
+		store.UpsertDocument(document)
+
+		theTime = theTime.Add(45 * time.Minute)
+		restored := registry.NewDocumentStore(time.Hour, func() time.Time { return theTime }, registry.WithStorage(s))
+
+		g.Expect(restored.AllDocuments()).To(ConsistOf(document))
+		g.Expect(s.All("")).To(HaveLen(1))
+
+		theTime = theTime.Add(time.Hour)
+		g.Expect(restored.AllDocuments()).To(BeEmpty())
+		g.Expect(s.All("")).To(BeEmpty())
+	})
 }
 
 func TestFiltering(t *testing.T) {
@@ -349,6 +581,21 @@ func TestFiltering(t *testing.T) {
 	})
 }
 
+type countingStorage struct {
+	storage.Storage
+	puts int
+}
+
+func (s *countingStorage) Put(key string, value []byte) error {
+	s.puts++
+	return s.Storage.Put(key, value)
+}
+
 func strPtr(s string) *string {
 	return &s
 }
+
+func fromSource(p indicator.Patch, source string) indicator.Patch {
+	p.Source = source
+	return p
+}
@@ -0,0 +1,338 @@
+package registry
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/pivotal/monitoring-indicator-protocol/pkg/registry/status_store"
+)
+
+// ResourceVersionHeader carries the resource version a watch starts after.
+const ResourceVersionHeader = "X-Resource-Version"
+
+// ErrResourceVersionGone is returned when a watch cannot resume after a resource version, because the
+// registry no longer has the events that followed it. Watchers list the documents again, and watch
+// from the current version.
+var ErrResourceVersionGone = errors.New("resource version is too old or unknown to the registry")
+
+var watchers = prometheus.NewGauge(prometheus.GaugeOpts{
+	Subsystem: "registry",
+	Name:      "watchers",
+	Help:      "The number of clients watching the registry for changes.",
+})
+
+func init() {
+	prometheus.MustRegister(watchers)
+}
+
+type WatchEventType string
+
+const (
+	WatchAdded         WatchEventType = "added"
+	WatchUpdated       WatchEventType = "updated"
+	WatchExpired       WatchEventType = "expired"
+	WatchDeleted       WatchEventType = "deleted"
+	WatchStatusChanged WatchEventType = "status_changed"
+)
+
+// WatchEvent is a change of a registered document, or of the status of one of its indicators.
+// Documents are sent without their statuses, which change with their own events.
+type WatchEvent struct {
+	Type            WatchEventType           `json:"type"`
+	ResourceVersion string                   `json:"resourceVersion"`
+	DocumentUID     string                   `json:"documentUID"`
+	Document        *APIDocumentResponse     `json:"document,omitempty"`
+	Status          *APIStatusChangeResponse `json:"status,omitempty"`
+}
+
+type APIStatusChangeResponse struct {
+	Indicator string    `json:"indicator"`
+	OldStatus *string   `json:"oldStatus"`
+	NewStatus *string   `json:"newStatus"`
+	Value     *float64  `json:"value,omitempty"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type WatchHubOpt func(*WatchHub)
+
+// WithEventRetention sets the number of recent events kept for watchers resuming after a disconnect.
+func WithEventRetention(events int) WatchHubOpt {
+	return func(h *WatchHub) {
+		h.retention = events
+	}
+}
+
+// WithWatchTimeout sets how long a watch streams before the registry ends it, and how often a
+// heartbeat is sent on an idle stream.
+func WithWatchTimeout(timeout time.Duration, heartbeat time.Duration) WatchHubOpt {
+	return func(h *WatchHub) {
+		h.timeout = timeout
+		h.heartbeat = heartbeat
+	}
+}
+
+// WatchHub numbers the changes to the registry with increasing resource versions and sends them to
+// the watchers. Resource versions start after startVersion, which should increase with each start of
+// the registry so that watchers cannot resume after the versions of an earlier one.
+type WatchHub struct {
+	sync.Mutex
+	version uint64
+	// The most recent events, from the oldest.
+	events      []WatchEvent
+	retention   int
+	subscribers map[*Subscription]struct{}
+
+	timeout   time.Duration
+	heartbeat time.Duration
+}
+
+func NewWatchHub(startVersion uint64, opts ...WatchHubOpt) *WatchHub {
+	h := &WatchHub{
+		version:     startVersion,
+		retention:   1000,
+		subscribers: make(map[*Subscription]struct{}),
+		timeout:     5 * time.Minute,
+		heartbeat:   15 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
+}
+
+// The number of events buffered for each watcher. Watchers that fall further behind are
+// disconnected, and resume from the last event they received.
+const subscriptionBufferSize = 100
+
+// Subscription receives the events after its resource version until it is closed.
+type Subscription struct {
+	resourceVersion string
+	events          chan WatchEvent
+}
+
+func (s *Subscription) Events() <-chan WatchEvent {
+	return s.events
+}
+
+// ResourceVersion is the version the subscription receives the events after.
+func (s *Subscription) ResourceVersion() string {
+	return s.resourceVersion
+}
+
+// Subscribe returns a subscription to the events after the resource version, or after the current
+// version if it is empty. It returns ErrResourceVersionGone if the events after the resource version
+// are no longer retained.
+func (h *WatchHub) Subscribe(resourceVersion string) (*Subscription, error) {
+	h.Lock()
+	defer h.Unlock()
+
+	since := h.version
+	if resourceVersion != "" {
+		v, err := strconv.ParseUint(resourceVersion, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid resource version %q", resourceVersion)
+		}
+		oldest := h.version - uint64(len(h.events))
+		if v < oldest || v > h.version {
+			return nil, ErrResourceVersionGone
+		}
+		since = v
+	}
+
+	missed := h.events[len(h.events)-int(h.version-since):]
+	s := &Subscription{
+		resourceVersion: strconv.FormatUint(since, 10),
+		events:          make(chan WatchEvent, len(missed)+subscriptionBufferSize),
+	}
+	for _, e := range missed {
+		s.events <- e
+	}
+	h.subscribers[s] = struct{}{}
+	watchers.Inc()
+
+	return s, nil
+}
+
+// Unsubscribe closes the subscription.
+func (h *WatchHub) Unsubscribe(s *Subscription) {
+	h.Lock()
+	defer h.Unlock()
+
+	h.unsubscribe(s)
+}
+
+func (h *WatchHub) unsubscribe(s *Subscription) {
+	if _, ok := h.subscribers[s]; !ok {
+		return
+	}
+	delete(h.subscribers, s)
+	close(s.events)
+	watchers.Dec()
+}
+
+// DocumentChanged publishes a document change. It can be used as the change handler of the document
+// store.
+func (h *WatchHub) DocumentChanged(change DocumentChange) {
+	event := WatchEvent{DocumentUID: change.Document.BoshUID()}
+	switch change.Type {
+	case DocumentAdded:
+		event.Type = WatchAdded
+	case DocumentUpdated:
+		event.Type = WatchUpdated
+	case DocumentExpired:
+		event.Type = WatchExpired
+	case DocumentDeleted:
+		event.Type = WatchDeleted
+	}
+	if change.Type == DocumentAdded || change.Type == DocumentUpdated {
+		doc := ToAPIDocumentResponse(change.Document)
+		event.Document = &doc
+	}
+
+	h.publish(event)
+}
+
+// StatusChanged publishes a status transition. It can be used as the transition handler of the status
+// store.
+func (h *WatchHub) StatusChanged(change status_store.StatusChange) {
+	h.publish(WatchEvent{
+		Type:        WatchStatusChanged,
+		DocumentUID: change.DocumentUID,
+		Status: &APIStatusChangeResponse{
+			Indicator: change.IndicatorName,
+			OldStatus: change.OldStatus,
+			NewStatus: change.NewStatus,
+			Value:     change.Value,
+			UpdatedAt: change.At,
+		},
+	})
+}
+
+func (h *WatchHub) publish(event WatchEvent) {
+	h.Lock()
+	defer h.Unlock()
+
+	h.version++
+	event.ResourceVersion = strconv.FormatUint(h.version, 10)
+
+	h.events = append(h.events, event)
+	if len(h.events) > h.retention {
+		h.events = append([]WatchEvent(nil), h.events[len(h.events)-h.retention:]...)
+	}
+
+	for s := range h.subscribers {
+		select {
+		case s.events <- event:
+		default:
+			log.Printf("watcher fell behind at resource version %s, closing its watch", event.ResourceVersion)
+			h.unsubscribe(s)
+		}
+	}
+}
+
+// NewWatchHandler streams the events of the hub as Server-Sent Events. Watches resume after the
+// `resourceVersion` query parameter or the Last-Event-ID header, and otherwise start from the current
+// version. A watch that can no longer resume is answered with 410 Gone.
+func NewWatchHandler(hub *WatchHub) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			writeErrors(w, http.StatusInternalServerError, errors.New("streaming is not supported"))
+			return
+		}
+
+		resourceVersion := r.URL.Query().Get("resourceVersion")
+		if resourceVersion == "" {
+			resourceVersion = r.Header.Get("Last-Event-ID")
+		}
+
+		timeout := hub.timeout
+		if value := r.URL.Query().Get("timeoutSeconds"); value != "" {
+			seconds, err := strconv.Atoi(value)
+			if err != nil || seconds <= 0 {
+				writeErrors(w, http.StatusBadRequest, fmt.Errorf("invalid timeoutSeconds: %s", value))
+				return
+			}
+			if t := time.Duration(seconds) * time.Second; t < timeout {
+				timeout = t
+			}
+		}
+
+		subscription, err := hub.Subscribe(resourceVersion)
+		if err == ErrResourceVersionGone {
+			writeErrors(w, http.StatusGone, err)
+			return
+		}
+		if err != nil {
+			writeErrors(w, http.StatusBadRequest, err)
+			return
+		}
+		defer hub.Unsubscribe(subscription)
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set(ResourceVersionHeader, subscription.ResourceVersion())
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		end := time.NewTimer(timeout)
+		defer end.Stop()
+		heartbeat := time.NewTicker(hub.heartbeat)
+		defer heartbeat.Stop()
+
+		for {
+			var err error
+			select {
+			case <-r.Context().Done():
+				return
+			case <-end.C:
+				return
+			case <-heartbeat.C:
+				_, err = fmt.Fprint(w, ": heartbeat\n\n")
+			case event, ok := <-subscription.Events():
+				if !ok {
+					return
+				}
+				err = writeEvent(w, event)
+			}
+			if err != nil {
+				log.Printf("failed to write watch event: %s", err)
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func writeEvent(w http.ResponseWriter, event WatchEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", event.ResourceVersion, event.Type, data)
+	return err
+}
+
+// WriteTimeoutHandler limits the time taken to respond to each request, except to watches, which
+// stream until they time out on their own. Servers of the registry API use it in place of a
+// WriteTimeout, which would end the watches early.
+func WriteTimeoutHandler(h http.Handler, timeout time.Duration) http.Handler {
+	timeoutHandler := http.TimeoutHandler(h, timeout, "")
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(strings.TrimSuffix(r.URL.Path, "/"), "/v1/watch") {
+			h.ServeHTTP(w, r)
+			return
+		}
+		timeoutHandler.ServeHTTP(w, r)
+	})
+}
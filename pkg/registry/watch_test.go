@@ -0,0 +1,171 @@
+package registry_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal/monitoring-indicator-protocol/pkg/go_test"
+	v1 "github.com/pivotal/monitoring-indicator-protocol/pkg/k8s/apis/indicatordocument/v1"
+	"github.com/pivotal/monitoring-indicator-protocol/pkg/registry"
+	"github.com/pivotal/monitoring-indicator-protocol/pkg/registry/status_store"
+	"github.com/pivotal/monitoring-indicator-protocol/test_fixtures"
+)
+
+func TestWatch(t *testing.T) {
+	doc := v1.IndicatorDocument{
+		Spec: v1.IndicatorDocumentSpec{
+			Product: v1.Product{Name: "uaa", Version: "1.0.0"},
+			Indicators: []v1.IndicatorSpec{{
+				Name:   "latency",
+				PromQL: "rate(latency[5m])",
+			}},
+		},
+	}
+
+	t.Run("it streams the changes to documents and statuses", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		hub := registry.NewWatchHub(100)
+		store := registry.NewDocumentStore(time.Hour, time.Now, registry.WithChangeHandler(hub.DocumentChanged))
+		statusStore := status_store.New(time.Now, status_store.WithTransitionHandler(hub.StatusChanged))
+
+		start, stop := registry.NewWebServer(registry.WebServerConfig{
+			Address:       "localhost:34556",
+			DocumentStore: store,
+			StatusStore:   statusStore,
+			WatchHub:      hub,
+		})
+		go start()
+		defer stop()
+		g.Expect(go_test.WaitForTCPServer("localhost:34556", time.Second)).To(Succeed())
+
+		client := registry.NewAPIClient("http://localhost:34556", &http.Client{Timeout: time.Second})
+		stream, err := client.Watch("")
+		g.Expect(err).ToNot(HaveOccurred())
+		defer stream.Close()
+		g.Expect(stream.ResourceVersion()).To(Equal("100"))
+
+		store.UpsertDocument(doc)
+		statusStore.UpdateStatus(status_store.UpdateRequest{
+			DocumentUID:   doc.BoshUID(),
+			IndicatorName: "latency",
+			Status:        test_fixtures.StrPtr("healthy"),
+		})
+		statusStore.UpdateStatus(status_store.UpdateRequest{
+			DocumentUID:   doc.BoshUID(),
+			IndicatorName: "latency",
+			Status:        test_fixtures.StrPtr("critical"),
+			Value:         test_fixtures.FloatPtr(250),
+		})
+
+		event, err := stream.Next()
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(event.Type).To(Equal(registry.WatchAdded))
+		g.Expect(event.ResourceVersion).To(Equal("101"))
+		g.Expect(event.DocumentUID).To(Equal(doc.BoshUID()))
+		g.Expect(event.Document.Spec.Product.Name).To(Equal("uaa"))
+		g.Expect(stream.ResourceVersion()).To(Equal("101"))
+
+		event, err = stream.Next()
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(event.Type).To(Equal(registry.WatchStatusChanged))
+		g.Expect(event.ResourceVersion).To(Equal("102"))
+		g.Expect(event.DocumentUID).To(Equal(doc.BoshUID()))
+		g.Expect(event.Document).To(BeNil())
+		g.Expect(event.Status.Indicator).To(Equal("latency"))
+		g.Expect(event.Status.OldStatus).To(Equal(test_fixtures.StrPtr("healthy")))
+		g.Expect(event.Status.NewStatus).To(Equal(test_fixtures.StrPtr("critical")))
+		g.Expect(event.Status.Value).To(Equal(test_fixtures.FloatPtr(250)))
+	})
+
+	t.Run("it resumes watches after a resource version", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		hub := registry.NewWatchHub(100)
+		server := httptest.NewServer(registry.NewWatchHandler(hub))
+		defer server.Close()
+
+		for i := 0; i < 3; i++ {
+			hub.DocumentChanged(registry.DocumentChange{Type: registry.DocumentUpdated, Document: doc})
+		}
+
+		client := registry.NewAPIClient(server.URL, http.DefaultClient)
+		stream, err := client.Watch("101")
+		g.Expect(err).ToNot(HaveOccurred())
+		defer stream.Close()
+
+		event, err := stream.Next()
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(event.ResourceVersion).To(Equal("102"))
+		event, err = stream.Next()
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(event.ResourceVersion).To(Equal("103"))
+	})
+
+	t.Run("it does not resume after events it no longer has", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		hub := registry.NewWatchHub(100, registry.WithEventRetention(2))
+		server := httptest.NewServer(registry.NewWatchHandler(hub))
+		defer server.Close()
+
+		for i := 0; i < 3; i++ {
+			hub.DocumentChanged(registry.DocumentChange{Type: registry.DocumentUpdated, Document: doc})
+		}
+
+		client := registry.NewAPIClient(server.URL, http.DefaultClient)
+		_, err := client.Watch("100")
+		g.Expect(err).To(Equal(registry.ErrResourceVersionGone))
+		_, err = client.Watch("104")
+		g.Expect(err).To(Equal(registry.ErrResourceVersionGone))
+		_, err = client.Watch("not-a-version")
+		g.Expect(err).To(MatchError("received non-successful response from registry: 400"))
+
+		stream, err := client.Watch("101")
+		g.Expect(err).ToNot(HaveOccurred())
+		stream.Close()
+	})
+
+	t.Run("it ends watches after their timeout", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		hub := registry.NewWatchHub(100, registry.WithWatchTimeout(100*time.Millisecond, 10*time.Millisecond))
+		server := httptest.NewServer(registry.NewWatchHandler(hub))
+		defer server.Close()
+
+		client := registry.NewAPIClient(server.URL, http.DefaultClient)
+		stream, err := client.Watch("")
+		g.Expect(err).ToNot(HaveOccurred())
+		defer stream.Close()
+
+		_, err = stream.Next()
+		g.Expect(err).To(Equal(io.EOF))
+	})
+
+	t.Run("it closes the subscriptions of watchers that fall behind", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		hub := registry.NewWatchHub(100)
+		subscription, err := hub.Subscribe("")
+		g.Expect(err).ToNot(HaveOccurred())
+
+		for i := 0; i < 150; i++ {
+			hub.DocumentChanged(registry.DocumentChange{Type: registry.DocumentUpdated, Document: doc})
+		}
+
+		var received []registry.WatchEvent
+		for event := range subscription.Events() {
+			received = append(received, event)
+		}
+		g.Expect(received).To(HaveLen(100))
+		g.Expect(received[99].ResourceVersion).To(Equal("200"))
+
+		_, err = hub.Subscribe("200")
+		g.Expect(err).ToNot(HaveOccurred())
+	})
+}
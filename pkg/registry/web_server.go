@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/pivotal/monitoring-indicator-protocol/pkg/indicator"
 	"github.com/pivotal/monitoring-indicator-protocol/pkg/registry/status_store"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -17,14 +18,19 @@ type WebServerConfig struct {
 	Address       string
 	DocumentStore *DocumentStore
 	StatusStore   *status_store.Store
+	// ImportResolver resolves the imports of registered documents. Documents with imports are
+	// rejected when it is nil.
+	ImportResolver indicator.ImportResolver
+	// WatchHub streams the changes to the documents and statuses from /v1/watch. The endpoint is not
+	// served when it is nil.
+	WatchHub *WatchHub
 }
 
 func NewWebServer(c WebServerConfig) (func() error, func() error) {
 	server := &http.Server{
-		Addr:         c.Address,
-		Handler:      newRouter(c),
-		ReadTimeout:  5 * time.Second,
-		WriteTimeout: 10 * time.Second,
+		Addr:        c.Address,
+		Handler:     WriteTimeoutHandler(newRouter(c), 10*time.Second),
+		ReadTimeout: 5 * time.Second,
 	}
 
 	start := func() error { return server.ListenAndServe() }
@@ -48,14 +54,35 @@ func newRouter(w WebServerConfig) *mux.Router {
 	r.Handle("/metrics", instrumentEndpoint(httpRequests, promhttp.Handler()))
 	r.NotFoundHandler = notFound(httpRequests)
 
+	var readOpts []indicator.ReadOpt
+	if w.ImportResolver != nil {
+		readOpts = append(readOpts, indicator.ResolveImports(w.ImportResolver, ""))
+	}
+
 	// Optional trailing slash: https://github.com/gorilla/mux/issues/30#issuecomment-321045004
 	optionalTrailingSlash := "{_:(?:\\/)?}"
 	r.HandleFunc("/v1/register" + optionalTrailingSlash,
-		instrumentEndpoint(httpRequests, NewRegisterHandler(w.DocumentStore))).Methods(http.MethodPost)
+		instrumentEndpoint(httpRequests, NewRegisterHandler(w.DocumentStore, readOpts...))).Methods(http.MethodPost)
 	r.HandleFunc("/v1/indicator-documents" + optionalTrailingSlash,
 		instrumentEndpoint(httpRequests, NewIndicatorDocumentsHandler(w.DocumentStore, w.StatusStore))).Methods(http.MethodGet)
+	r.HandleFunc("/v1/deregister" + optionalTrailingSlash,
+		instrumentEndpoint(httpRequests, NewDeregisterHandler(w.DocumentStore, w.StatusStore, readOpts...))).Methods(http.MethodPost)
+	r.HandleFunc("/v1/indicator-documents/{documentID}" + optionalTrailingSlash,
+		instrumentEndpoint(httpRequests, NewIndicatorDocumentHandler(w.DocumentStore, w.StatusStore))).Methods(http.MethodGet)
+	r.HandleFunc("/v1/indicator-documents/{documentID}" + optionalTrailingSlash,
+		instrumentEndpoint(httpRequests, NewDeleteIndicatorDocumentHandler(w.DocumentStore, w.StatusStore))).Methods(http.MethodDelete)
 	r.HandleFunc("/v1/indicator-documents/{documentID}/bulk_status" + optionalTrailingSlash,
 		instrumentEndpoint(httpRequests, NewIndicatorStatusBulkUpdateHandler(w.StatusStore))).Methods(http.MethodPost)
+	r.HandleFunc("/v1/indicator-documents/{documentID}/patches" + optionalTrailingSlash,
+		instrumentEndpoint(httpRequests, NewPatchReportHandler(w.DocumentStore))).Methods(http.MethodGet)
+	r.HandleFunc("/v1/indicator-documents/{documentID}/diff" + optionalTrailingSlash,
+		instrumentEndpoint(httpRequests, NewDocumentDiffHandler(w.DocumentStore))).Methods(http.MethodGet)
+	r.HandleFunc("/v1/indicator-documents/{documentID}/indicators/{indicatorName}/history" + optionalTrailingSlash,
+		instrumentEndpoint(httpRequests, NewStatusHistoryHandler(w.StatusStore))).Methods(http.MethodGet)
+	if w.WatchHub != nil {
+		r.HandleFunc("/v1/watch" + optionalTrailingSlash,
+			instrumentEndpoint(httpRequests, NewWatchHandler(w.WatchHub))).Methods(http.MethodGet)
+	}
 	return r
 }
 
@@ -79,6 +106,12 @@ func (sr *statusRecorder) WriteHeader(statusCode int) {
 	sr.ResponseWriter.WriteHeader(statusCode)
 }
 
+func (sr *statusRecorder) Flush() {
+	if flusher, ok := sr.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
 func instrumentEndpoint(counter *prometheus.CounterVec, h http.Handler) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		rec := statusRecorder{ResponseWriter: w, status: 200}
@@ -86,9 +119,21 @@ func instrumentEndpoint(counter *prometheus.CounterVec, h http.Handler) http.Han
 		h.ServeHTTP(&rec, r)
 
 		urlLabel := r.URL.Path
+		if documentID, ok := mux.Vars(r)["documentID"]; ok && strings.TrimSuffix(r.URL.Path, "/") == "/v1/indicator-documents/"+documentID {
+			urlLabel = "/v1/indicator-documents/document"
+		}
 		if strings.Contains(r.URL.Path, "bulk_status") {
 			urlLabel = "/v1/indicator-documents/bulk_status"
 		}
+		if strings.HasSuffix(strings.TrimSuffix(r.URL.Path, "/"), "/patches") {
+			urlLabel = "/v1/indicator-documents/patches"
+		}
+		if strings.HasSuffix(strings.TrimSuffix(r.URL.Path, "/"), "/diff") {
+			urlLabel = "/v1/indicator-documents/diff"
+		}
+		if strings.HasSuffix(strings.TrimSuffix(r.URL.Path, "/"), "/history") {
+			urlLabel = "/v1/indicator-documents/indicators/history"
+		}
 
 		counter.WithLabelValues(urlLabel, strconv.Itoa(rec.status)).Inc()
 	}
@@ -77,6 +77,38 @@ func TestRegisterAndServeDocuments(t *testing.T) {
 	g.Expect(body).To(MatchJSON(expectedJSON))
 }
 
+func TestGetDeleteAndDeregisterDocuments(t *testing.T) {
+	g := NewGomegaWithT(t)
+	addr, stop := newWebServer(32898)
+	defer stop()
+
+	document, err := ioutil.ReadFile("test_fixtures/doc.yml")
+	g.Expect(err).ToNot(HaveOccurred())
+
+	client := registry.NewAPIClient("http://"+addr, http.DefaultClient)
+	g.Eventually(func() error { return client.AddIndicatorDocument(document) }).Should(Succeed())
+
+	documents, err := client.IndicatorDocuments()
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(documents).To(HaveLen(1))
+	uid := registry.ToIndicatorDocument(documents[0]).BoshUID()
+
+	doc, err := client.IndicatorDocument(uid)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(doc).To(Equal(documents[0]))
+
+	g.Expect(client.DeleteIndicatorDocument(uid)).To(Succeed())
+	_, err = client.IndicatorDocument(uid)
+	g.Expect(err).To(MatchError("received non-successful response from registry: 404"))
+	g.Expect(client.DeleteIndicatorDocument(uid)).To(MatchError("received non-successful response from registry: 404"))
+
+	g.Expect(client.AddIndicatorDocument(document)).To(Succeed())
+	g.Expect(client.DeregisterIndicatorDocument(document)).To(Succeed())
+	documents, err = client.IndicatorDocuments()
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(documents).To(BeEmpty())
+}
+
 func TestWritingAndReadingStatus(t *testing.T) {
 	g := NewGomegaWithT(t)
 
@@ -0,0 +1,247 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	v1 "github.com/pivotal/monitoring-indicator-protocol/pkg/k8s/apis/indicatordocument/v1"
+	"github.com/pivotal/monitoring-indicator-protocol/pkg/registry/status_store"
+)
+
+var (
+	deliveries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: "registry",
+		Name:      "webhook_deliveries",
+		Help:      "The count of status transitions sent to webhooks, by result: delivered, failed after all retries, or dropped because the queue was full.",
+	}, []string{"webhook", "result"})
+
+	failedAttempts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: "registry",
+		Name:      "webhook_failed_attempts",
+		Help:      "The count of failed attempts to deliver status transitions to webhooks, including those retried.",
+	}, []string{"webhook"})
+
+	queueLength = prometheus.NewGauge(prometheus.GaugeOpts{
+		Subsystem: "registry",
+		Name:      "webhook_queue_length",
+		Help:      "The number of webhook deliveries waiting to be sent.",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(deliveries, failedAttempts, queueLength)
+}
+
+// DocumentLookup returns the registered document with the given UID.
+type DocumentLookup func(uid string) (v1.IndicatorDocument, bool)
+
+type DispatcherOpt func(*Dispatcher)
+
+// WithQueueSize bounds the number of deliveries waiting to be sent. Transitions are dropped while the
+// queue is full.
+func WithQueueSize(size int) DispatcherOpt {
+	return func(d *Dispatcher) {
+		d.queueSize = size
+	}
+}
+
+// WithRetries sets how often a delivery is attempted, and the delay before the first retry. The delay
+// doubles with every retry, up to maxBackoff.
+func WithRetries(attempts int, backoff time.Duration, maxBackoff time.Duration) DispatcherOpt {
+	return func(d *Dispatcher) {
+		d.attempts = attempts
+		d.backoff = backoff
+		d.maxBackoff = maxBackoff
+	}
+}
+
+func WithWorkers(workers int) DispatcherOpt {
+	return func(d *Dispatcher) {
+		d.workers = workers
+	}
+}
+
+func WithHTTPClient(client *http.Client) DispatcherOpt {
+	return func(d *Dispatcher) {
+		d.client = client
+	}
+}
+
+// Dispatcher POSTs the status transitions of indicators to the webhooks matching their documents.
+type Dispatcher struct {
+	webhooks []Webhook
+	lookup   DocumentLookup
+	client   *http.Client
+
+	queueSize  int
+	workers    int
+	attempts   int
+	backoff    time.Duration
+	maxBackoff time.Duration
+
+	queue chan delivery
+	stop  chan struct{}
+	wg    sync.WaitGroup
+}
+
+type delivery struct {
+	webhook Webhook
+	body    []byte
+}
+
+func NewDispatcher(webhooks []Webhook, lookup DocumentLookup, opts ...DispatcherOpt) *Dispatcher {
+	d := &Dispatcher{
+		webhooks:   webhooks,
+		lookup:     lookup,
+		client:     &http.Client{Timeout: 10 * time.Second},
+		queueSize:  1000,
+		workers:    4,
+		attempts:   5,
+		backoff:    time.Second,
+		maxBackoff: time.Minute,
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	d.queue = make(chan delivery, d.queueSize)
+	d.stop = make(chan struct{})
+
+	return d
+}
+
+// Start sends the queued deliveries in the background until Stop is called.
+func (d *Dispatcher) Start() {
+	for i := 0; i < d.workers; i++ {
+		d.wg.Add(1)
+		go d.work()
+	}
+}
+
+// Stop waits for the deliveries in flight. Queued deliveries are not sent.
+func (d *Dispatcher) Stop() {
+	close(d.stop)
+	d.wg.Wait()
+}
+
+// Notify queues a delivery of the status change to each webhook matching its document, without
+// blocking. It can be used as the transition handler of the status store.
+func (d *Dispatcher) Notify(change status_store.StatusChange) {
+	if len(d.webhooks) == 0 {
+		return
+	}
+
+	doc, ok := d.lookup(change.DocumentUID)
+	if !ok {
+		log.Printf("no webhooks notified of the status of %s, document %s is not registered", change.IndicatorName, change.DocumentUID)
+		return
+	}
+
+	body, err := json.Marshal(Payload{
+		DocumentUID: change.DocumentUID,
+		Product: Product{
+			Name:    doc.Spec.Product.Name,
+			Version: doc.Spec.Product.Version,
+		},
+		Labels:    doc.Labels,
+		Indicator: change.IndicatorName,
+		OldStatus: change.OldStatus,
+		NewStatus: change.NewStatus,
+		Value:     change.Value,
+		Timestamp: change.At,
+	})
+	if err != nil {
+		log.Printf("failed to encode webhook payload: %s", err)
+		return
+	}
+
+	for _, w := range d.webhooks {
+		if !w.Match.Matches(doc) {
+			continue
+		}
+
+		select {
+		case d.queue <- delivery{webhook: w, body: body}:
+			queueLength.Inc()
+		default:
+			deliveries.WithLabelValues(w.Name, "dropped").Inc()
+			log.Printf("webhook queue is full, dropped status transition for webhook %s", w.Name)
+		}
+	}
+}
+
+func (d *Dispatcher) work() {
+	defer d.wg.Done()
+
+	for {
+		select {
+		case <-d.stop:
+			return
+		case next := <-d.queue:
+			queueLength.Dec()
+			d.deliver(next)
+		}
+	}
+}
+
+func (d *Dispatcher) deliver(next delivery) {
+	backoff := d.backoff
+	for attempt := 1; ; attempt++ {
+		retry, err := d.send(next)
+		if err == nil {
+			deliveries.WithLabelValues(next.webhook.Name, "delivered").Inc()
+			return
+		}
+
+		failedAttempts.WithLabelValues(next.webhook.Name).Inc()
+		if !retry || attempt >= d.attempts {
+			deliveries.WithLabelValues(next.webhook.Name, "failed").Inc()
+			log.Printf("failed to deliver status transition to webhook %s after %d attempts: %s", next.webhook.Name, attempt, err)
+			return
+		}
+
+		select {
+		case <-d.stop:
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > d.maxBackoff {
+			backoff = d.maxBackoff
+		}
+	}
+}
+
+// send makes a single attempt at a delivery. Client errors other than 429 are not retried, as
+// retrying would not change the response.
+func (d *Dispatcher) send(next delivery) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, next.webhook.URL, bytes.NewReader(next.body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, statusTransitionEvent)
+	req.Header.Set(SignatureHeader, Sign(next.webhook.Secret, next.body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+
+	retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+	return retry, fmt.Errorf("webhook responded with %d", resp.StatusCode)
+}
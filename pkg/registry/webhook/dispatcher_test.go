@@ -0,0 +1,255 @@
+package webhook_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "github.com/pivotal/monitoring-indicator-protocol/pkg/k8s/apis/indicatordocument/v1"
+	"github.com/pivotal/monitoring-indicator-protocol/pkg/registry/status_store"
+	"github.com/pivotal/monitoring-indicator-protocol/pkg/registry/webhook"
+	"github.com/pivotal/monitoring-indicator-protocol/test_fixtures"
+)
+
+func TestDispatcher(t *testing.T) {
+	doc := v1.IndicatorDocument{
+		ObjectMeta: metaV1.ObjectMeta{
+			Labels: map[string]string{"deployment": "cf"},
+		},
+		Spec: v1.IndicatorDocumentSpec{
+			Product: v1.Product{Name: "uaa", Version: "1.0.0"},
+		},
+	}
+	lookup := func(uid string) (v1.IndicatorDocument, bool) {
+		if uid != doc.BoshUID() {
+			return v1.IndicatorDocument{}, false
+		}
+		return doc, true
+	}
+	at := time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+	change := status_store.StatusChange{
+		DocumentUID:   doc.BoshUID(),
+		IndicatorName: "latency",
+		OldStatus:     test_fixtures.StrPtr("HEALTHY"),
+		NewStatus:     test_fixtures.StrPtr("critical"),
+		Value:         test_fixtures.FloatPtr(125.5),
+		At:            at,
+	}
+	retries := webhook.WithRetries(3, time.Millisecond, 5*time.Millisecond)
+
+	t.Run("it posts signed transitions to the matching webhooks", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		receiver := newReceiver(http.StatusOK)
+		defer receiver.Close()
+
+		dispatcher := webhook.NewDispatcher([]webhook.Webhook{
+			{Name: "matching", URL: receiver.URL, Secret: "s3cret", Match: webhook.Selector{Product: "uaa"}},
+			{Name: "other-product", URL: receiver.URL, Secret: "s3cret", Match: webhook.Selector{Product: "cloud_controller"}},
+		}, lookup, retries)
+		dispatcher.Start()
+		defer dispatcher.Stop()
+
+		delivered := deliveries("matching", "delivered")
+		dispatcher.Notify(change)
+
+		g.Eventually(receiver.requests).Should(HaveLen(1))
+		g.Consistently(receiver.requests, 50*time.Millisecond).Should(HaveLen(1))
+
+		req := receiver.requests()[0]
+		g.Expect(req.header.Get("Content-Type")).To(Equal("application/json"))
+		g.Expect(req.header.Get(webhook.EventHeader)).To(Equal("status_transition"))
+		g.Expect(webhook.VerifySignature("s3cret", req.body, req.header.Get(webhook.SignatureHeader))).To(BeTrue())
+		g.Expect(req.body).To(MatchJSON(`{
+			"documentUID": "` + doc.BoshUID() + `",
+			"product": {"name": "uaa", "version": "1.0.0"},
+			"labels": {"deployment": "cf"},
+			"indicator": "latency",
+			"oldStatus": "HEALTHY",
+			"newStatus": "critical",
+			"value": 125.5,
+			"timestamp": "2019-06-01T12:00:00Z"
+		}`))
+
+		g.Eventually(func() float64 { return deliveries("matching", "delivered") }).
+			Should(Equal(delivered + 1))
+	})
+
+	t.Run("it retries failed deliveries", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		receiver := newReceiver(http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusOK)
+		defer receiver.Close()
+
+		dispatcher := webhook.NewDispatcher([]webhook.Webhook{
+			{Name: "flaky", URL: receiver.URL, Secret: "s3cret"},
+		}, lookup, retries)
+		dispatcher.Start()
+		defer dispatcher.Stop()
+
+		delivered := deliveries("flaky", "delivered")
+		failed := failedAttempts("flaky")
+		dispatcher.Notify(change)
+
+		g.Eventually(receiver.requests).Should(HaveLen(3))
+		g.Eventually(func() float64 { return deliveries("flaky", "delivered") }).
+			Should(Equal(delivered + 1))
+		g.Expect(failedAttempts("flaky")).To(Equal(failed + 2))
+	})
+
+	t.Run("it gives up after the last attempt", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		receiver := newReceiver(http.StatusInternalServerError)
+		defer receiver.Close()
+
+		dispatcher := webhook.NewDispatcher([]webhook.Webhook{
+			{Name: "down", URL: receiver.URL, Secret: "s3cret"},
+		}, lookup, retries)
+		dispatcher.Start()
+		defer dispatcher.Stop()
+
+		failed := deliveries("down", "failed")
+		dispatcher.Notify(change)
+
+		g.Eventually(func() float64 { return deliveries("down", "failed") }).
+			Should(Equal(failed + 1))
+		g.Expect(receiver.requests()).To(HaveLen(3))
+	})
+
+	t.Run("it does not retry client errors", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		receiver := newReceiver(http.StatusBadRequest)
+		defer receiver.Close()
+
+		dispatcher := webhook.NewDispatcher([]webhook.Webhook{
+			{Name: "rejecting", URL: receiver.URL, Secret: "s3cret"},
+		}, lookup, retries)
+		dispatcher.Start()
+		defer dispatcher.Stop()
+
+		failed := deliveries("rejecting", "failed")
+		dispatcher.Notify(change)
+
+		g.Eventually(func() float64 { return deliveries("rejecting", "failed") }).
+			Should(Equal(failed + 1))
+		g.Expect(receiver.requests()).To(HaveLen(1))
+	})
+
+	t.Run("it drops transitions while the queue is full", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		dispatcher := webhook.NewDispatcher([]webhook.Webhook{
+			{Name: "full", URL: "http://localhost:0", Secret: "s3cret"},
+		}, lookup, webhook.WithQueueSize(2))
+
+		dropped := deliveries("full", "dropped")
+		for i := 0; i < 5; i++ {
+			dispatcher.Notify(change)
+		}
+
+		g.Expect(deliveries("full", "dropped")).To(Equal(dropped + 3))
+	})
+
+	t.Run("it does not notify of documents that are not registered", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		receiver := newReceiver(http.StatusOK)
+		defer receiver.Close()
+
+		dispatcher := webhook.NewDispatcher([]webhook.Webhook{
+			{Name: "unregistered", URL: receiver.URL, Secret: "s3cret"},
+		}, lookup, retries)
+		dispatcher.Start()
+		defer dispatcher.Stop()
+
+		unregistered := change
+		unregistered.DocumentUID = "unknown"
+		dispatcher.Notify(unregistered)
+
+		g.Consistently(receiver.requests, 50*time.Millisecond).Should(BeEmpty())
+	})
+}
+
+type receivedRequest struct {
+	header http.Header
+	body   []byte
+}
+
+type receiver struct {
+	*httptest.Server
+	sync.Mutex
+	received []receivedRequest
+}
+
+// newReceiver responds with the given status codes in order, repeating the last one.
+func newReceiver(statusCodes ...int) *receiver {
+	r := &receiver{}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := ioutil.ReadAll(req.Body)
+
+		r.Lock()
+		defer r.Unlock()
+		r.received = append(r.received, receivedRequest{header: req.Header, body: body})
+
+		statusCode := statusCodes[len(statusCodes)-1]
+		if len(r.received) <= len(statusCodes) {
+			statusCode = statusCodes[len(r.received)-1]
+		}
+		w.WriteHeader(statusCode)
+	}))
+	return r
+}
+
+func (r *receiver) requests() []receivedRequest {
+	r.Lock()
+	defer r.Unlock()
+	return append([]receivedRequest(nil), r.received...)
+}
+
+func deliveries(webhookName string, result string) float64 {
+	return counterValue("registry_webhook_deliveries", map[string]string{"webhook": webhookName, "result": result})
+}
+
+func failedAttempts(webhookName string) float64 {
+	return counterValue("registry_webhook_failed_attempts", map[string]string{"webhook": webhookName})
+}
+
+func counterValue(name string, labels map[string]string) float64 {
+	families, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		panic(err)
+	}
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			if hasLabels(metric.GetLabel(), labels) {
+				return metric.GetCounter().GetValue()
+			}
+		}
+	}
+	return 0
+}
+
+func hasLabels(pairs []*dto.LabelPair, labels map[string]string) bool {
+	if len(pairs) != len(labels) {
+		return false
+	}
+	for _, pair := range pairs {
+		if labels[pair.GetName()] != pair.GetValue() {
+			return false
+		}
+	}
+	return true
+}
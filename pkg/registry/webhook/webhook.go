@@ -0,0 +1,123 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	v1 "github.com/pivotal/monitoring-indicator-protocol/pkg/k8s/apis/indicatordocument/v1"
+)
+
+// SignatureHeader carries the hex encoded HMAC-SHA256 of the request body, keyed with the webhook's
+// secret and prefixed with `sha256=`.
+const SignatureHeader = "X-Indicator-Signature"
+
+// EventHeader names the kind of event delivered.
+const EventHeader = "X-Indicator-Event"
+
+const statusTransitionEvent = "status_transition"
+
+type ConfigFile struct {
+	Webhooks []Webhook `yaml:"webhooks"`
+}
+
+// Webhook receives the status transitions of the indicators of the documents it matches.
+type Webhook struct {
+	Name   string   `yaml:"name"`
+	URL    string   `yaml:"url"`
+	Secret string   `yaml:"secret"`
+	Match  Selector `yaml:"match"`
+}
+
+// Selector matches documents by product name and metadata labels. An empty selector matches every
+// document.
+type Selector struct {
+	Product string            `yaml:"product"`
+	Labels  map[string]string `yaml:"labels"`
+}
+
+func (s Selector) Matches(doc v1.IndicatorDocument) bool {
+	if s.Product != "" && s.Product != doc.Spec.Product.Name {
+		return false
+	}
+	for k, v := range s.Labels {
+		if doc.Labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+func ReadConfigFile(filePath string) ([]Webhook, error) {
+	fileBytes, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, errors.New("could not read webhooks file, error reading configuration file")
+	}
+
+	var f ConfigFile
+	err = yaml.Unmarshal(fileBytes, &f)
+	if err != nil {
+		return nil, errors.New("could not read webhooks file, error parsing configuration file yaml")
+	}
+
+	if err := Validate(f.Webhooks); err != nil {
+		return nil, fmt.Errorf("could not read webhooks file, configuration is not valid: %s", err)
+	}
+
+	return f.Webhooks, nil
+}
+
+func Validate(webhooks []Webhook) error {
+	names := make(map[string]bool)
+	for i, w := range webhooks {
+		if w.Name == "" {
+			return fmt.Errorf("webhooks[%d] is missing a name", i)
+		}
+		if names[w.Name] {
+			return fmt.Errorf("webhooks[%d] has a duplicate name %s", i, w.Name)
+		}
+		names[w.Name] = true
+		if w.URL == "" {
+			return fmt.Errorf("webhooks[%d] is missing a url", i)
+		}
+		if w.Secret == "" {
+			return fmt.Errorf("webhooks[%d] is missing a secret", i)
+		}
+	}
+	return nil
+}
+
+// Payload is the JSON body POSTed to webhooks when an indicator changes status.
+type Payload struct {
+	DocumentUID string            `json:"documentUID"`
+	Product     Product           `json:"product"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Indicator   string            `json:"indicator"`
+	OldStatus   *string           `json:"oldStatus"`
+	NewStatus   *string           `json:"newStatus"`
+	Value       *float64          `json:"value,omitempty"`
+	Timestamp   time.Time         `json:"timestamp"`
+}
+
+type Product struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// Sign returns the value of the signature header for the body.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature checks the signature header of a delivery, as receivers should.
+func VerifySignature(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
@@ -0,0 +1,93 @@
+package webhook_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "github.com/pivotal/monitoring-indicator-protocol/pkg/k8s/apis/indicatordocument/v1"
+	"github.com/pivotal/monitoring-indicator-protocol/pkg/registry/webhook"
+)
+
+func TestReadConfigFile(t *testing.T) {
+	t.Run("it reads the webhooks", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		webhooks, err := webhook.ReadConfigFile("test_fixtures/webhooks.yml")
+		g.Expect(err).ToNot(HaveOccurred())
+
+		g.Expect(webhooks).To(Equal([]webhook.Webhook{{
+			Name:   "uaa-pager",
+			URL:    "https://pager.example.com/hooks/indicators",
+			Secret: "s3cret",
+			Match: webhook.Selector{
+				Product: "uaa",
+				Labels:  map[string]string{"deployment": "cf"},
+			},
+		}, {
+			Name:   "everything",
+			URL:    "https://chat.example.com/hooks/indicators",
+			Secret: "an0ther",
+		}}))
+	})
+
+	t.Run("it returns an error if the file does not exist", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		_, err := webhook.ReadConfigFile("test_fixtures/missing.yml")
+		g.Expect(err).To(HaveOccurred())
+	})
+
+	t.Run("it validates the webhooks", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		g.Expect(webhook.Validate([]webhook.Webhook{{URL: "https://example.com", Secret: "s"}})).
+			To(MatchError("webhooks[0] is missing a name"))
+		g.Expect(webhook.Validate([]webhook.Webhook{{Name: "a", Secret: "s"}})).
+			To(MatchError("webhooks[0] is missing a url"))
+		g.Expect(webhook.Validate([]webhook.Webhook{{Name: "a", URL: "https://example.com"}})).
+			To(MatchError("webhooks[0] is missing a secret"))
+		g.Expect(webhook.Validate([]webhook.Webhook{
+			{Name: "a", URL: "https://example.com", Secret: "s"},
+			{Name: "a", URL: "https://example.com", Secret: "s"},
+		})).To(MatchError("webhooks[1] has a duplicate name a"))
+	})
+}
+
+func TestSelector(t *testing.T) {
+	doc := v1.IndicatorDocument{
+		ObjectMeta: metaV1.ObjectMeta{
+			Labels: map[string]string{"deployment": "cf", "source_id": "uaa"},
+		},
+		Spec: v1.IndicatorDocumentSpec{
+			Product: v1.Product{Name: "uaa", Version: "1.0.0"},
+		},
+	}
+
+	t.Run("it matches documents by product and labels", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		g.Expect(webhook.Selector{}.Matches(doc)).To(BeTrue())
+		g.Expect(webhook.Selector{Product: "uaa"}.Matches(doc)).To(BeTrue())
+		g.Expect(webhook.Selector{Labels: map[string]string{"deployment": "cf"}}.Matches(doc)).To(BeTrue())
+		g.Expect(webhook.Selector{Product: "uaa", Labels: map[string]string{"deployment": "cf"}}.Matches(doc)).To(BeTrue())
+
+		g.Expect(webhook.Selector{Product: "cloud_controller"}.Matches(doc)).To(BeFalse())
+		g.Expect(webhook.Selector{Labels: map[string]string{"deployment": "other"}}.Matches(doc)).To(BeFalse())
+		g.Expect(webhook.Selector{Product: "uaa", Labels: map[string]string{"az": "z1"}}.Matches(doc)).To(BeFalse())
+	})
+}
+
+func TestSignature(t *testing.T) {
+	t.Run("it signs bodies with HMAC-SHA256", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		signature := webhook.Sign("s3cret", []byte(`{"indicator":"latency"}`))
+
+		g.Expect(signature).To(HavePrefix("sha256="))
+		g.Expect(webhook.VerifySignature("s3cret", []byte(`{"indicator":"latency"}`), signature)).To(BeTrue())
+		g.Expect(webhook.VerifySignature("other", []byte(`{"indicator":"latency"}`), signature)).To(BeFalse())
+		g.Expect(webhook.VerifySignature("s3cret", []byte(`{"indicator":"errors"}`), signature)).To(BeFalse())
+	})
+}
@@ -52,9 +52,9 @@ func (h *Handler) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// If it's a GET (presumably, as it's not a POST) then
+	// If it's a GET (presumably, as it's not a POST or DELETE) then
 	// we don't need to ask every proxy, because they all have the same data
-	if r.Method != http.MethodPost {
+	if r.Method != http.MethodPost && r.Method != http.MethodDelete {
 		h.localRegistryHandler.ServeHTTP(rw, r)
 		return
 	}
@@ -72,7 +72,7 @@ func (h *Handler) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// If it's a POST, though, we have to talk to every registry we know
+	// If it's a POST or DELETE, though, we have to talk to every registry we know
 	// about, so they are all in consistent states
 	h.localRegistryHandler.ServeHTTP(rw, newReq)
 	r.URL.Path = prefix[:len(prefix)-1] + r.URL.Path
@@ -10,31 +10,38 @@ import (
 )
 
 func TestRegistryProxy(t *testing.T) {
-	t.Run("it broadcasts POST requests to every registry", func(t *testing.T) {
-		g := NewGomegaWithT(t)
-		rw := httptest.NewRecorder()
-		r := httptest.NewRequest("POST", "/foo", nil)
+	t.Run("it broadcasts POST and DELETE requests to every registry", func(t *testing.T) {
+		for _, method := range []string{"POST", "DELETE"} {
+			t.Run(method, func(t *testing.T) {
+				g := NewGomegaWithT(t)
+				rw := httptest.NewRecorder()
+				r := httptest.NewRequest(method, "/foo", nil)
 
-		var (
-			localCalled, remoteCalled   bool
-			localURLPath, remoteURLPath string
-		)
+				var (
+					localCalled, remoteCalled   bool
+					localURLPath, remoteURLPath string
+					remoteMethod                string
+				)
 
-		handlerFunc := http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
-			localCalled = true
-			localURLPath = r.URL.Path
-		})
-		h := registry_proxy.NewHandler(handlerFunc, []http.Handler{http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
-			remoteCalled = true
-			remoteURLPath = r.URL.Path
-		})})
-
-		h.ServeHTTP(rw, r)
-
-		g.Expect(localCalled).To(BeTrue())
-		g.Expect(localURLPath).To(Equal("/foo"))
-		g.Expect(remoteCalled).To(BeTrue())
-		g.Expect(remoteURLPath).To(Equal("/backend/foo"))
+				handlerFunc := http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+					localCalled = true
+					localURLPath = r.URL.Path
+				})
+				h := registry_proxy.NewHandler(handlerFunc, []http.Handler{http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+					remoteCalled = true
+					remoteURLPath = r.URL.Path
+					remoteMethod = r.Method
+				})})
+
+				h.ServeHTTP(rw, r)
+
+				g.Expect(localCalled).To(BeTrue())
+				g.Expect(localURLPath).To(Equal("/foo"))
+				g.Expect(remoteCalled).To(BeTrue())
+				g.Expect(remoteURLPath).To(Equal("/backend/foo"))
+				g.Expect(remoteMethod).To(Equal(method))
+			})
+		}
 	})
 
 	t.Run("it broadcasts requests in parallel", func(t *testing.T) {
@@ -75,6 +75,10 @@ func StrPtr(s string) *string {
 	return &s
 }
 
+func FloatPtr(f float64) *float64 {
+	return &f
+}
+
 func Indicator(name string, promql string) v1.Indicator {
 	return v1.Indicator{
 		ObjectMeta: metaV1.ObjectMeta{